@@ -0,0 +1,26 @@
+// Package analytics turns tracked detections into counts and occupancy, such
+// as people crossing a doorway line or dwelling in a region of a frame.
+package analytics
+
+import "image"
+
+// Anchor selects the point of a box that represents its position.
+type Anchor int
+
+const (
+	// AnchorBottomCenter is the middle of the bottom edge, where a standing
+	// person touches the ground.
+	AnchorBottomCenter Anchor = iota
+	// AnchorCenter is the middle of the box.
+	AnchorCenter
+)
+
+// Point returns the anchor point of r.
+func (a Anchor) Point(r image.Rectangle) image.Point {
+	switch a {
+	case AnchorCenter:
+		return image.Pt((r.Min.X+r.Max.X)/2, (r.Min.Y+r.Max.Y)/2)
+	default:
+		return image.Pt((r.Min.X+r.Max.X)/2, r.Max.Y)
+	}
+}
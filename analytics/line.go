@@ -0,0 +1,163 @@
+package analytics
+
+import (
+	"image"
+	"math"
+	"time"
+
+	"github.com/danhilltech/goyolov5/tracker"
+)
+
+// Line is a named counting line segment from A to B in image coordinates.
+type Line struct {
+	Name string
+	A, B image.Point
+}
+
+// Direction of a line crossing. In is a crossing from the left of A->B to its
+// right, as seen on screen by an observer at A facing B; Out is the reverse.
+type Direction int
+
+const (
+	In Direction = iota
+	Out
+)
+
+func (d Direction) String() string {
+	if d == In {
+		return "in"
+	}
+	return "out"
+}
+
+// Count holds the crossings of a line in each direction.
+type Count struct {
+	In, Out int
+}
+
+// Crossing is a single track crossing a line.
+type Crossing struct {
+	Line       string
+	TrackID    int
+	ClassIndex uint
+	Direction  Direction
+	Time       time.Time
+}
+
+// LineCounter counts tracks crossing one or more lines, per class and
+// direction. A track must move further than margin pixels past a line before
+// its side changes, so jitter around the line is not counted.
+type LineCounter struct {
+	anchor Anchor
+	margin float64
+	lines  []Line
+	// sides holds the last side, -1 or 1, each track was confirmed on per line
+	sides  []map[int]int
+	counts []map[uint]Count
+}
+
+func NewLineCounter(anchor Anchor, margin float64, lines ...Line) *LineCounter {
+	c := &LineCounter{
+		anchor: anchor,
+		margin: margin,
+		lines:  lines,
+		sides:  make([]map[int]int, len(lines)),
+		counts: make([]map[uint]Count, len(lines)),
+	}
+	for i := range lines {
+		c.sides[i] = map[int]int{}
+		c.counts[i] = map[uint]Count{}
+	}
+	return c
+}
+
+// Update consumes the tracks of a frame captured at t and returns the
+// crossings they made.
+func (c *LineCounter) Update(tracks []tracker.Track, t time.Time) []Crossing {
+	var crossings []Crossing
+
+	for _, tr := range tracks {
+		for i, line := range c.lines {
+			if tr.State == tracker.Removed {
+				delete(c.sides[i], tr.ID)
+				continue
+			}
+			if tr.State != tracker.Tracked {
+				continue
+			}
+
+			p := c.anchor.Point(tr.Rect)
+			distance, along := lineDistance(line, p)
+			if math.Abs(distance) <= c.margin {
+				continue
+			}
+
+			side := 1
+			if distance < 0 {
+				side = -1
+			}
+
+			last, seen := c.sides[i][tr.ID]
+			c.sides[i][tr.ID] = side
+			if !seen || last == side || along < 0 || along > 1 {
+				continue
+			}
+
+			direction := In
+			if side < 0 {
+				direction = Out
+			}
+
+			count := c.counts[i][tr.ClassIndex]
+			if direction == In {
+				count.In++
+			} else {
+				count.Out++
+			}
+			c.counts[i][tr.ClassIndex] = count
+
+			crossings = append(crossings, Crossing{
+				Line:       line.Name,
+				TrackID:    tr.ID,
+				ClassIndex: tr.ClassIndex,
+				Direction:  direction,
+				Time:       t,
+			})
+		}
+	}
+
+	return crossings
+}
+
+// Counts returns the per-class counts of the named line.
+func (c *LineCounter) Counts(name string) map[uint]Count {
+	res := map[uint]Count{}
+	for i, line := range c.lines {
+		if line.Name != name {
+			continue
+		}
+		for class, count := range c.counts[i] {
+			res[class] = count
+		}
+	}
+	return res
+}
+
+// lineDistance returns the signed distance of p from the line through A and B,
+// positive on its right, and the position of p's projection along the segment,
+// 0 at A and 1 at B.
+func lineDistance(line Line, p image.Point) (float64, float64) {
+	dx := float64(line.B.X - line.A.X)
+	dy := float64(line.B.Y - line.A.Y)
+	px := float64(p.X - line.A.X)
+	py := float64(p.Y - line.A.Y)
+
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return math.Hypot(px, py), 0
+	}
+
+	distance := (dx*py - dy*px) / length
+	along := (dx*px + dy*py) / (length * length)
+	return distance, along
+}
@@ -0,0 +1,73 @@
+package analytics
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"github.com/danhilltech/goyolov5/tracker"
+)
+
+func trackAt(id int, class uint, x, y int) tracker.Track {
+	return tracker.Track{
+		ID:         id,
+		State:      tracker.Tracked,
+		ClassIndex: class,
+		Rect:       image.Rect(x-20, y-100, x+20, y),
+	}
+}
+
+func TestLineCounter(t *testing.T) {
+	door := Line{Name: "door", A: image.Pt(0, 200), B: image.Pt(400, 200)}
+	counter := NewLineCounter(AnchorBottomCenter, 5, door)
+	start := time.Unix(0, 0)
+
+	// Track 1 walks down through the door, jittering around the line on the way
+	ys := []int{150, 190, 198, 203, 197, 204, 230, 260}
+	var crossings []Crossing
+	for i, y := range ys {
+		crossings = append(crossings, counter.Update([]tracker.Track{trackAt(1, 0, 100, y)}, start.Add(time.Duration(i)*time.Second))...)
+	}
+	if len(crossings) != 1 {
+		t.Fatalf("expected 1 crossing, got %+v", crossings)
+	}
+	if crossings[0].Direction != In || crossings[0].TrackID != 1 || crossings[0].Time != start.Add(6*time.Second) {
+		t.Fatalf("unexpected crossing %+v", crossings[0])
+	}
+
+	// Track 2, of another class, walks up and out
+	for _, y := range []int{260, 220, 180, 150} {
+		counter.Update([]tracker.Track{trackAt(2, 2, 300, y)}, start)
+	}
+
+	// Track 3 passes beyond the end of the segment and is not counted
+	for _, y := range []int{150, 250} {
+		counter.Update([]tracker.Track{trackAt(3, 0, 500, y)}, start)
+	}
+
+	counts := counter.Counts("door")
+	if counts[0] != (Count{In: 1}) {
+		t.Fatalf("expected class 0 in=1, got %+v", counts[0])
+	}
+	if counts[2] != (Count{Out: 1}) {
+		t.Fatalf("expected class 2 out=1, got %+v", counts[2])
+	}
+}
+
+func TestLineCounterIgnoresLostTracks(t *testing.T) {
+	counter := NewLineCounter(AnchorCenter, 0, Line{Name: "l", A: image.Pt(100, 0), B: image.Pt(100, 400)})
+
+	counter.Update([]tracker.Track{trackAt(1, 0, 50, 200)}, time.Time{})
+	lost := trackAt(1, 0, 150, 200)
+	lost.State = tracker.Lost
+	if crossings := counter.Update([]tracker.Track{lost}, time.Time{}); len(crossings) != 0 {
+		t.Fatalf("expected lost tracks to be ignored, got %+v", crossings)
+	}
+
+	// An observer at A facing down the line has the image's left on their
+	// right, so moving right is Out.
+	crossings := counter.Update([]tracker.Track{trackAt(1, 0, 150, 200)}, time.Time{})
+	if len(crossings) != 1 || crossings[0].Direction != Out {
+		t.Fatalf("expected an out crossing, got %+v", crossings)
+	}
+}
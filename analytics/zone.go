@@ -0,0 +1,191 @@
+package analytics
+
+import (
+	"image"
+	"math"
+	"time"
+
+	"github.com/danhilltech/goyolov5"
+	"github.com/danhilltech/goyolov5/tracker"
+)
+
+// Zone is a named polygonal region of interest in image coordinates.
+type Zone struct {
+	Name    string
+	Polygon []image.Point
+}
+
+// Contains reports whether p lies inside the polygon, using the even-odd rule.
+func (z Zone) Contains(p image.Point) bool {
+	inside := false
+	n := len(z.Polygon)
+	for i, j := 0, n-1; i < n; j, i = i, i+1 {
+		a, b := z.Polygon[i], z.Polygon[j]
+		if (a.Y > p.Y) != (b.Y > p.Y) {
+			x := float64(b.X-a.X)*float64(p.Y-a.Y)/float64(b.Y-a.Y) + float64(a.X)
+			if float64(p.X) < x {
+				inside = !inside
+			}
+		}
+	}
+	return inside
+}
+
+// Overlap returns the fraction of r's area inside the polygon.
+func (z Zone) Overlap(r image.Rectangle) float64 {
+	area := float64(r.Dx() * r.Dy())
+	if area <= 0 || len(z.Polygon) < 3 {
+		return 0
+	}
+
+	poly := make([][2]float64, len(z.Polygon))
+	for i, p := range z.Polygon {
+		poly[i] = [2]float64{float64(p.X), float64(p.Y)}
+	}
+
+	// Sutherland-Hodgman against each edge of the rectangle
+	minX, minY, maxX, maxY := float64(r.Min.X), float64(r.Min.Y), float64(r.Max.X), float64(r.Max.Y)
+	poly = clip(poly, func(p [2]float64) float64 { return p[0] - minX })
+	poly = clip(poly, func(p [2]float64) float64 { return maxX - p[0] })
+	poly = clip(poly, func(p [2]float64) float64 { return p[1] - minY })
+	poly = clip(poly, func(p [2]float64) float64 { return maxY - p[1] })
+
+	return polygonArea(poly) / area
+}
+
+// clip keeps the part of poly where inside(p) >= 0, inside being a linear
+// function of p.
+func clip(poly [][2]float64, inside func([2]float64) float64) [][2]float64 {
+	var out [][2]float64
+	for i := range poly {
+		cur, prev := poly[i], poly[(i+len(poly)-1)%len(poly)]
+		dc, dp := inside(cur), inside(prev)
+		if (dc >= 0) != (dp >= 0) {
+			t := dp / (dp - dc)
+			out = append(out, [2]float64{prev[0] + t*(cur[0]-prev[0]), prev[1] + t*(cur[1]-prev[1])})
+		}
+		if dc >= 0 {
+			out = append(out, cur)
+		}
+	}
+	return out
+}
+
+// polygonArea is the shoelace formula.
+func polygonArea(poly [][2]float64) float64 {
+	var sum float64
+	for i := range poly {
+		a, b := poly[i], poly[(i+1)%len(poly)]
+		sum += a[0]*b[1] - b[0]*a[1]
+	}
+	return math.Abs(sum) / 2
+}
+
+// ZoneOccupancy describes what is inside one zone in a frame.
+type ZoneOccupancy struct {
+	Zone string
+	// Indices are the positions, in the slice passed in, of the predictions or
+	// tracks inside the zone.
+	Indices []int
+	// Occupancy is the number of objects inside, per class.
+	Occupancy map[uint]int
+	// Dwell is how long each track inside has been there, by track ID. It is
+	// only set by ZoneMonitor.Update.
+	Dwell map[int]time.Duration
+}
+
+// ZoneMonitor assigns detections to zones. A box is inside a zone when its
+// anchor point is, or, when minOverlap is above zero, when at least that
+// fraction of its area is.
+type ZoneMonitor struct {
+	anchor     Anchor
+	minOverlap float64
+	zones      []Zone
+	// entered holds when each track entered each zone
+	entered []map[int]time.Time
+}
+
+func NewZoneMonitor(anchor Anchor, minOverlap float64, zones ...Zone) *ZoneMonitor {
+	m := &ZoneMonitor{
+		anchor:     anchor,
+		minOverlap: minOverlap,
+		zones:      zones,
+		entered:    make([]map[int]time.Time, len(zones)),
+	}
+	for i := range zones {
+		m.entered[i] = map[int]time.Time{}
+	}
+	return m
+}
+
+func (m *ZoneMonitor) inside(z Zone, r image.Rectangle) bool {
+	if m.minOverlap > 0 {
+		return z.Overlap(r) >= m.minOverlap
+	}
+	return z.Contains(m.anchor.Point(r))
+}
+
+// Assign returns, for every zone, which of the predictions are inside it and
+// the occupancy per class.
+func (m *ZoneMonitor) Assign(predictions []goyolov5.Prediction) []ZoneOccupancy {
+	res := make([]ZoneOccupancy, len(m.zones))
+	for i, z := range m.zones {
+		res[i] = ZoneOccupancy{Zone: z.Name, Occupancy: map[uint]int{}}
+		for j, p := range predictions {
+			if m.inside(z, p.Rect) {
+				res[i].Indices = append(res[i].Indices, j)
+				res[i].Occupancy[p.ClassIndex]++
+			}
+		}
+	}
+	return res
+}
+
+// Update consumes the tracks of a frame captured at t and returns, for every
+// zone, the tracked objects inside it, the occupancy per class and how long
+// each has dwelt there. Lost tracks are not counted but keep their entry time
+// in case they reappear inside.
+func (m *ZoneMonitor) Update(tracks []tracker.Track, t time.Time) []ZoneOccupancy {
+	res := make([]ZoneOccupancy, len(m.zones))
+	for i, z := range m.zones {
+		res[i] = ZoneOccupancy{Zone: z.Name, Occupancy: map[uint]int{}, Dwell: map[int]time.Duration{}}
+		for j, tr := range tracks {
+			switch tr.State {
+			case tracker.Removed:
+				delete(m.entered[i], tr.ID)
+				continue
+			case tracker.Lost:
+				continue
+			}
+
+			if !m.inside(z, tr.Rect) {
+				delete(m.entered[i], tr.ID)
+				continue
+			}
+
+			entered, ok := m.entered[i][tr.ID]
+			if !ok {
+				entered = t
+				m.entered[i][tr.ID] = t
+			}
+			res[i].Indices = append(res[i].Indices, j)
+			res[i].Occupancy[tr.ClassIndex]++
+			res[i].Dwell[tr.ID] = t.Sub(entered)
+		}
+	}
+	return res
+}
+
+// Mask drops every prediction that is not inside at least one zone.
+func (m *ZoneMonitor) Mask(predictions []goyolov5.Prediction) []goyolov5.Prediction {
+	var res []goyolov5.Prediction
+	for _, p := range predictions {
+		for _, z := range m.zones {
+			if m.inside(z, p.Rect) {
+				res = append(res, p)
+				break
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,85 @@
+package analytics
+
+import (
+	"image"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/danhilltech/goyolov5"
+	"github.com/danhilltech/goyolov5/tracker"
+)
+
+// An L-shaped, concave zone
+var counterZone = Zone{Name: "counter", Polygon: []image.Point{
+	{0, 0}, {200, 0}, {200, 100}, {100, 100}, {100, 200}, {0, 200},
+}}
+
+func TestZoneContains(t *testing.T) {
+	if !counterZone.Contains(image.Pt(50, 150)) {
+		t.Fatal("expected (50,150) inside")
+	}
+	if counterZone.Contains(image.Pt(150, 150)) {
+		t.Fatal("expected (150,150) in the notch to be outside")
+	}
+}
+
+func TestZoneOverlap(t *testing.T) {
+	overlap := counterZone.Overlap(image.Rect(50, 50, 150, 150))
+	if math.Abs(overlap-0.75) > 1e-9 {
+		t.Fatalf("expected overlap 0.75, got %f", overlap)
+	}
+	if overlap := counterZone.Overlap(image.Rect(300, 300, 310, 310)); overlap != 0 {
+		t.Fatalf("expected no overlap, got %f", overlap)
+	}
+}
+
+func TestZoneMonitorAssignAndMask(t *testing.T) {
+	monitor := NewZoneMonitor(AnchorCenter, 0, counterZone)
+	predictions := []goyolov5.Prediction{
+		{Rect: image.Rect(40, 140, 60, 160), ClassIndex: 0},
+		{Rect: image.Rect(140, 140, 160, 160), ClassIndex: 0},
+		{Rect: image.Rect(140, 40, 160, 60), ClassIndex: 2},
+	}
+
+	occupancy := monitor.Assign(predictions)
+	if len(occupancy[0].Indices) != 2 || occupancy[0].Indices[1] != 2 {
+		t.Fatalf("unexpected indices %v", occupancy[0].Indices)
+	}
+	if occupancy[0].Occupancy[0] != 1 || occupancy[0].Occupancy[2] != 1 {
+		t.Fatalf("unexpected occupancy %v", occupancy[0].Occupancy)
+	}
+
+	if masked := monitor.Mask(predictions); len(masked) != 2 {
+		t.Fatalf("expected 2 predictions left, got %d", len(masked))
+	}
+
+	// By overlap, the box straddling the notch is mostly outside
+	monitor = NewZoneMonitor(AnchorCenter, 0.5, counterZone)
+	if masked := monitor.Mask([]goyolov5.Prediction{{Rect: image.Rect(90, 90, 190, 190)}}); len(masked) != 0 {
+		t.Fatalf("expected the box to be masked, got %v", masked)
+	}
+}
+
+func TestZoneMonitorDwell(t *testing.T) {
+	monitor := NewZoneMonitor(AnchorCenter, 0, counterZone)
+	start := time.Unix(0, 0)
+	track := func(y int, state tracker.TrackState) []tracker.Track {
+		return []tracker.Track{{ID: 7, State: state, Rect: image.Rect(40, y-10, 60, y+10)}}
+	}
+
+	monitor.Update(track(300, tracker.Tracked), start)
+	monitor.Update(track(150, tracker.Tracked), start.Add(time.Second))
+	monitor.Update(track(150, tracker.Lost), start.Add(2*time.Second))
+	occupancy := monitor.Update(track(140, tracker.Tracked), start.Add(4*time.Second))
+
+	if occupancy[0].Dwell[7] != 3*time.Second {
+		t.Fatalf("expected 3s dwell, got %v", occupancy[0].Dwell[7])
+	}
+
+	occupancy = monitor.Update(track(300, tracker.Tracked), start.Add(5*time.Second))
+	occupancy = monitor.Update(track(150, tracker.Tracked), start.Add(6*time.Second))
+	if occupancy[0].Dwell[7] != 0 {
+		t.Fatalf("expected dwell to restart after leaving, got %v", occupancy[0].Dwell[7])
+	}
+}
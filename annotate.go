@@ -0,0 +1,200 @@
+package goyolov5
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strconv"
+)
+
+// DefaultPalette is the Ultralytics class colour palette, cycled through by
+// class index.
+var DefaultPalette = []color.RGBA{
+	{0xff, 0x38, 0x38, 0xff}, {0xff, 0x9d, 0x97, 0xff}, {0xff, 0x70, 0x1f, 0xff}, {0xff, 0xb2, 0x1d, 0xff},
+	{0xcf, 0xd2, 0x31, 0xff}, {0x48, 0xf9, 0x0a, 0xff}, {0x92, 0xcc, 0x17, 0xff}, {0x3d, 0xdb, 0x86, 0xff},
+	{0x1a, 0x93, 0x34, 0xff}, {0x00, 0xd4, 0xbb, 0xff}, {0x2c, 0x99, 0xa8, 0xff}, {0x00, 0xc2, 0xff, 0xff},
+	{0x34, 0x45, 0x93, 0xff}, {0x64, 0x73, 0xff, 0xff}, {0x00, 0x18, 0xec, 0xff}, {0x84, 0x38, 0xff, 0xff},
+	{0x52, 0x00, 0x85, 0xff}, {0xcb, 0x38, 0xff, 0xff}, {0xff, 0x95, 0xc8, 0xff}, {0xff, 0x37, 0xc7, 0xff},
+}
+
+// Annotator draws predictions as boxes in per-class colours, each with a
+// filled label box naming the class and its confidence. The zero value draws
+// with DefaultPalette, class indices as labels and sizes scaled to the image.
+type Annotator struct {
+	// Names label classes by index. Classes without a name are labelled by
+	// their index.
+	Names []string
+	// Palette colours classes by index, cycling when there are more classes
+	// than colours.
+	Palette []color.RGBA
+	// Thickness is the box line width in pixels, or 0 to scale it with the
+	// image size.
+	Thickness int
+	// FontScale magnifies the 5x7 pixel label font, or 0 to scale it with the
+	// line width.
+	FontScale int
+	// HideLabels draws boxes only, and HideConfidence leaves the confidence
+	// out of labels.
+	HideLabels     bool
+	HideConfidence bool
+	// MinVisibility is the least visibility of keypoints drawn for COCO pose
+	// models, 0.5 if zero.
+	MinVisibility float64
+}
+
+// NewAnnotator returns an Annotator labelling classes with names.
+func NewAnnotator(names []string) *Annotator {
+	return &Annotator{Names: names}
+}
+
+// Color is the colour of a class.
+func (a *Annotator) Color(classIndex uint) color.RGBA {
+	palette := a.Palette
+	if len(palette) == 0 {
+		palette = DefaultPalette
+	}
+	return palette[classIndex%uint(len(palette))]
+}
+
+// Label is the text drawn above a prediction.
+func (a *Annotator) Label(pred Prediction) string {
+	label := strconv.Itoa(int(pred.ClassIndex))
+	if int(pred.ClassIndex) < len(a.Names) {
+		label = a.Names[pred.ClassIndex]
+	}
+	if !a.HideConfidence {
+		label = fmt.Sprintf("%s %.2f", label, pred.Confidence)
+	}
+	// A Cascade's top class follows the detector's
+	if len(pred.Classifications) > 0 {
+		top := pred.Classifications[0]
+		name := top.Class
+		if name == "" {
+			name = strconv.Itoa(int(top.ClassIndex))
+		}
+		label += " " + name
+		if !a.HideConfidence {
+			label = fmt.Sprintf("%s %.2f", label, top.Score)
+		}
+	}
+	return label
+}
+
+// sizes returns the line width and font scale for an image, scaled like
+// Ultralytics' plotting when not set.
+func (a *Annotator) sizes(bounds image.Rectangle) (int, int) {
+	thickness := a.Thickness
+	if thickness <= 0 {
+		thickness = int(math.Round(float64(bounds.Dx()+bounds.Dy()) / 2 * 0.003))
+		if thickness < 2 {
+			thickness = 2
+		}
+	}
+	scale := a.FontScale
+	if scale <= 0 {
+		scale = thickness - 1
+		if scale < 1 {
+			scale = 1
+		}
+	}
+	return thickness, scale
+}
+
+// Annotate draws predictions on img.
+func (a *Annotator) Annotate(img *Tensor, predictions []Prediction) {
+	thickness, scale := a.sizes(img.Rect)
+	minVisibility := a.MinVisibility
+	if minVisibility == 0 {
+		minVisibility = 0.5
+	}
+
+	for _, pred := range predictions {
+		col := a.Color(pred.ClassIndex)
+		r := pred.Rect
+
+		// Lines grow inwards so boxes keep their extent
+		t := thickness
+		if t > r.Dx()/2+1 {
+			t = r.Dx()/2 + 1
+		}
+		if t > r.Dy()/2+1 {
+			t = r.Dy()/2 + 1
+		}
+		img.FillRect(image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+t), col)
+		img.FillRect(image.Rect(r.Min.X, r.Max.Y-t, r.Max.X, r.Max.Y), col)
+		img.FillRect(image.Rect(r.Min.X, r.Min.Y, r.Min.X+t, r.Max.Y), col)
+		img.FillRect(image.Rect(r.Max.X-t, r.Min.Y, r.Max.X, r.Max.Y), col)
+
+		if len(pred.Keypoints) == NKeypointsCOCO {
+			img.DrawSkeleton(pred.Keypoints, COCOSkeleton, minVisibility, col)
+		}
+		if !a.HideLabels {
+			a.drawLabel(img, r, a.Label(pred), scale, col)
+		}
+	}
+}
+
+// drawLabel draws text on a filled box above the top left corner of r, or
+// just inside it when there is no room above, kept within the image.
+func (a *Annotator) drawLabel(img *Tensor, r image.Rectangle, text string, scale int, col color.RGBA) {
+	pad := scale
+	size := TextSize(text, scale).Add(image.Pt(2*pad, 2*pad))
+
+	box := image.Rectangle{Min: image.Pt(r.Min.X, r.Min.Y-size.Y), Max: image.Pt(r.Min.X+size.X, r.Min.Y)}
+	if box.Min.Y < img.Rect.Min.Y {
+		box = box.Add(image.Pt(0, size.Y))
+	}
+	if box.Max.X > img.Rect.Max.X {
+		box = box.Add(image.Pt(img.Rect.Max.X-box.Max.X, 0))
+	}
+	if box.Min.X < img.Rect.Min.X {
+		box = box.Add(image.Pt(img.Rect.Min.X-box.Min.X, 0))
+	}
+
+	img.FillRect(box, col)
+	img.DrawText(box.Min.Add(image.Pt(pad, pad)), text, scale, textColor(col))
+}
+
+// textColor is black or white, whichever contrasts more with the background.
+func textColor(background color.RGBA) color.RGBA {
+	luma := 0.299*float64(background.R) + 0.587*float64(background.G) + 0.114*float64(background.B)
+	if luma > 150 {
+		return color.RGBA{0, 0, 0, 255}
+	}
+	return color.RGBA{255, 255, 255, 255}
+}
+
+// AnnotateBatch draws each image's predictions on it, as returned for a batch
+// of images.
+func (a *Annotator) AnnotateBatch(imgs []*Tensor, predictions [][]Prediction) error {
+	if len(imgs) != len(predictions) {
+		return fmt.Errorf("%d images but predictions for %d", len(imgs), len(predictions))
+	}
+	for i, img := range imgs {
+		if img != nil {
+			a.Annotate(img, predictions[i])
+		}
+	}
+	return nil
+}
+
+// WithAnnotator draws the annotateTensor of Infer with a instead of plain
+// green boxes.
+func WithAnnotator(a *Annotator) InferOption {
+	return func(o *inferOptions) {
+		o.annotator = a
+	}
+}
+
+// annotate draws predictions on the annotateTensor of an Infer call, with the
+// Annotator of the call if it has one.
+func (o inferOptions) annotate(img *Tensor, predictions []Prediction) {
+	if o.annotator != nil {
+		o.annotator.Annotate(img, predictions)
+		return
+	}
+	for _, pred := range predictions {
+		annotatePrediction(img, pred)
+	}
+}
@@ -0,0 +1,113 @@
+package goyolov5
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestAnnotator(t *testing.T) {
+	img := NewTensor(image.Rect(0, 0, 200, 200))
+	a := &Annotator{Names: []string{"person", "car"}, Thickness: 3, FontScale: 1}
+	a.Annotate(img, []Prediction{
+		{Rect: image.Rect(50, 60, 150, 160), Confidence: 0.87, ClassIndex: 1},
+	})
+
+	car := DefaultPalette[1]
+	for _, p := range []image.Point{{50, 60}, {52, 100}, {149, 159}, {100, 157}} {
+		if got := img.RGBAAt(p.X, p.Y); got != car {
+			t.Errorf("expected box colour at %v, got %v", p, got)
+		}
+	}
+	if got := img.RGBAAt(53, 100); got != (color.RGBA{0, 0, 0, 255}) {
+		t.Errorf("expected a 3 pixel line, got %v at 53,100", got)
+	}
+
+	// The label "car 0.87" sits above the box: 8 glyphs and 1 pixel padding
+	size := TextSize("car 0.87", 1)
+	if size != image.Pt(47, 7) {
+		t.Errorf("unexpected text size %v", size)
+	}
+	if got := img.RGBAAt(50, 60-9); got != car {
+		t.Errorf("expected the label box above the box, got %v", got)
+	}
+	if got := img.RGBAAt(50+48, 60-5); got != car {
+		t.Errorf("expected the label box to fit the text, got %v", got)
+	}
+	if got := img.RGBAAt(50+49, 60-5); got != (color.RGBA{0, 0, 0, 255}) {
+		t.Errorf("expected the label box to end after the text, got %v", got)
+	}
+	var text int
+	for y := 60 - 8; y < 60-1; y++ {
+		for x := 51; x < 51+47; x++ {
+			if img.RGBAAt(x, y) == textColor(car) {
+				text++
+			}
+		}
+	}
+	if text == 0 {
+		t.Error("expected label text")
+	}
+}
+
+func TestAnnotatorLabelPlacement(t *testing.T) {
+	img := NewTensor(image.Rect(0, 0, 100, 100))
+	a := &Annotator{Palette: []color.RGBA{{255, 255, 255, 255}}, Thickness: 1, FontScale: 1, HideConfidence: true}
+	if label := a.Label(Prediction{ClassIndex: 7}); label != "7" {
+		t.Errorf("expected the class index as label, got %q", label)
+	}
+
+	// No room above or to the right: the label moves inside and left
+	a.Annotate(img, []Prediction{{Rect: image.Rect(95, 0, 100, 40), ClassIndex: 12}})
+	size := TextSize("12", 1).Add(image.Pt(2, 2))
+	if got := img.RGBAAt(100-size.X, size.Y-1); got != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("expected the label box inside the image, got %v", got)
+	}
+	if got := img.RGBAAt(100-size.X-1, 1); got != (color.RGBA{0, 0, 0, 255}) {
+		t.Errorf("expected nothing left of the label box, got %v", got)
+	}
+}
+
+func TestAnnotatorSizes(t *testing.T) {
+	var a Annotator
+	if thickness, scale := a.sizes(image.Rect(0, 0, 640, 480)); thickness != 2 || scale != 1 {
+		t.Errorf("expected 2 and 1 for 640x480, got %d and %d", thickness, scale)
+	}
+	if thickness, scale := a.sizes(image.Rect(0, 0, 3840, 2160)); thickness != 9 || scale != 8 {
+		t.Errorf("expected 9 and 8 for 4K, got %d and %d", thickness, scale)
+	}
+}
+
+func TestAnnotateBatch(t *testing.T) {
+	imgs := []*Tensor{NewTensor(image.Rect(0, 0, 100, 100)), NewTensor(image.Rect(0, 0, 100, 100))}
+	predictions := [][]Prediction{
+		{{Rect: image.Rect(10, 20, 50, 50), ClassIndex: 0}},
+		{{Rect: image.Rect(60, 60, 90, 90), ClassIndex: 2}},
+	}
+	a := NewAnnotator(nil)
+	if err := a.AnnotateBatch(imgs, predictions); err != nil {
+		t.Fatal(err)
+	}
+	if imgs[0].RGBAAt(10, 20) != DefaultPalette[0] || imgs[0].RGBAAt(60, 60) == DefaultPalette[2] {
+		t.Error("expected only the first image's box on the first image")
+	}
+	if imgs[1].RGBAAt(60, 60) != DefaultPalette[2] || imgs[1].RGBAAt(10, 20) == DefaultPalette[0] {
+		t.Error("expected only the second image's box on the second image")
+	}
+	if err := a.AnnotateBatch(imgs, predictions[:1]); err == nil {
+		t.Error("expected an error for mismatched lengths")
+	}
+}
+
+func TestInferWithAnnotator(t *testing.T) {
+	backend := NewFakeBackend(NClasses, 0, 10, FakeDetection{Rect: image.Rect(10, 10, 60, 110), Confidence: 0.9, ClassIndex: 2, ClassConfidence: 0.8})
+	yolov5 := NewYoloV5WithBackend(backend, 320, NClasses, 0)
+
+	annotated := NewTensor(image.Rect(0, 0, 320, 320))
+	if _, err := yolov5.Infer(NewTensor(annotated.Rect), 0.5, 0.4, annotated, WithAnnotator(NewAnnotator(COCONames))); err != nil {
+		t.Fatal(err)
+	}
+	if got := annotated.RGBAAt(10, 50); got != DefaultPalette[2] {
+		t.Errorf("expected the car colour, got %v", got)
+	}
+}
@@ -0,0 +1,127 @@
+package goyolov5
+
+import (
+	"context"
+	"fmt"
+	"image"
+)
+
+// Augmentation is one test-time transform of the letterboxed model input.
+type Augmentation struct {
+	// Scale shrinks the input to this fraction of the model size, in (0, 1],
+	// padding the remainder.
+	Scale float64
+	// Flip mirrors the input horizontally.
+	Flip bool
+}
+
+// DefaultAugmentations matches upstream YOLOv5's --augment.
+var DefaultAugmentations = []Augmentation{
+	{Scale: 1.0},
+	{Scale: 0.83, Flip: true},
+	{Scale: 0.67},
+}
+
+// cocoFlipIndex maps each COCO keypoint to its mirror image, e.g. left eye to
+// right eye.
+var cocoFlipIndex = []int{0, 2, 1, 4, 3, 6, 5, 8, 7, 10, 9, 12, 11, 14, 13, 16, 15}
+
+// WithAugment enables test-time augmentation: the image is run once per
+// augmentation, each transform is undone on the boxes and all boxes are fused
+// before NMS. This trades latency for recall. With no augmentations
+// DefaultAugmentations is used.
+func WithAugment(augmentations ...Augmentation) InferOption {
+	if len(augmentations) == 0 {
+		augmentations = DefaultAugmentations
+	}
+	return func(o *inferOptions) {
+		o.augmentations = augmentations
+	}
+}
+
+// augmentedForward runs every augmentation of inputTensor and returns the boxes
+// of all of them, mapped back to inputTensor's coordinates.
+func (yolov5 *YoloV5) augmentedForward(ctx context.Context, inputTensor *Tensor, augmentations []Augmentation, confidenceThreshold float32) ([][]Bbox, error) {
+	size := inputTensor.Rect.Dx()
+	bboxes := make([][]Bbox, yolov5.nClasses)
+
+	for _, aug := range augmentations {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if aug.Scale <= 0 || aug.Scale > 1 {
+			return nil, fmt.Errorf("augmentation scale must be in (0, 1], got %f", aug.Scale)
+		}
+
+		augmented := inputTensor
+		ratio := 1.0
+		if aug.Scale != 1 {
+			resized, r, err := inputTensor.Resize(int(float64(size) * aug.Scale))
+			if err != nil {
+				return nil, err
+			}
+			augmented = resized.tile(resized.Rect, size)
+			ratio = r
+		}
+		if aug.Flip {
+			augmented = augmented.FlipH()
+		}
+
+		rawOutput, shape, err := yolov5.backend.Forward(augmented)
+		if err != nil {
+			return nil, err
+		}
+
+		batchBboxes, err := yolov5.postProcess(rawOutput, shape, confidenceThreshold)
+		if err != nil {
+			return nil, err
+		}
+
+		for c, bboxesForClass := range batchBboxes[0] {
+			for _, b := range bboxesForClass {
+				if aug.Flip {
+					b = b.flipX(float64(size))
+				}
+				bboxes[c] = append(bboxes[c], b.transform(ratio, 0, 0))
+			}
+		}
+	}
+
+	return bboxes, nil
+}
+
+// flipX mirrors the box, keypoints included, within an image of the given
+// width.
+func (b Bbox) flipX(width float64) Bbox {
+	b.xmin, b.xmax = width-b.xmax, width-b.xmin
+
+	if len(b.keypoints) > 0 {
+		keypoints := make([]float32, len(b.keypoints))
+		n := len(keypoints) / 3
+		for i := 0; i < n; i++ {
+			j := i
+			if n == NKeypointsCOCO {
+				j = cocoFlipIndex[i]
+			}
+			keypoints[j*3] = float32(width) - b.keypoints[i*3]
+			keypoints[j*3+1] = b.keypoints[i*3+1]
+			keypoints[j*3+2] = b.keypoints[i*3+2]
+		}
+		b.keypoints = keypoints
+	}
+	return b
+}
+
+// FlipH returns a horizontally mirrored copy of the tensor.
+func (p *Tensor) FlipH() *Tensor {
+	dst := NewTensor(image.Rect(0, 0, p.Rect.Dx(), p.Rect.Dy()))
+	w := p.Rect.Dx()
+	for y := 0; y < p.Rect.Dy(); y++ {
+		src := p.Pix[p.PixOffset(p.Rect.Min.X, p.Rect.Min.Y+y):]
+		row := dst.Pix[y*dst.Stride:]
+		for x := 0; x < w; x++ {
+			copy(row[(w-1-x)*3:(w-x)*3], src[x*3:x*3+3])
+		}
+	}
+	return dst
+}
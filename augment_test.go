@@ -0,0 +1,65 @@
+package goyolov5
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func TestFlipH(t *testing.T) {
+	tensor := NewTensor(image.Rect(0, 0, 3, 2))
+	red := color.RGBA{255, 0, 0, 255}
+	tensor.Set(0, 1, red)
+
+	flipped := tensor.FlipH()
+	if flipped.RGBAAt(2, 1) != red {
+		t.Fatalf("expected pixel mirrored to (2,1)")
+	}
+	if flipped.RGBAAt(0, 1) == red {
+		t.Fatalf("expected (0,1) to be cleared")
+	}
+}
+
+func TestBboxFlipX(t *testing.T) {
+	keypoints := make([]float32, NKeypointsCOCO*3)
+	keypoints[1*3] = 10   // left eye
+	keypoints[2*3] = 20   // right eye
+	keypoints[2*3+2] = .5 // right eye visibility
+
+	b := Bbox{xmin: 10, xmax: 30, keypoints: keypoints}.flipX(100)
+	if b.xmin != 70 || b.xmax != 90 {
+		t.Fatalf("expected x range 70-90, got %f-%f", b.xmin, b.xmax)
+	}
+	if b.keypoints[1*3] != 80 || b.keypoints[1*3+2] != .5 {
+		t.Fatalf("expected the mirrored right eye to become the left eye, got %v", b.keypoints[:9])
+	}
+	if b.keypoints[2*3] != 90 {
+		t.Fatalf("expected the mirrored left eye to become the right eye, got %v", b.keypoints[:9])
+	}
+}
+
+func TestInferAugment(t *testing.T) {
+	backend := &blobBackend{}
+	yolov5 := NewYoloV5WithBackend(backend, 320, 1, 0)
+
+	tensor := NewTensor(image.Rect(0, 0, 640, 640))
+	draw.Draw(tensor, image.Rect(100, 200, 180, 300), image.NewUniform(color.White), image.Point{}, draw.Src)
+
+	predictions, err := yolov5.Infer(tensor, 0.5, 0.4, nil, WithAugment())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if backend.calls != len(DefaultAugmentations) {
+		t.Fatalf("expected %d forward calls, got %d", len(DefaultAugmentations), backend.calls)
+	}
+	if len(predictions[0]) != 1 {
+		t.Fatalf("expected the augmented boxes to fuse into 1 prediction, got %d", len(predictions[0]))
+	}
+
+	r := predictions[0][0].Rect
+	expected := image.Rect(100, 200, 180, 300)
+	if abs(r.Min.X-expected.Min.X) > 4 || abs(r.Min.Y-expected.Min.Y) > 4 || abs(r.Max.X-expected.Max.X) > 4 || abs(r.Max.Y-expected.Max.Y) > 4 {
+		t.Fatalf("expected a box near %v, got %v", expected, r)
+	}
+}
@@ -0,0 +1,150 @@
+// Package autolabel pre-annotates images with a model for human review in
+// labelling tools. A Labeler runs the model with per-class confidence
+// thresholds and maps the model's class names to the labelling project's
+// labels; Writers export the results as CVAT XML, Label Studio JSON or YOLO
+// txt.
+package autolabel
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/danhilltech/goyolov5"
+)
+
+// Options configures a Labeler.
+type Options struct {
+	// Confidence is the threshold for classes without their own.
+	Confidence float64
+	// ClassConfidence overrides Confidence by model class name.
+	ClassConfidence map[string]float64
+	// NMS is the NMS IoU threshold.
+	NMS float64
+	// Rename maps model class names to label names. Several classes may
+	// share a label, and classes renamed to "" are not labelled.
+	Rename map[string]string
+}
+
+// Image is an image with its pre-annotations. Prediction class indices are
+// indices into the Labeler's labels.
+type Image struct {
+	// Path is the image's name in the export, usually relative to the
+	// directory labelled.
+	Path        string
+	Width       int
+	Height      int
+	Predictions []goyolov5.Prediction
+}
+
+// Writer exports labelled images. Close finishes the export; it does not
+// close the underlying writer.
+type Writer interface {
+	Write(img Image) error
+	Close() error
+}
+
+// Labeler pre-annotates images with a model.
+type Labeler struct {
+	yolov5 *goyolov5.YoloV5
+	nms    float64
+	labels []string
+	// classLabels is the label index of each model class, or -1.
+	classLabels []int
+	thresholds  []float64
+	// classes and minThreshold are passed to Infer, which then keeps every
+	// prediction some class threshold might accept.
+	classes      []uint
+	minThreshold float64
+}
+
+// NewLabeler returns a Labeler for yolov5, whose class names come from the
+// export's metadata or SetClassNames.
+func NewLabeler(yolov5 *goyolov5.YoloV5, opts Options) (*Labeler, error) {
+	names := yolov5.Info().ClassNames
+	if len(names) == 0 {
+		return nil, errors.New("model has no class names, set them with SetClassNames")
+	}
+	index := map[string]int{}
+	for i, name := range names {
+		index[name] = i
+	}
+	for name := range opts.ClassConfidence {
+		if _, ok := index[name]; !ok {
+			return nil, fmt.Errorf("confidence for unknown class %q", name)
+		}
+	}
+	for name := range opts.Rename {
+		if _, ok := index[name]; !ok {
+			return nil, fmt.Errorf("rename of unknown class %q", name)
+		}
+	}
+	if opts.NMS < 0 || opts.NMS > 1 {
+		return nil, fmt.Errorf("NMS threshold must be in [0, 1], got %v", opts.NMS)
+	}
+
+	l := &Labeler{
+		yolov5:       yolov5,
+		nms:          opts.NMS,
+		classLabels:  make([]int, len(names)),
+		thresholds:   make([]float64, len(names)),
+		minThreshold: 1,
+	}
+	labels := map[string]int{}
+	for i, name := range names {
+		label := name
+		if renamed, ok := opts.Rename[name]; ok {
+			label = renamed
+		}
+		if label == "" {
+			l.classLabels[i] = -1
+			continue
+		}
+		if _, ok := labels[label]; !ok {
+			labels[label] = len(l.labels)
+			l.labels = append(l.labels, label)
+		}
+		l.classLabels[i] = labels[label]
+
+		threshold := opts.Confidence
+		if t, ok := opts.ClassConfidence[name]; ok {
+			threshold = t
+		}
+		if threshold < 0 || threshold > 1 {
+			return nil, fmt.Errorf("confidence for %q must be in [0, 1], got %v", name, threshold)
+		}
+		l.thresholds[i] = threshold
+		if threshold < l.minThreshold {
+			l.minThreshold = threshold
+		}
+		l.classes = append(l.classes, uint(i))
+	}
+	if len(l.labels) == 0 {
+		return nil, errors.New("every class is renamed to nothing")
+	}
+	return l, nil
+}
+
+// Labels are the label names in the export, in model class order with
+// duplicates removed.
+func (l *Labeler) Labels() []string {
+	return l.labels
+}
+
+// Label runs the model on an image and keeps predictions above their class's
+// threshold.
+func (l *Labeler) Label(path string, tensor *goyolov5.Tensor) (Image, error) {
+	predictions, err := l.yolov5.Infer(tensor, float32(l.minThreshold), l.nms, nil, goyolov5.WithClasses(l.classes...))
+	if err != nil {
+		return Image{}, err
+	}
+
+	img := Image{Path: path, Width: tensor.Rect.Dx(), Height: tensor.Rect.Dy()}
+	for _, p := range predictions[0] {
+		if int(p.ClassIndex) >= len(l.classLabels) || l.classLabels[p.ClassIndex] < 0 || p.Confidence < l.thresholds[p.ClassIndex] {
+			continue
+		}
+		p.ClassIndex = uint(l.classLabels[p.ClassIndex])
+		img.Predictions = append(img.Predictions, p)
+	}
+	return img, nil
+}
@@ -0,0 +1,131 @@
+package autolabel
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/danhilltech/goyolov5"
+)
+
+func newTestLabeler(t *testing.T, opts Options) *Labeler {
+	backend := goyolov5.NewFakeBackend(3, 0, 10,
+		goyolov5.FakeDetection{Rect: image.Rect(10, 10, 60, 110), Confidence: 0.9, ClassIndex: 0, ClassConfidence: 0.9},
+		goyolov5.FakeDetection{Rect: image.Rect(100, 100, 150, 150), Confidence: 0.6, ClassIndex: 1, ClassConfidence: 0.9},
+		goyolov5.FakeDetection{Rect: image.Rect(200, 100, 250, 150), Confidence: 0.6, ClassIndex: 2, ClassConfidence: 0.9},
+	)
+	yolov5 := goyolov5.NewYoloV5WithBackend(backend, 320, 3, 0)
+	yolov5.SetClassNames([]string{"person", "car", "truck"})
+
+	l, err := NewLabeler(yolov5, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func TestLabeler(t *testing.T) {
+	l := newTestLabeler(t, Options{
+		Confidence:      0.3,
+		ClassConfidence: map[string]float64{"person": 0.7},
+		NMS:             0.45,
+		Rename:          map[string]string{"car": "vehicle", "truck": "vehicle"},
+	})
+	if labels := l.Labels(); len(labels) != 2 || labels[0] != "person" || labels[1] != "vehicle" {
+		t.Fatalf("unexpected labels %v", labels)
+	}
+
+	img, err := l.Label("a.png", goyolov5.NewTensor(image.Rect(0, 0, 320, 320)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Path != "a.png" || img.Width != 320 || img.Height != 320 {
+		t.Errorf("unexpected image %+v", img)
+	}
+	counts := map[uint]int{}
+	for _, p := range img.Predictions {
+		counts[p.ClassIndex]++
+	}
+	if len(img.Predictions) != 3 || counts[0] != 1 || counts[1] != 2 {
+		t.Errorf("expected a person and two vehicles, got %+v", img.Predictions)
+	}
+
+	// A higher person threshold drops it, and renaming to nothing drops trucks
+	l = newTestLabeler(t, Options{
+		Confidence:      0.3,
+		ClassConfidence: map[string]float64{"person": 0.95},
+		NMS:             0.45,
+		Rename:          map[string]string{"truck": ""},
+	})
+	img, err = l.Label("a.png", goyolov5.NewTensor(image.Rect(0, 0, 320, 320)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(img.Predictions) != 1 || img.Predictions[0].ClassIndex != 1 || l.Labels()[1] != "car" {
+		t.Errorf("expected one car, got %+v", img.Predictions)
+	}
+}
+
+func TestNewLabelerErrors(t *testing.T) {
+	backend := goyolov5.NewFakeBackend(3, 0, 10)
+	yolov5 := goyolov5.NewYoloV5WithBackend(backend, 320, 3, 0)
+	if _, err := NewLabeler(yolov5, Options{Confidence: 0.5, NMS: 0.5}); err == nil {
+		t.Error("expected an error for a model without class names")
+	}
+
+	yolov5.SetClassNames([]string{"a", "b", "c"})
+	for name, opts := range map[string]Options{
+		"unknown confidence class": {Confidence: 0.5, NMS: 0.5, ClassConfidence: map[string]float64{"d": 0.5}},
+		"unknown rename class":     {Confidence: 0.5, NMS: 0.5, Rename: map[string]string{"d": "e"}},
+		"confidence out of range":  {Confidence: 1.5, NMS: 0.5},
+		"everything dropped":       {Confidence: 0.5, NMS: 0.5, Rename: map[string]string{"a": "", "b": "", "c": ""}},
+	} {
+		if _, err := NewLabeler(yolov5, opts); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestYOLOWriter(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewYOLOWriter(dir, []string{"person", "vehicle"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(testImage("sub/a.jpg")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "sub/a.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "0 0.250000 0.300000 0.200000 0.200000\n1 0.900000 0.925000 0.200000 0.150000\n" {
+		t.Errorf("unexpected labels %q", data)
+	}
+	classes, err := os.ReadFile(filepath.Join(dir, "classes.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(classes) != "person\nvehicle\n" {
+		t.Errorf("unexpected classes %q", classes)
+	}
+}
+
+// testImage is a 200x200 image with a person and a vehicle running off its
+// right edge.
+func testImage(path string) Image {
+	return Image{
+		Path:   path,
+		Width:  200,
+		Height: 200,
+		Predictions: []goyolov5.Prediction{
+			{Rect: image.Rect(30, 40, 70, 80), Confidence: 0.9, ClassIndex: 0},
+			{Rect: image.Rect(160, 170, 220, 200), Confidence: 0.5, ClassIndex: 1},
+		},
+	}
+}
@@ -0,0 +1,96 @@
+package autolabel
+
+import (
+	"encoding/xml"
+	"fmt"
+	"image"
+	"io"
+)
+
+type cvatLabel struct {
+	Name string `xml:"name"`
+}
+
+type cvatMeta struct {
+	XMLName xml.Name    `xml:"meta"`
+	Labels  []cvatLabel `xml:"task>labels>label"`
+}
+
+type cvatBox struct {
+	Label    string `xml:"label,attr"`
+	Source   string `xml:"source,attr"`
+	Occluded int    `xml:"occluded,attr"`
+	XTL      string `xml:"xtl,attr"`
+	YTL      string `xml:"ytl,attr"`
+	XBR      string `xml:"xbr,attr"`
+	YBR      string `xml:"ybr,attr"`
+	ZOrder   int    `xml:"z_order,attr"`
+}
+
+type cvatImage struct {
+	XMLName xml.Name  `xml:"image"`
+	ID      int       `xml:"id,attr"`
+	Name    string    `xml:"name,attr"`
+	Width   int       `xml:"width,attr"`
+	Height  int       `xml:"height,attr"`
+	Boxes   []cvatBox `xml:"box"`
+}
+
+type cvatWriter struct {
+	w      io.Writer
+	enc    *xml.Encoder
+	labels []string
+	id     int
+}
+
+// NewCVATWriter writes boxes in the "CVAT for images 1.1" XML format, which
+// CVAT imports into a task whose images have the same names. Boxes are
+// marked as automatic annotations.
+func NewCVATWriter(w io.Writer, labels []string) (Writer, error) {
+	if _, err := io.WriteString(w, xml.Header+"<annotations>\n  <version>1.1</version>\n  "); err != nil {
+		return nil, err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("  ", "  ")
+
+	meta := cvatMeta{}
+	for _, label := range labels {
+		meta.Labels = append(meta.Labels, cvatLabel{Name: label})
+	}
+	if err := enc.Encode(meta); err != nil {
+		return nil, err
+	}
+	return &cvatWriter{w: w, enc: enc, labels: labels}, nil
+}
+
+func (c *cvatWriter) Write(img Image) error {
+	ci := cvatImage{ID: c.id, Name: img.Path, Width: img.Width, Height: img.Height}
+	bounds := image.Rect(0, 0, img.Width, img.Height)
+	for _, p := range img.Predictions {
+		if int(p.ClassIndex) >= len(c.labels) {
+			return fmt.Errorf("%s: prediction of unknown label %d", img.Path, p.ClassIndex)
+		}
+		r := p.Rect.Intersect(bounds)
+		if r.Empty() {
+			continue
+		}
+		ci.Boxes = append(ci.Boxes, cvatBox{
+			Label:  c.labels[p.ClassIndex],
+			Source: "auto",
+			XTL:    fmt.Sprintf("%.2f", float64(r.Min.X)),
+			YTL:    fmt.Sprintf("%.2f", float64(r.Min.Y)),
+			XBR:    fmt.Sprintf("%.2f", float64(r.Max.X)),
+			YBR:    fmt.Sprintf("%.2f", float64(r.Max.Y)),
+		})
+	}
+	c.id++
+	return c.enc.Encode(ci)
+}
+
+func (c *cvatWriter) Close() error {
+	if err := c.enc.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(c.w, "\n</annotations>\n")
+	return err
+}
@@ -0,0 +1,42 @@
+package autolabel
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+)
+
+func TestCVATWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewCVATWriter(&buf, []string{"person", "vehicle"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"a.jpg", "b.jpg"} {
+		if err := w.Write(testImage(path)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		Version string      `xml:"version"`
+		Labels  []cvatLabel `xml:"meta>task>labels>label"`
+		Images  []cvatImage `xml:"image"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("%v in:\n%s", err, buf.String())
+	}
+	if doc.Version != "1.1" || len(doc.Labels) != 2 || doc.Labels[1].Name != "vehicle" {
+		t.Errorf("unexpected header:\n%s", buf.String())
+	}
+	if len(doc.Images) != 2 || doc.Images[1].ID != 1 || doc.Images[1].Name != "b.jpg" || doc.Images[0].Width != 200 {
+		t.Fatalf("unexpected images:\n%s", buf.String())
+	}
+	expected := cvatBox{Label: "vehicle", Source: "auto", XTL: "160.00", YTL: "170.00", XBR: "200.00", YBR: "200.00"}
+	if boxes := doc.Images[0].Boxes; len(boxes) != 2 || boxes[1] != expected {
+		t.Errorf("expected clipped box %+v, got %+v", expected, boxes)
+	}
+}
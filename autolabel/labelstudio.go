@@ -0,0 +1,132 @@
+package autolabel
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"io"
+)
+
+// LabelStudioOptions configures a Label Studio export.
+type LabelStudioOptions struct {
+	// ImagePrefix is prepended to image paths to form the task's image URL,
+	// such as "/data/local-files/?d=" for local file storage.
+	ImagePrefix string
+	// FromName and ToName are the names of the RectangleLabels and Image
+	// tags of the project's labelling config, "label" and "image" by default.
+	FromName string
+	ToName   string
+	// ModelVersion tags the predictions, so reviewers can compare models.
+	ModelVersion string
+}
+
+type labelStudioValue struct {
+	X               float64  `json:"x"`
+	Y               float64  `json:"y"`
+	Width           float64  `json:"width"`
+	Height          float64  `json:"height"`
+	Rotation        float64  `json:"rotation"`
+	RectangleLabels []string `json:"rectanglelabels"`
+}
+
+type labelStudioResult struct {
+	ID             string           `json:"id"`
+	Type           string           `json:"type"`
+	FromName       string           `json:"from_name"`
+	ToName         string           `json:"to_name"`
+	OriginalWidth  int              `json:"original_width"`
+	OriginalHeight int              `json:"original_height"`
+	ImageRotation  int              `json:"image_rotation"`
+	Value          labelStudioValue `json:"value"`
+	Score          float64          `json:"score"`
+}
+
+type labelStudioPrediction struct {
+	ModelVersion string              `json:"model_version,omitempty"`
+	Score        float64             `json:"score"`
+	Result       []labelStudioResult `json:"result"`
+}
+
+type labelStudioTask struct {
+	Data        map[string]string       `json:"data"`
+	Predictions []labelStudioPrediction `json:"predictions"`
+}
+
+type labelStudioWriter struct {
+	w      io.Writer
+	opts   LabelStudioOptions
+	labels []string
+	n      int
+}
+
+// NewLabelStudioWriter writes a Label Studio JSON task list with one task
+// per image, its boxes as predictions for review. A task's score is the mean
+// confidence of its boxes, for sorting tasks in the data manager.
+func NewLabelStudioWriter(w io.Writer, labels []string, opts LabelStudioOptions) (Writer, error) {
+	if opts.FromName == "" {
+		opts.FromName = "label"
+	}
+	if opts.ToName == "" {
+		opts.ToName = "image"
+	}
+	if _, err := io.WriteString(w, "["); err != nil {
+		return nil, err
+	}
+	return &labelStudioWriter{w: w, opts: opts, labels: labels}, nil
+}
+
+func (l *labelStudioWriter) Write(img Image) error {
+	prediction := labelStudioPrediction{ModelVersion: l.opts.ModelVersion, Result: []labelStudioResult{}}
+	bounds := image.Rect(0, 0, img.Width, img.Height)
+	for _, p := range img.Predictions {
+		if int(p.ClassIndex) >= len(l.labels) {
+			return fmt.Errorf("%s: prediction of unknown label %d", img.Path, p.ClassIndex)
+		}
+		r := p.Rect.Intersect(bounds)
+		if r.Empty() {
+			continue
+		}
+		// Label Studio positions are percentages of the image size
+		prediction.Result = append(prediction.Result, labelStudioResult{
+			ID:             fmt.Sprintf("r%d", len(prediction.Result)),
+			Type:           "rectanglelabels",
+			FromName:       l.opts.FromName,
+			ToName:         l.opts.ToName,
+			OriginalWidth:  img.Width,
+			OriginalHeight: img.Height,
+			Value: labelStudioValue{
+				X:               100 * float64(r.Min.X) / float64(img.Width),
+				Y:               100 * float64(r.Min.Y) / float64(img.Height),
+				Width:           100 * float64(r.Dx()) / float64(img.Width),
+				Height:          100 * float64(r.Dy()) / float64(img.Height),
+				RectangleLabels: []string{l.labels[p.ClassIndex]},
+			},
+			Score: p.Confidence,
+		})
+		prediction.Score += p.Confidence
+	}
+	if len(prediction.Result) > 0 {
+		prediction.Score /= float64(len(prediction.Result))
+	}
+
+	task := labelStudioTask{
+		Data:        map[string]string{l.opts.ToName: l.opts.ImagePrefix + img.Path},
+		Predictions: []labelStudioPrediction{prediction},
+	}
+	data, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+	sep := ",\n"
+	if l.n == 0 {
+		sep = "\n"
+	}
+	l.n++
+	_, err = fmt.Fprintf(l.w, "%s%s", sep, data)
+	return err
+}
+
+func (l *labelStudioWriter) Close() error {
+	_, err := io.WriteString(l.w, "\n]\n")
+	return err
+}
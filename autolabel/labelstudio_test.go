@@ -0,0 +1,50 @@
+package autolabel
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestLabelStudioWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewLabelStudioWriter(&buf, []string{"person", "vehicle"}, LabelStudioOptions{ImagePrefix: "/data/local-files/?d=", ModelVersion: "yolov5s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	empty := testImage("b.jpg")
+	empty.Predictions = nil
+	for _, img := range []Image{testImage("a.jpg"), empty} {
+		if err := w.Write(img); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var tasks []labelStudioTask
+	if err := json.Unmarshal(buf.Bytes(), &tasks); err != nil {
+		t.Fatalf("%v in:\n%s", err, buf.String())
+	}
+	if len(tasks) != 2 || tasks[0].Data["image"] != "/data/local-files/?d=a.jpg" {
+		t.Fatalf("unexpected tasks:\n%s", buf.String())
+	}
+
+	prediction := tasks[0].Predictions[0]
+	if prediction.ModelVersion != "yolov5s" || prediction.Score != 0.7 || len(prediction.Result) != 2 {
+		t.Fatalf("unexpected prediction %+v", prediction)
+	}
+	result := prediction.Result[1]
+	expected := labelStudioValue{X: 80, Y: 85, Width: 20, Height: 15, RectangleLabels: []string{"vehicle"}}
+	if result.FromName != "label" || result.ToName != "image" || result.Type != "rectanglelabels" || result.OriginalWidth != 200 {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if v := result.Value; v.X != expected.X || v.Y != expected.Y || v.Width != expected.Width || v.Height != expected.Height || v.RectangleLabels[0] != "vehicle" {
+		t.Errorf("expected value %+v, got %+v", expected, v)
+	}
+
+	if p := tasks[1].Predictions[0]; p.Score != 0 || p.Result == nil || len(p.Result) != 0 {
+		t.Errorf("expected an empty prediction, got %+v", p)
+	}
+}
@@ -0,0 +1,44 @@
+package autolabel
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/danhilltech/goyolov5"
+)
+
+type yoloWriter struct {
+	dir    string
+	labels []string
+}
+
+// NewYOLOWriter writes a YOLO txt label file per image under dir, at the
+// image's path with a .txt extension, and the label names one per line to
+// classes.txt on Close.
+func NewYOLOWriter(dir string, labels []string) (Writer, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &yoloWriter{dir: dir, labels: labels}, nil
+}
+
+func (y *yoloWriter) Write(img Image) error {
+	path := filepath.Join(y.dir, strings.TrimSuffix(img.Path, filepath.Ext(img.Path))+".txt")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := goyolov5.WriteYOLOLabels(f, img.Predictions, img.Width, img.Height, false); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (y *yoloWriter) Close() error {
+	return os.WriteFile(filepath.Join(y.dir, "classes.txt"), []byte(strings.Join(y.labels, "\n")+"\n"), 0644)
+}
@@ -0,0 +1,82 @@
+package goyolov5
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrBackendUnavailable is returned when loading a model whose backend was not
+// compiled in, e.g. NewYoloV5 in a build with the nolibtorch tag.
+var ErrBackendUnavailable = errors.New("goyolov5: inference backend not available in this build")
+
+// InferenceBackend runs the forward pass of a model. Forward takes a
+// letterboxed uint8 HWC tensor at the model size and returns the raw float
+// output together with its shape, [batch, predictions, values per prediction]
+// for YOLOv5 detection heads.
+type InferenceBackend interface {
+	Forward(input *Tensor) ([]float32, []int, error)
+}
+
+// BatchBackend is implemented by backends that can forward several inputs of
+// the same size in a single call. The output shape's first dimension is the
+// number of inputs.
+type BatchBackend interface {
+	InferenceBackend
+	ForwardBatch(inputs []*Tensor) ([]float32, []int, error)
+}
+
+// forwardBatch runs inputs through the backend in one call when it supports
+// batching, falling back to one Forward per input otherwise.
+func forwardBatch(backend InferenceBackend, inputs []*Tensor) ([]float32, []int, error) {
+	if batchBackend, ok := backend.(BatchBackend); ok && len(inputs) > 1 {
+		return batchBackend.ForwardBatch(inputs)
+	}
+
+	var out []float32
+	var shape []int
+	for _, input := range inputs {
+		o, s, err := backend.Forward(input)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(s) == 0 || s[0] != 1 {
+			return nil, nil, fmt.Errorf("expected a batch of 1, got output shape %v", s)
+		}
+		if shape == nil {
+			shape = append([]int{0}, s[1:]...)
+		}
+		shape[0]++
+		out = append(out, o...)
+	}
+	return out, shape, nil
+}
+
+// hwcToCHW converts a uint8 HWC tensor into the float32 CHW layout, scaled to
+// [0, 1], that exported models without a built-in preprocessing step expect.
+func hwcToCHW(t *Tensor) []float32 {
+	w, h := t.Rect.Dx(), t.Rect.Dy()
+	plane := w * h
+	out := make([]float32, 3*plane)
+
+	for y := 0; y < h; y++ {
+		row := t.Pix[y*t.Stride : y*t.Stride+3*w]
+		for x := 0; x < w; x++ {
+			i := y*w + x
+			out[i] = float32(row[x*3+0]) / 255.0
+			out[plane+i] = float32(row[x*3+1]) / 255.0
+			out[2*plane+i] = float32(row[x*3+2]) / 255.0
+		}
+	}
+	return out
+}
+
+// normalizeCHW subtracts mean from and divides by std each channel of a CHW
+// tensor, as classification models expect.
+func normalizeCHW(chw []float32, mean, std *[3]float32) {
+	plane := len(chw) / 3
+	for c := 0; c < 3; c++ {
+		for i := c * plane; i < (c+1)*plane; i++ {
+			chw[i] = (chw[i] - mean[c]) / std[c]
+		}
+	}
+}
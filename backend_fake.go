@@ -0,0 +1,85 @@
+package goyolov5
+
+import (
+	"fmt"
+	"image"
+	"sync"
+)
+
+// FakeDetection is a single candidate box a FakeBackend emits, in model input
+// coordinates.
+type FakeDetection struct {
+	Rect            image.Rectangle
+	Confidence      float32
+	ClassIndex      int
+	ClassConfidence float32
+	// Keypoints holds x, y, visibility triplets for keypoint-head layouts
+	Keypoints []float32
+}
+
+// FakeBackend is a deterministic, in-memory InferenceBackend. Every call to
+// Forward returns the same output, built from its detections, and records the
+// input it was given. It needs no libtorch and no weights.
+type FakeBackend struct {
+	mu         sync.Mutex
+	nClasses   int
+	nKeypoints int
+	nPreds     int
+	detections []FakeDetection
+	inputs     []*Tensor
+}
+
+var _ BatchBackend = new(FakeBackend)
+
+// NewFakeBackend returns a FakeBackend producing nPreds candidate boxes per
+// image, the first of which are the given detections.
+func NewFakeBackend(nClasses, nKeypoints, nPreds int, detections ...FakeDetection) *FakeBackend {
+	return &FakeBackend{
+		nClasses:   nClasses,
+		nKeypoints: nKeypoints,
+		nPreds:     nPreds,
+		detections: detections,
+	}
+}
+
+// Forward implements InferenceBackend.
+func (b *FakeBackend) Forward(input *Tensor) ([]float32, []int, error) {
+	return b.ForwardBatch([]*Tensor{input})
+}
+
+// ForwardBatch implements BatchBackend, emitting the same detections for every
+// input.
+func (b *FakeBackend) ForwardBatch(inputs []*Tensor) ([]float32, []int, error) {
+	b.mu.Lock()
+	b.inputs = append(b.inputs, inputs...)
+	b.mu.Unlock()
+
+	if len(b.detections) > b.nPreds {
+		return nil, nil, fmt.Errorf("fake backend has %d detections but only %d predictions", len(b.detections), b.nPreds)
+	}
+
+	predSize := ClassesOffset + b.nClasses + 3*b.nKeypoints
+	out := make([]float32, len(inputs)*b.nPreds*predSize)
+
+	for batch := range inputs {
+		for i, d := range b.detections {
+			predVals := out[(batch*b.nPreds+i)*predSize : (batch*b.nPreds+i+1)*predSize]
+			predVals[0] = float32(d.Rect.Min.X+d.Rect.Max.X) / 2.0
+			predVals[1] = float32(d.Rect.Min.Y+d.Rect.Max.Y) / 2.0
+			predVals[2] = float32(d.Rect.Dx())
+			predVals[3] = float32(d.Rect.Dy())
+			predVals[4] = d.Confidence
+			predVals[ClassesOffset+d.ClassIndex] = d.ClassConfidence
+			copy(predVals[ClassesOffset+b.nClasses:], d.Keypoints)
+		}
+	}
+
+	return out, []int{len(inputs), b.nPreds, predSize}, nil
+}
+
+// Inputs returns every tensor passed to Forward so far, in call order.
+func (b *FakeBackend) Inputs() []*Tensor {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]*Tensor(nil), b.inputs...)
+}
@@ -0,0 +1,126 @@
+//go:build cgo && onnxruntime
+
+package goyolov5
+
+// #cgo LDFLAGS: -lonnxruntime
+// #include <stdlib.h>
+// #include "goyolo_onnx.h"
+import "C"
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
+const onnxMaxDims = 8
+
+// onnxBackend runs a YOLOv5 .onnx export through ONNX Runtime.
+type onnxBackend struct {
+	mu      sync.Mutex
+	session *C.onnx_session
+	// mean and std normalise inputs after scaling to [0, 1], if set.
+	mean, std *[3]float32
+}
+
+var _ InferenceBackend = new(onnxBackend)
+
+// NewYoloV5ONNX loads a YOLOv5 .onnx export with ONNX Runtime. Only float32
+// exports are supported. It shares the Infer API and post-processing with
+// NewYoloV5.
+func NewYoloV5ONNX(path string, device DeviceType, size int) (*YoloV5, error) {
+	backend, err := newONNXBackend(path, device)
+	if err != nil {
+		return nil, err
+	}
+
+	yolov5 := NewYoloV5WithBackend(backend, size, NClasses, 0)
+	yolov5.modelName = filepath.Base(path)
+
+	return yolov5, nil
+}
+
+// NewClassifierONNX loads a YOLOv5 classification .onnx export with ONNX
+// Runtime. Class names are not stored in ONNX exports; set them with
+// SetClassNames.
+func NewClassifierONNX(path string, device DeviceType, size int) (*Classifier, error) {
+	backend, err := newONNXBackend(path, device)
+	if err != nil {
+		return nil, err
+	}
+	backend.mean, backend.std = &ImageNetMean, &ImageNetStd
+
+	classifier := NewClassifierWithBackend(backend, size)
+	classifier.modelName = filepath.Base(path)
+	return classifier, nil
+}
+
+func newONNXBackend(path string, device DeviceType) (*onnxBackend, error) {
+	cpath := C.CString(path)
+	defer C.free(unsafe.Pointer(cpath))
+
+	var session *C.onnx_session
+	if err := onnxErr(C.onnx_load(cpath, C.int(device), &session)); err != nil {
+		C.onnx_free(session)
+		return nil, err
+	}
+
+	b := &onnxBackend{session: session}
+	runtime.SetFinalizer(b, func(b *onnxBackend) {
+		C.onnx_free(b.session)
+	})
+	return b, nil
+}
+
+// Forward implements InferenceBackend.
+func (b *onnxBackend) Forward(inputTensor *Tensor) ([]float32, []int, error) {
+	data := hwcToCHW(inputTensor)
+	if b.mean != nil && b.std != nil {
+		normalizeCHW(data, b.mean, b.std)
+	}
+	size := inputTensor.Rect.Size()
+	inputShape := []C.int64_t{1, 3, C.int64_t(size.Y), C.int64_t(size.X)}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var value C.onnx_value
+	err := onnxErr(C.onnx_run(b.session, (*C.float)(unsafe.Pointer(&data[0])), &inputShape[0], C.size_t(len(inputShape)), &value))
+	runtime.KeepAlive(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer C.onnx_free_value(value)
+
+	var dims [onnxMaxDims]C.int64_t
+	var nDims C.size_t
+	if err := onnxErr(C.onnx_shape(value, &dims[0], onnxMaxDims, &nDims)); err != nil {
+		return nil, nil, err
+	}
+	shape := make([]int, int(nDims))
+	numel := 1
+	for i := range shape {
+		shape[i] = int(dims[i])
+		numel *= shape[i]
+	}
+
+	var cdata *C.float
+	if err := onnxErr(C.onnx_data(value, &cdata)); err != nil {
+		return nil, nil, err
+	}
+
+	out := make([]float32, numel)
+	copy(out, (*[1 << 30]float32)(unsafe.Pointer(cdata))[:numel:numel])
+
+	return out, shape, nil
+}
+
+// onnxErr converts and frees an error message returned by the ONNX wrapper.
+func onnxErr(cerr *C.char) error {
+	if cerr == nil {
+		return nil
+	}
+	defer C.free(unsafe.Pointer(cerr))
+	return fmt.Errorf("onnxruntime API Error: %v", C.GoString(cerr))
+}
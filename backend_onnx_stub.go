@@ -0,0 +1,15 @@
+//go:build !cgo || !onnxruntime
+
+package goyolov5
+
+// NewYoloV5ONNX returns ErrBackendUnavailable unless the package is built with
+// the onnxruntime tag.
+func NewYoloV5ONNX(path string, device DeviceType, size int) (*YoloV5, error) {
+	return nil, ErrBackendUnavailable
+}
+
+// NewClassifierONNX returns ErrBackendUnavailable unless the package is built
+// with the onnxruntime tag.
+func NewClassifierONNX(path string, device DeviceType, size int) (*Classifier, error) {
+	return nil, ErrBackendUnavailable
+}
@@ -0,0 +1,78 @@
+//go:build cgo && onnxruntime && !nolibtorch && !cuda
+
+package goyolov5
+
+import (
+	"image"
+	"math"
+	"os"
+	"testing"
+)
+
+func TestONNXMatchesTorchScript(t *testing.T) {
+	torch, err := NewYoloV5("weights/yolov5n/yolov5n.torchscript.cpu.640.pt", DeviceCPU, 640, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	onnx, err := NewYoloV5ONNX("weights/yolov5n/yolov5n.cpu.640.onnx", DeviceCPU, 640)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open("tests/inputs/people2.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	input, _, err := image.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tensor := NewTensorFromImage(input)
+
+	inputTensor, _, _, _, err := torch.preProcess(tensor)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	torchOut, torchShape, err := torch.backend.Forward(inputTensor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	onnxOut, onnxShape, err := onnx.backend.Forward(inputTensor)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(torchShape) != len(onnxShape) {
+		t.Fatalf("shape mismatch: torchscript %v, onnx %v", torchShape, onnxShape)
+	}
+	for i := range torchShape {
+		if torchShape[i] != onnxShape[i] {
+			t.Fatalf("shape mismatch: torchscript %v, onnx %v", torchShape, onnxShape)
+		}
+	}
+
+	// Box coordinates are in pixels, scores in [0, 1]
+	for i := range torchOut {
+		tolerance := 1e-3
+		if i%PredSize < 4 {
+			tolerance = 1e-1
+		}
+		if math.Abs(float64(torchOut[i]-onnxOut[i])) > tolerance {
+			t.Fatalf("output %d differs: torchscript %f, onnx %f", i, torchOut[i], onnxOut[i])
+		}
+	}
+
+	torchPreds, err := torch.Infer(tensor, 0.5, 0.4, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	onnxPreds, err := onnx.Infer(tensor, 0.5, 0.4, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(torchPreds[0]) != len(onnxPreds[0]) {
+		t.Fatalf("expected %d predictions, got %d", len(torchPreds[0]), len(onnxPreds[0]))
+	}
+}
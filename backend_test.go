@@ -0,0 +1,145 @@
+package goyolov5
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestInferFakeBackend(t *testing.T) {
+	backend := NewFakeBackend(NClasses, 0, 100,
+		FakeDetection{Rect: image.Rect(10, 10, 60, 110), Confidence: 0.9, ClassIndex: COCO_PERSON, ClassConfidence: 0.9},
+		FakeDetection{Rect: image.Rect(12, 12, 62, 112), Confidence: 0.8, ClassIndex: COCO_PERSON, ClassConfidence: 0.8},
+		FakeDetection{Rect: image.Rect(100, 50, 150, 100), Confidence: 0.7, ClassIndex: 2, ClassConfidence: 0.6},
+		FakeDetection{Rect: image.Rect(200, 200, 250, 250), Confidence: 0.3, ClassIndex: 2, ClassConfidence: 0.6},
+	)
+	yolov5 := NewYoloV5WithBackend(backend, 320, NClasses, 0)
+
+	tensor := NewTensor(image.Rect(0, 0, 640, 480))
+	annotated := NewTensor(tensor.Rect)
+
+	predictions, err := yolov5.Infer(tensor, 0.5, 0.4, annotated)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inputs := backend.Inputs()
+	if len(inputs) != 1 {
+		t.Fatalf("expected 1 forward call, got %d", len(inputs))
+	}
+	if inputs[0].Rect != image.Rect(0, 0, 320, 320) {
+		t.Fatalf("expected a 320x320 model input, got %v", inputs[0].Rect)
+	}
+
+	if len(predictions) != 1 {
+		t.Fatalf("expected 1 batch, got %d", len(predictions))
+	}
+	if len(predictions[0]) != 2 {
+		t.Fatalf("expected 2 predictions, got %d", len(predictions[0]))
+	}
+
+	person := predictions[0][0]
+	if person.ClassIndex != COCO_PERSON || person.Confidence < 0.89 {
+		t.Fatalf("expected the most confident person box to survive NMS, got %+v", person)
+	}
+	if person.Rect != image.Rect(20, 20, 120, 220) {
+		t.Fatalf("expected box scaled to input coordinates, got %v", person.Rect)
+	}
+	if predictions[0][1].ClassIndex != 2 {
+		t.Fatalf("expected class 2, got %d", predictions[0][1].ClassIndex)
+	}
+
+	if annotated.RGBAAt(20, 20) != (color.RGBA{0, 255, 0, 255}) {
+		t.Fatalf("expected annotation at box corner")
+	}
+}
+
+func TestInferFakeBackendWrongLayout(t *testing.T) {
+	backend := NewFakeBackend(3, 0, 10)
+	yolov5 := NewYoloV5WithBackend(backend, 320, NClasses, 0)
+
+	_, err := yolov5.Infer(NewTensor(image.Rect(0, 0, 320, 320)), 0.5, 0.4, nil)
+	if err == nil {
+		t.Fatal("expected an error for a mismatched output layout")
+	}
+}
+
+func TestPostNMS(t *testing.T) {
+	yolov5 := &YoloV5{}
+	bboxes := [][]Bbox{{
+		{xmin: 0, ymin: 0, xmax: 10, ymax: 10, confidence: 0.6},
+		{xmin: 1, ymin: 1, xmax: 11, ymax: 11, confidence: 0.9},
+		{xmin: 50, ymin: 50, xmax: 60, ymax: 60, confidence: 0.5},
+	}}
+
+	res, err := yolov5.postNMS(bboxes, 0.4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res[0]) != 2 {
+		t.Fatalf("expected 2 boxes, got %d", len(res[0]))
+	}
+	if res[0][0].confidence != 0.9 {
+		t.Fatalf("expected the most confident box first, got %f", res[0][0].confidence)
+	}
+}
+
+func TestHWCToCHW(t *testing.T) {
+	tensor := NewTensor(image.Rect(0, 0, 2, 1))
+	tensor.Set(0, 0, color.RGBA{255, 0, 51, 255})
+	tensor.Set(1, 0, color.RGBA{0, 102, 255, 255})
+
+	chw := hwcToCHW(tensor)
+
+	expected := []float32{1, 0, 0, 0.4, 0.2, 1}
+	for i := range expected {
+		if chw[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, chw)
+		}
+	}
+}
+
+func TestInferWithClasses(t *testing.T) {
+	backend := NewFakeBackend(NClasses, 0, 10,
+		FakeDetection{Rect: image.Rect(10, 10, 60, 110), Confidence: 0.9, ClassIndex: COCO_PERSON, ClassConfidence: 0.9},
+		FakeDetection{Rect: image.Rect(100, 50, 150, 100), Confidence: 0.7, ClassIndex: 2, ClassConfidence: 0.6},
+	)
+	yolov5 := NewYoloV5WithBackend(backend, 320, NClasses, 0)
+
+	predictions, err := yolov5.Infer(NewTensor(image.Rect(0, 0, 640, 480)), 0.5, 0.4, nil, WithClasses(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(predictions[0]) != 1 || predictions[0][0].ClassIndex != 2 {
+		t.Fatalf("expected only class 2, got %+v", predictions[0])
+	}
+}
+
+func TestInferWithContext(t *testing.T) {
+	backend := NewFakeBackend(NClasses, 0, 10)
+	yolov5 := NewYoloV5WithBackend(backend, 320, NClasses, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := yolov5.Infer(NewTensor(image.Rect(0, 0, 640, 480)), 0.5, 0.4, nil, WithContext(ctx))
+	if err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if len(backend.Inputs()) != 0 {
+		t.Fatal("expected no forward pass after cancellation")
+	}
+}
+
+func TestInferWithTimings(t *testing.T) {
+	yolov5 := NewYoloV5WithBackend(NewFakeBackend(NClasses, 0, 10), 320, NClasses, 0)
+
+	var timings Timings
+	if _, err := yolov5.Infer(NewTensor(image.Rect(0, 0, 640, 480)), 0.5, 0.4, nil, WithTimings(&timings)); err != nil {
+		t.Fatal(err)
+	}
+	if timings.Preprocess <= 0 || timings.Forward <= 0 || timings.Postprocess <= 0 {
+		t.Fatalf("expected every stage timed, got %+v", timings)
+	}
+}
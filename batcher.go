@@ -0,0 +1,323 @@
+package goyolov5
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrBatcherClosed is returned by Batcher.Infer after Close.
+var ErrBatcherClosed = errors.New("batcher is closed")
+
+// BatcherConfig configures a Batcher.
+type BatcherConfig struct {
+	// MaxBatch is the largest number of requests run in one forward pass.
+	MaxBatch int
+	// MaxWait is the longest a batch waits for more requests once a model is
+	// free to run it.
+	MaxWait time.Duration
+	// FixedBatch, for models exported with a fixed batch dimension, is that
+	// batch size. Batches with fewer requests are padded with black inputs
+	// whose outputs are dropped. Zero means a dynamic batch dimension.
+	FixedBatch int
+}
+
+// BatcherStats counts the requests and forward passes of a Batcher.
+type BatcherStats struct {
+	Requests uint64
+	Batches  uint64
+	// BatchSizes[n] is the number of forward passes run with n requests.
+	BatchSizes []uint64
+}
+
+// MeanBatchSize is the mean number of requests per forward pass.
+func (s BatcherStats) MeanBatchSize() float64 {
+	if s.Batches == 0 {
+		return 0
+	}
+	var requests uint64
+	for n, count := range s.BatchSizes {
+		requests += uint64(n) * count
+	}
+	return float64(requests) / float64(s.Batches)
+}
+
+// Batcher collects concurrent single image Infer calls into batched forward
+// passes, which multiplies throughput on models exported with a dynamic or,
+// with FixedBatch set, a fixed batch dimension. A batch runs on the next free
+// model of a Pool once it has MaxBatch requests, or MaxWait after that model
+// was free. Letterboxing runs in the caller's goroutine and NMS per request,
+// so each request keeps its own thresholds.
+type Batcher struct {
+	// Accessed atomically, kept first for alignment
+	requests uint64
+	batches  uint64
+
+	pool   *Pool
+	config BatcherConfig
+	queue  chan *batchRequest
+	done   chan struct{}
+	wg     sync.WaitGroup
+	once   sync.Once
+
+	mu    sync.Mutex
+	sizes []uint64
+
+	// padding fills fixed size batches
+	padding *Tensor
+}
+
+// batchRequest is one Infer call waiting in a Batcher.
+type batchRequest struct {
+	ctx                 context.Context
+	input               *Tensor
+	scaleRatio          float64
+	origin              image.Point
+	confidenceThreshold float32
+	nmsThreshold        float64
+	options             inferOptions
+	result              chan batchResult
+}
+
+type batchResult struct {
+	predictions []Prediction
+	forward     time.Duration
+	err         error
+}
+
+// NewBatcher starts a Batcher running batches on the models of pool, which
+// must share an input size. Close stops it.
+func NewBatcher(pool *Pool, config BatcherConfig) (*Batcher, error) {
+	for _, yolov5 := range pool.all {
+		if yolov5.size != pool.all[0].size {
+			return nil, fmt.Errorf("pool models have input sizes %d and %d", pool.all[0].size, yolov5.size)
+		}
+	}
+	if config.MaxBatch < 1 {
+		return nil, fmt.Errorf("max batch must be at least 1, got %d", config.MaxBatch)
+	}
+	if config.MaxWait < 0 {
+		return nil, fmt.Errorf("max wait must not be negative, got %s", config.MaxWait)
+	}
+	if config.FixedBatch < 0 || (config.FixedBatch > 0 && config.MaxBatch > config.FixedBatch) {
+		return nil, fmt.Errorf("max batch %d must not exceed the fixed batch size %d", config.MaxBatch, config.FixedBatch)
+	}
+
+	b := &Batcher{
+		pool:   pool,
+		config: config,
+		queue:  make(chan *batchRequest),
+		done:   make(chan struct{}),
+		sizes:  make([]uint64, config.MaxBatch+1),
+	}
+	if config.FixedBatch > 0 {
+		size := pool.all[0].size
+		b.padding = NewTensor(image.Rect(0, 0, size, size))
+	}
+	b.wg.Add(1)
+	go b.run()
+	return b, nil
+}
+
+// Infer queues a single image for the next batch and waits for its
+// predictions. It takes the same arguments as YoloV5.Infer, except for
+// WithAugment, which cannot be batched.
+func (b *Batcher) Infer(ctx context.Context, inputTensorRaw *Tensor, confidenceThreshold float32, nmsThreshold float64, annotateTensor *Tensor, opts ...InferOption) ([][]Prediction, error) {
+	options := newInferOptions(opts)
+	if len(options.augmentations) > 0 {
+		return nil, errors.New("test-time augmentation cannot be batched")
+	}
+	atomic.AddUint64(&b.requests, 1)
+
+	start := time.Now()
+	inputTensor, _, _, scaleRatio, err := b.pool.all[0].preProcess(inputTensorRaw)
+	if err != nil {
+		return nil, err
+	}
+	preprocess := time.Since(start)
+
+	req := &batchRequest{
+		ctx:                 ctx,
+		input:               inputTensor,
+		scaleRatio:          scaleRatio,
+		origin:              inputTensorRaw.Rect.Min,
+		confidenceThreshold: confidenceThreshold,
+		nmsThreshold:        nmsThreshold,
+		options:             options,
+		result:              make(chan batchResult, 1),
+	}
+
+	select {
+	case b.queue <- req:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-b.done:
+		return nil, ErrBatcherClosed
+	}
+
+	var res batchResult
+	select {
+	case res = <-req.result:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if res.err != nil {
+		return nil, res.err
+	}
+
+	if annotateTensor != nil {
+		options.annotate(annotateTensor, res.predictions)
+	}
+	if options.timings != nil {
+		*options.timings = Timings{
+			Preprocess:  preprocess,
+			Forward:     res.forward,
+			Postprocess: time.Since(start) - preprocess - res.forward,
+		}
+	}
+
+	return [][]Prediction{res.predictions}, nil
+}
+
+// Stats returns the Batcher's counters so far.
+func (b *Batcher) Stats() BatcherStats {
+	b.mu.Lock()
+	sizes := append([]uint64(nil), b.sizes...)
+	b.mu.Unlock()
+
+	return BatcherStats{
+		Requests:   atomic.LoadUint64(&b.requests),
+		Batches:    atomic.LoadUint64(&b.batches),
+		BatchSizes: sizes,
+	}
+}
+
+// Close stops accepting requests and waits for running batches to finish.
+func (b *Batcher) Close() {
+	b.once.Do(func() {
+		close(b.done)
+	})
+	b.wg.Wait()
+}
+
+func (b *Batcher) run() {
+	defer b.wg.Done()
+
+	for {
+		var first *batchRequest
+		select {
+		case first = <-b.queue:
+		case <-b.done:
+			return
+		}
+
+		// Requests keep arriving while every model is busy, so MaxWait starts
+		// once a model is free rather than eating into the wait for one
+		yolov5, err := b.pool.Get(context.Background())
+		if err != nil {
+			first.result <- batchResult{err: err}
+			continue
+		}
+		deadline := time.NewTimer(b.config.MaxWait)
+
+		batch := []*batchRequest{first}
+	collect:
+		for len(batch) < b.config.MaxBatch {
+			select {
+			case req := <-b.queue:
+				batch = append(batch, req)
+			case <-deadline.C:
+				break collect
+			case <-b.done:
+				break collect
+			}
+		}
+		deadline.Stop()
+
+		b.wg.Add(1)
+		go func() {
+			defer b.wg.Done()
+			defer b.pool.Put(yolov5)
+			b.runBatch(yolov5, batch)
+		}()
+	}
+}
+
+// runBatch runs one forward pass over the live requests of batch and answers
+// each of them.
+func (b *Batcher) runBatch(yolov5 *YoloV5, batch []*batchRequest) {
+	live := batch[:0]
+	for _, req := range batch {
+		if err := req.ctx.Err(); err != nil {
+			req.result <- batchResult{err: err}
+			continue
+		}
+		live = append(live, req)
+	}
+	if len(live) == 0 {
+		return
+	}
+
+	atomic.AddUint64(&b.batches, 1)
+	b.mu.Lock()
+	b.sizes[len(live)]++
+	b.mu.Unlock()
+
+	inputs := make([]*Tensor, len(live), len(live)+b.config.FixedBatch)
+	for i, req := range live {
+		inputs[i] = req.input
+	}
+	for len(inputs) < b.config.FixedBatch {
+		inputs = append(inputs, b.padding)
+	}
+
+	start := time.Now()
+	rawOutput, shape, err := forwardBatch(yolov5.backend, inputs)
+	forward := time.Since(start)
+	if err == nil && (len(shape) == 0 || shape[0] != len(inputs)) {
+		err = fmt.Errorf("expected %d outputs, got output shape %v", len(inputs), shape)
+	}
+	var npreds int
+	if err == nil {
+		_, npreds, err = yolov5.outputShape(rawOutput, shape)
+	}
+	if err != nil {
+		for _, req := range live {
+			req.result <- batchResult{err: err}
+		}
+		return
+	}
+
+	predSize := yolov5.predSize()
+	for i, req := range live {
+		predictions, err := yolov5.decode(rawOutput[i*npreds*predSize:(i+1)*npreds*predSize], npreds, req)
+		req.result <- batchResult{predictions: predictions, forward: forward, err: err}
+	}
+}
+
+// decode runs confidence filtering and NMS on one image's raw output with the
+// request's own thresholds.
+func (yolov5 *YoloV5) decode(rawOutput []float32, npreds int, req *batchRequest) ([]Prediction, error) {
+	bboxes, err := yolov5.postConfidence(rawOutput, yolov5.nClasses, yolov5.nKeypoints, npreds, req.confidenceThreshold)
+	if err != nil {
+		return nil, err
+	}
+	req.options.filterClasses(bboxes)
+
+	bboxesRes, err := yolov5.postNMS(bboxes, req.nmsThreshold)
+	if err != nil {
+		return nil, err
+	}
+
+	var predictions []Prediction
+	for _, c := range bboxesRes {
+		for _, b := range c {
+			predictions = append(predictions, b.toPrediction(req.scaleRatio, req.origin))
+		}
+	}
+	return predictions, nil
+}
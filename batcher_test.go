@@ -0,0 +1,208 @@
+package goyolov5
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestBatcher(t *testing.T, config BatcherConfig) *Batcher {
+	backend := NewFakeBackend(NClasses, 0, 10,
+		FakeDetection{Rect: image.Rect(10, 10, 60, 110), Confidence: 0.7, ClassIndex: COCO_PERSON, ClassConfidence: 0.9},
+	)
+	pool, err := NewPool(NewYoloV5WithBackend(backend, 320, NClasses, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	batcher, err := NewBatcher(pool, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(batcher.Close)
+	return batcher
+}
+
+func TestBatcher(t *testing.T) {
+	batcher := newTestBatcher(t, BatcherConfig{MaxBatch: 4, MaxWait: time.Second})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		// Every other request raises its threshold above the detection
+		confidence := float32(0.5)
+		if i%2 == 1 {
+			confidence = 0.8
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			predictions, err := batcher.Infer(context.Background(), NewTensor(image.Rect(0, 0, 640, 480)), confidence, 0.4, nil)
+			if err != nil {
+				errs <- err
+				return
+			}
+			expected := 1
+			if confidence > 0.7 {
+				expected = 0
+			}
+			if len(predictions) != 1 || len(predictions[0]) != expected {
+				t.Errorf("expected %d predictions at confidence %f, got %v", expected, confidence, predictions)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	stats := batcher.Stats()
+	if stats.Requests != 8 || stats.Batches != 2 || stats.BatchSizes[4] != 2 {
+		t.Fatalf("expected 2 full batches, got %+v", stats)
+	}
+	if stats.MeanBatchSize() != 4 {
+		t.Fatalf("expected a mean batch size of 4, got %f", stats.MeanBatchSize())
+	}
+}
+
+func TestBatcherMaxWait(t *testing.T) {
+	batcher := newTestBatcher(t, BatcherConfig{MaxBatch: 4, MaxWait: 10 * time.Millisecond})
+
+	annotated := NewTensor(image.Rect(0, 0, 640, 480))
+	var timings Timings
+	predictions, err := batcher.Infer(context.Background(), NewTensor(image.Rect(0, 0, 640, 480)), 0.5, 0.4, annotated, WithTimings(&timings))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(predictions[0]) != 1 || predictions[0][0].Rect != image.Rect(20, 20, 120, 220) {
+		t.Fatalf("unexpected predictions %v", predictions)
+	}
+	if annotated.RGBAAt(20, 20).G != 255 {
+		t.Fatal("expected the prediction drawn")
+	}
+	if timings.Preprocess <= 0 || timings.Forward <= 0 {
+		t.Fatalf("expected timings, got %+v", timings)
+	}
+	if stats := batcher.Stats(); stats.BatchSizes[1] != 1 {
+		t.Fatalf("expected a batch of 1 after the wait, got %+v", stats)
+	}
+}
+
+// fixedBatchBackend is a FakeBackend exported with a fixed batch dimension.
+type fixedBatchBackend struct {
+	*FakeBackend
+	batch int
+}
+
+func (b *fixedBatchBackend) Forward(input *Tensor) ([]float32, []int, error) {
+	return b.ForwardBatch([]*Tensor{input})
+}
+
+func (b *fixedBatchBackend) ForwardBatch(inputs []*Tensor) ([]float32, []int, error) {
+	if len(inputs) != b.batch {
+		return nil, nil, fmt.Errorf("model has a fixed batch of %d, got %d", b.batch, len(inputs))
+	}
+	return b.FakeBackend.ForwardBatch(inputs)
+}
+
+func TestBatcherFixedBatch(t *testing.T) {
+	backend := &fixedBatchBackend{FakeBackend: NewFakeBackend(NClasses, 0, 10,
+		FakeDetection{Rect: image.Rect(10, 10, 60, 110), Confidence: 0.7, ClassIndex: COCO_PERSON, ClassConfidence: 0.9},
+	), batch: 4}
+	pool, err := NewPool(NewYoloV5WithBackend(backend, 320, NClasses, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewBatcher(pool, BatcherConfig{MaxBatch: 8, FixedBatch: 4}); err == nil {
+		t.Fatal("expected an error for a max batch above the fixed batch")
+	}
+	batcher, err := NewBatcher(pool, BatcherConfig{MaxBatch: 4, MaxWait: time.Millisecond, FixedBatch: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer batcher.Close()
+
+	// A lone request is padded to the fixed batch, and the padding dropped
+	predictions, err := batcher.Infer(context.Background(), NewTensor(image.Rect(0, 0, 640, 480)), 0.5, 0.4, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(predictions) != 1 || len(predictions[0]) != 1 || predictions[0][0].Rect != image.Rect(20, 20, 120, 220) {
+		t.Fatalf("unexpected predictions %v", predictions)
+	}
+	if inputs := backend.Inputs(); len(inputs) != 4 {
+		t.Fatalf("expected a padded batch of 4, got %d inputs", len(inputs))
+	}
+}
+
+// slowBackend is a FakeBackend whose forward passes take delay.
+type slowBackend struct {
+	*FakeBackend
+	delay time.Duration
+}
+
+func (b *slowBackend) Forward(input *Tensor) ([]float32, []int, error) {
+	return b.ForwardBatch([]*Tensor{input})
+}
+
+func (b *slowBackend) ForwardBatch(inputs []*Tensor) ([]float32, []int, error) {
+	time.Sleep(b.delay)
+	return b.FakeBackend.ForwardBatch(inputs)
+}
+
+func TestBatcherSaturated(t *testing.T) {
+	backend := &slowBackend{FakeBackend: NewFakeBackend(NClasses, 0, 10), delay: 30 * time.Millisecond}
+	pool, err := NewPool(NewYoloV5WithBackend(backend, 320, NClasses, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	batcher, err := NewBatcher(pool, BatcherConfig{MaxBatch: 8, MaxWait: 5 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer batcher.Close()
+
+	// Requests queue up while the only model is busy, and fill the next batch
+	var wg sync.WaitGroup
+	for i := 0; i < 64; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := batcher.Infer(context.Background(), NewTensor(image.Rect(0, 0, 320, 320)), 0.5, 0.4, nil); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if stats := batcher.Stats(); stats.MeanBatchSize() < 6 {
+		t.Fatalf("expected batches near the max of 8 under load, got a mean of %f: %+v", stats.MeanBatchSize(), stats)
+	}
+}
+
+func TestBatcherErrors(t *testing.T) {
+	batcher := newTestBatcher(t, BatcherConfig{MaxBatch: 2, MaxWait: time.Millisecond})
+
+	if _, err := batcher.Infer(context.Background(), NewTensor(image.Rect(0, 0, 640, 480)), 0.5, 0.4, nil, WithAugment()); err == nil {
+		t.Fatal("expected an error for augmentation")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := batcher.Infer(ctx, NewTensor(image.Rect(0, 0, 640, 480)), 0.5, 0.4, nil); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	batcher.Close()
+	if _, err := batcher.Infer(context.Background(), NewTensor(image.Rect(0, 0, 640, 480)), 0.5, 0.4, nil); err != ErrBatcherClosed {
+		t.Fatalf("expected ErrBatcherClosed, got %v", err)
+	}
+
+	pool, _ := NewPool(NewYoloV5WithBackend(NewFakeBackend(NClasses, 0, 10), 320, NClasses, 0))
+	if _, err := NewBatcher(pool, BatcherConfig{}); err == nil {
+		t.Fatal("expected an error for a zero max batch")
+	}
+}
@@ -0,0 +1,112 @@
+package goyolov5
+
+import (
+	"fmt"
+	"image/color"
+)
+
+// CascadeOptions configures a Cascade.
+type CascadeOptions struct {
+	// Classes are the detector classes whose predictions are classified, or
+	// every class if empty.
+	Classes []uint
+	// TopK is how many classifications each prediction gets, 1 if zero.
+	TopK int
+	// MinScore drops classifications less likely than it.
+	MinScore float64
+	// BatchSize is the most crops per classifier forward pass, 1 if zero.
+	// Larger batches need a classifier exported with a dynamic batch
+	// dimension.
+	BatchSize int
+	// Margin grows boxes by a fraction of their width and height on each
+	// side, and MarginPixels by a fixed number of pixels, before cropping.
+	Margin       float64
+	MarginPixels int
+}
+
+// Cascade runs a detector, then a classifier on the crops of selected
+// classes' predictions, e.g. vehicle make on COCO cars and trucks.
+type Cascade struct {
+	detector   *YoloV5
+	classifier *Classifier
+	opts       CascadeOptions
+	classes    map[uint]bool
+}
+
+// NewCascade returns a Cascade of detector and classifier.
+func NewCascade(detector *YoloV5, classifier *Classifier, opts CascadeOptions) (*Cascade, error) {
+	if opts.TopK < 0 {
+		return nil, fmt.Errorf("topK must not be negative, got %d", opts.TopK)
+	}
+	if opts.TopK == 0 {
+		opts.TopK = 1
+	}
+	if opts.MinScore < 0 || opts.MinScore > 1 {
+		return nil, fmt.Errorf("minimum score must be in [0, 1], got %v", opts.MinScore)
+	}
+
+	c := &Cascade{detector: detector, classifier: classifier, opts: opts}
+	if len(opts.Classes) > 0 {
+		c.classes = make(map[uint]bool, len(opts.Classes))
+		for _, class := range opts.Classes {
+			if int(class) >= detector.nClasses {
+				return nil, fmt.Errorf("class %d is not one of the detector's %d classes", class, detector.nClasses)
+			}
+			c.classes[class] = true
+		}
+	}
+	return c, nil
+}
+
+// Infer runs the detector like YoloV5.Infer, then classifies its predictions.
+// The annotateTensor is drawn once predictions are classified, so an
+// Annotator can label them with their top classification.
+func (c *Cascade) Infer(inputTensor *Tensor, confidenceThreshold float32, nmsThreshold float64, annotateTensor *Tensor, opts ...InferOption) ([][]Prediction, error) {
+	predictions, err := c.detector.Infer(inputTensor, confidenceThreshold, nmsThreshold, nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Classify(inputTensor, predictions[0]); err != nil {
+		return nil, err
+	}
+	if annotateTensor != nil {
+		newInferOptions(opts).annotate(annotateTensor, predictions[0])
+	}
+	return predictions, nil
+}
+
+// Classify crops the selected classes' predictions from img, classifies the
+// crops in batches and sets each prediction's Classifications.
+func (c *Cascade) Classify(img *Tensor, predictions []Prediction) error {
+	var selected []int
+	var crops []*Tensor
+	for i, pred := range predictions {
+		if c.classes != nil && !c.classes[pred.ClassIndex] {
+			continue
+		}
+		crop := img.SquareCrop(expandRect(pred.Rect, c.opts.Margin, c.opts.MarginPixels), c.classifier.size, color.Black)
+		if crop == nil {
+			continue
+		}
+		selected = append(selected, i)
+		crops = append(crops, crop)
+	}
+	if len(crops) == 0 {
+		return nil
+	}
+
+	results, err := c.classifier.Classify(crops, c.opts.TopK, c.opts.BatchSize)
+	if err != nil {
+		return err
+	}
+	for j, i := range selected {
+		var kept []Classification
+		for _, cls := range results[j] {
+			if cls.Score >= c.opts.MinScore {
+				kept = append(kept, cls)
+			}
+		}
+		predictions[i].Classifications = kept
+	}
+	return nil
+}
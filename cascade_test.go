@@ -0,0 +1,68 @@
+package goyolov5
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestCascade(t *testing.T) {
+	detector := NewYoloV5WithBackend(NewFakeBackend(NClasses, 0, 10,
+		FakeDetection{Rect: image.Rect(10, 10, 60, 110), Confidence: 0.9, ClassIndex: COCO_PERSON, ClassConfidence: 0.9},
+		FakeDetection{Rect: image.Rect(100, 50, 150, 100), Confidence: 0.8, ClassIndex: 2, ClassConfidence: 0.8},
+		FakeDetection{Rect: image.Rect(200, 200, 260, 230), Confidence: 0.7, ClassIndex: 2, ClassConfidence: 0.8},
+	), 320, NClasses, 0)
+	backend := &channelBackend{}
+	classifier := NewClassifierWithBackend(backend, 64)
+	classifier.SetClassNames([]string{"red", "green", "blue"})
+
+	cascade, err := NewCascade(detector, classifier, CascadeOptions{Classes: []uint{2}, TopK: 2, MinScore: 0.5, BatchSize: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img := NewTensor(image.Rect(0, 0, 320, 320))
+	img.FillRect(image.Rect(10, 10, 60, 110), color.RGBA{255, 0, 0, 255})
+	img.FillRect(image.Rect(100, 50, 150, 100), color.RGBA{255, 0, 0, 255})
+	img.FillRect(image.Rect(200, 200, 260, 230), color.RGBA{0, 0, 255, 255})
+	annotated := NewTensorFromImage(img)
+
+	predictions, err := cascade.Infer(img, 0.5, 0.4, annotated, WithAnnotator(NewAnnotator(COCONames)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backend.batches) != 1 || backend.batches[0] != 2 {
+		t.Errorf("expected both cars in one batch, got %v", backend.batches)
+	}
+
+	preds := predictions[0]
+	if len(preds) != 3 {
+		t.Fatalf("expected 3 predictions, got %d", len(preds))
+	}
+	if preds[0].Classifications != nil {
+		t.Errorf("expected the person left unclassified, got %+v", preds[0].Classifications)
+	}
+	for i, want := range map[int]string{1: "red", 2: "blue"} {
+		c := preds[i].Classifications
+		if len(c) != 1 || c[0].Class != want {
+			t.Errorf("expected prediction %d classified %s above the minimum score only, got %+v", i, want, c)
+		}
+	}
+	if label := NewAnnotator(COCONames).Label(preds[1]); !strings.HasPrefix(label, "car 0.80 red ") {
+		t.Errorf("unexpected label %q", label)
+	}
+	if annotated.RGBAAt(100, 50) != DefaultPalette[2] {
+		t.Errorf("expected the annotation drawn after classification")
+	}
+	if d := NewDetections(preds, COCONames); d[2].Classifications[0].Class != "blue" {
+		t.Errorf("expected classifications in detections, got %+v", d[2])
+	}
+
+	if _, err := NewCascade(detector, classifier, CascadeOptions{Classes: []uint{80}}); err == nil {
+		t.Error("expected an error for a class the detector lacks")
+	}
+	if _, err := NewCascade(detector, classifier, CascadeOptions{MinScore: 2}); err == nil {
+		t.Error("expected an error for a minimum score above 1")
+	}
+}
@@ -0,0 +1,157 @@
+package goyolov5
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"math"
+	"path/filepath"
+	"sort"
+)
+
+// ImageNetMean and ImageNetStd are the per-channel statistics YOLOv5
+// classification models normalise their inputs with.
+var (
+	ImageNetMean = [3]float32{0.485, 0.456, 0.406}
+	ImageNetStd  = [3]float32{0.229, 0.224, 0.225}
+)
+
+// Classification is one class a classifier assigns an image, with its
+// softmax probability.
+type Classification struct {
+	ClassIndex uint    `json:"class_index"`
+	Class      string  `json:"class,omitempty"`
+	Score      float64 `json:"score"`
+}
+
+// Classifier runs an image classification model, such as a YOLOv5 -cls
+// export, whose output is a row of class logits per image.
+type Classifier struct {
+	backend   InferenceBackend
+	size      int
+	modelName string
+	names     []string
+}
+
+// NewClassifier loads a TorchScript classification export. Class names come
+// from the export's metadata, if present.
+func NewClassifier(path string, device DeviceType, size int, half bool) (*Classifier, error) {
+	backend, config, err := newTorchClassifierBackend(path, device, half)
+	if err != nil {
+		return nil, err
+	}
+
+	classifier := NewClassifierWithBackend(backend, size)
+	classifier.modelName = filepath.Base(path)
+	if config != "" {
+		var c torchConfig
+		if err := json.Unmarshal([]byte(config), &c); err != nil {
+			return nil, fmt.Errorf("reading %s config.txt: %w", classifier.modelName, err)
+		}
+		if len(c.Names) > 0 {
+			if classifier.names, err = parseConfigNames(c.Names); err != nil {
+				return nil, fmt.Errorf("reading %s config.txt: %w", classifier.modelName, err)
+			}
+		}
+	}
+	return classifier, nil
+}
+
+// NewClassifierWithBackend wraps an already constructed InferenceBackend
+// taking size x size inputs. The backend normalises its inputs.
+func NewClassifierWithBackend(backend InferenceBackend, size int) *Classifier {
+	return &Classifier{backend: backend, size: size}
+}
+
+// SetClassNames names the classifier's classes, by class index.
+func (c *Classifier) SetClassNames(names []string) {
+	c.names = names
+}
+
+// ClassNames are the classifier's class names, if known.
+func (c *Classifier) ClassNames() []string {
+	return c.names
+}
+
+// Size is the square input size of the classifier.
+func (c *Classifier) Size() int {
+	return c.size
+}
+
+// Classify classifies images, returning the topK most likely classes of each,
+// most likely first. Images are padded square and resized to the model size,
+// and run batchSize at a time; more than 1 needs a model exported with a
+// dynamic batch dimension.
+func (c *Classifier) Classify(images []*Tensor, topK, batchSize int) ([][]Classification, error) {
+	if topK < 1 {
+		return nil, fmt.Errorf("topK must be at least 1, got %d", topK)
+	}
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	results := make([][]Classification, len(images))
+	for start := 0; start < len(images); start += batchSize {
+		end := start + batchSize
+		if end > len(images) {
+			end = len(images)
+		}
+
+		inputs := make([]*Tensor, end-start)
+		for i, img := range images[start:end] {
+			if inputs[i] = img.SquareCrop(img.Rect, c.size, color.Black); inputs[i] == nil {
+				return nil, fmt.Errorf("image %d is empty", start+i)
+			}
+		}
+
+		output, shape, err := forwardBatch(c.backend, inputs)
+		if err != nil {
+			return nil, err
+		}
+		if len(shape) != 2 || shape[0] != len(inputs) || shape[1] < 1 || len(output) < shape[0]*shape[1] {
+			return nil, fmt.Errorf("expected %d rows of class scores, got output shape %v", len(inputs), shape)
+		}
+		if len(c.names) > 0 && len(c.names) != shape[1] {
+			return nil, fmt.Errorf("classifier has %d class names but outputs %d classes", len(c.names), shape[1])
+		}
+
+		for i := range inputs {
+			results[start+i] = c.topK(output[i*shape[1]:(i+1)*shape[1]], topK)
+		}
+	}
+	return results, nil
+}
+
+// topK softmaxes a row of logits and returns its k most likely classes.
+func (c *Classifier) topK(logits []float32, k int) []Classification {
+	max := logits[0]
+	for _, l := range logits {
+		if l > max {
+			max = l
+		}
+	}
+	scores := make([]float64, len(logits))
+	var sum float64
+	for i, l := range logits {
+		scores[i] = math.Exp(float64(l - max))
+		sum += scores[i]
+	}
+
+	indices := make([]int, len(scores))
+	for i := range indices {
+		indices[i] = i
+	}
+	sort.SliceStable(indices, func(a, b int) bool { return scores[indices[a]] > scores[indices[b]] })
+	if k > len(indices) {
+		k = len(indices)
+	}
+
+	classifications := make([]Classification, k)
+	for i, index := range indices[:k] {
+		classifications[i] = Classification{ClassIndex: uint(index), Score: scores[index] / sum}
+		if index < len(c.names) {
+			classifications[i].Class = c.names[index]
+		}
+	}
+	return classifications
+}
@@ -0,0 +1,95 @@
+package goyolov5
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sync"
+	"testing"
+)
+
+// channelBackend is a classifier backend whose logits are an image's mean
+// red, green and blue, scaled to [0, 10]. It records the size of each batch.
+type channelBackend struct {
+	mu      sync.Mutex
+	batches []int
+}
+
+func (b *channelBackend) Forward(input *Tensor) ([]float32, []int, error) {
+	return b.ForwardBatch([]*Tensor{input})
+}
+
+func (b *channelBackend) ForwardBatch(inputs []*Tensor) ([]float32, []int, error) {
+	b.mu.Lock()
+	b.batches = append(b.batches, len(inputs))
+	b.mu.Unlock()
+
+	out := make([]float32, 0, 3*len(inputs))
+	for _, input := range inputs {
+		var sums [3]float32
+		for i := 0; i < len(input.Pix); i += 3 {
+			for c := range sums {
+				sums[c] += float32(input.Pix[i+c])
+			}
+		}
+		n := float32(input.Rect.Dx() * input.Rect.Dy())
+		for c := range sums {
+			out = append(out, sums[c]/n/255*10)
+		}
+	}
+	return out, []int{len(inputs), 3}, nil
+}
+
+func filled(w, h int, col color.RGBA) *Tensor {
+	img := NewTensor(image.Rect(0, 0, w, h))
+	img.FillRect(img.Rect, col)
+	return img
+}
+
+func TestClassify(t *testing.T) {
+	backend := &channelBackend{}
+	classifier := NewClassifierWithBackend(backend, 32)
+	classifier.SetClassNames([]string{"red", "green", "blue"})
+
+	images := []*Tensor{
+		filled(10, 20, color.RGBA{255, 0, 0, 255}),
+		filled(40, 40, color.RGBA{0, 0, 255, 255}),
+		filled(5, 5, color.RGBA{0, 255, 128, 255}),
+	}
+	results, err := classifier.Classify(images, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backend.batches) != 2 || backend.batches[0] != 2 || backend.batches[1] != 1 {
+		t.Errorf("expected batches of 2 and 1, got %v", backend.batches)
+	}
+
+	// The red image is padded square with black, halving its mean red
+	red := results[0]
+	want := math.Exp(5) / (math.Exp(5) + 2)
+	if len(red) != 2 || red[0].Class != "red" || math.Abs(red[0].Score-want) > 1e-4 {
+		t.Errorf("unexpected classification %+v, want red %v", red, want)
+	}
+	if results[1][0].Class != "blue" || results[2][0].Class != "green" || results[2][1].Class != "blue" {
+		t.Errorf("unexpected classifications %+v", results)
+	}
+	var sum float64
+	for _, c := range (&Classifier{}).topK([]float32{1, 2, 3}, 3) {
+		sum += c.Score
+	}
+	if math.Abs(sum-1) > 1e-9 {
+		t.Errorf("expected scores summing to 1, got %v", sum)
+	}
+
+	if _, err := classifier.Classify(images, 0, 1); err == nil {
+		t.Error("expected an error for topK 0")
+	}
+	classifier.SetClassNames([]string{"red", "green"})
+	if _, err := classifier.Classify(images, 1, 1); err == nil {
+		t.Error("expected an error for too few class names")
+	}
+	detector := NewClassifierWithBackend(NewFakeBackend(NClasses, 0, 10), 32)
+	if _, err := detector.Classify(images, 1, 1); err == nil {
+		t.Error("expected an error for a detection model")
+	}
+}
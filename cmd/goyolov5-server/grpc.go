@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"time"
+
+	"github.com/danhilltech/goyolov5"
+	"github.com/danhilltech/goyolov5/detectorpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// detectorService implements the gRPC Detector service over the server's
+// models.
+type detectorService struct {
+	detectorpb.UnimplementedDetectorServer
+	server *server
+}
+
+func (d *detectorService) Detect(ctx context.Context, req *detectorpb.DetectRequest) (*detectorpb.DetectResponse, error) {
+	return d.detect(ctx, req)
+}
+
+// DetectStream answers each request in order before reading the next, so a
+// client bounds its latency by how many frames it sends ahead.
+func (d *detectorService) DetectStream(stream detectorpb.Detector_DetectStreamServer) error {
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		res, err := d.detect(stream.Context(), req)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(res); err != nil {
+			return err
+		}
+	}
+}
+
+func (d *detectorService) detect(ctx context.Context, req *detectorpb.DetectRequest) (*detectorpb.DetectResponse, error) {
+	if !d.server.isReady() {
+		return nil, status.Error(codes.Unavailable, "models are still loading")
+	}
+
+	name, m, err := d.server.model(req.GetModel())
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+
+	confidence, nms := float32(defaultConfidence), float64(defaultNMS)
+	if req.Confidence != nil {
+		confidence = req.GetConfidence()
+	}
+	if req.Nms != nil {
+		nms = float64(req.GetNms())
+	}
+	if confidence < 0 || confidence > 1 || nms < 0 || nms > 1 {
+		return nil, status.Error(codes.InvalidArgument, "thresholds must be in [0, 1]")
+	}
+
+	tensor, err := requestTensor(req, d.server.maxPixels)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if req.GetTimeoutMs() > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(req.GetTimeoutMs())*time.Millisecond)
+		defer cancel()
+	}
+
+	var opts []goyolov5.InferOption
+	if len(req.GetClasses()) > 0 {
+		classes := make([]uint, len(req.GetClasses()))
+		for i, c := range req.GetClasses() {
+			classes[i] = uint(c)
+		}
+		opts = append(opts, goyolov5.WithClasses(classes...))
+	}
+
+	predictions, err := m.detector.Infer(ctx, tensor, confidence, nms, nil, opts...)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+			return nil, status.FromContextError(err).Err()
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &detectorpb.DetectResponse{
+		Model:      name,
+		FrameId:    req.GetFrameId(),
+		Width:      int32(tensor.Rect.Dx()),
+		Height:     int32(tensor.Rect.Dy()),
+		Detections: protoDetections(goyolov5.NewDetections(predictions[0], m.names)),
+	}, nil
+}
+
+func requestTensor(req *detectorpb.DetectRequest, maxPixels int64) (*goyolov5.Tensor, error) {
+	switch img := req.GetImage().(type) {
+	case *detectorpb.DetectRequest_Encoded:
+		return decodeImage(bytes.NewReader(img.Encoded), maxPixels)
+	case *detectorpb.DetectRequest_Raw:
+		width, height := int(img.Raw.GetWidth()), int(img.Raw.GetHeight())
+		if width <= 0 || height <= 0 {
+			return nil, fmt.Errorf("raw image needs a positive size, got %dx%d", width, height)
+		}
+		if int64(width)*int64(height) > maxPixels {
+			return nil, fmt.Errorf("%w: %dx%d is over the %d pixel limit", errTooLarge, width, height, maxPixels)
+		}
+		if len(img.Raw.GetRgb()) != width*height*3 {
+			return nil, fmt.Errorf("raw %dx%d RGB needs %d bytes, got %d", width, height, width*height*3, len(img.Raw.GetRgb()))
+		}
+		tensor := goyolov5.NewTensor(image.Rect(0, 0, width, height))
+		copy(tensor.Pix, img.Raw.GetRgb())
+		return tensor, nil
+	}
+	return nil, errors.New("request has no image")
+}
+
+func protoDetections(detections []goyolov5.Detection) []*detectorpb.Detection {
+	res := make([]*detectorpb.Detection, len(detections))
+	for i, d := range detections {
+		pd := &detectorpb.Detection{
+			Class:           d.Class,
+			ClassIndex:      uint32(d.ClassIndex),
+			Confidence:      float32(d.Confidence),
+			ClassConfidence: float32(d.ClassConfidence),
+			Box:             &detectorpb.Box{X0: int32(d.Box[0]), Y0: int32(d.Box[1]), X1: int32(d.Box[2]), Y1: int32(d.Box[3])},
+		}
+		for _, k := range d.Keypoints {
+			pd.Keypoints = append(pd.Keypoints, &detectorpb.Keypoint{X: float32(k[0]), Y: float32(k[1]), Visibility: float32(k[2])})
+		}
+		for _, c := range d.Classifications {
+			pd.Classifications = append(pd.Classifications, &detectorpb.Classification{Class: c.Class, ClassIndex: uint32(c.ClassIndex), Score: float32(c.Score)})
+		}
+		res[i] = pd
+	}
+	return res
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/danhilltech/goyolov5"
+	"github.com/danhilltech/goyolov5/detectorpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+func newTestClient(t *testing.T, s *server) detectorpb.DetectorClient {
+	lis := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	detectorpb.RegisterDetectorServer(grpcServer, &detectorService{server: s})
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return detectorpb.NewDetectorClient(conn)
+}
+
+func rawRequest(width, height int) *detectorpb.DetectRequest {
+	return &detectorpb.DetectRequest{
+		Image: &detectorpb.DetectRequest_Raw{Raw: &detectorpb.RawImage{Width: int32(width), Height: int32(height), Rgb: make([]byte, width*height*3)}},
+	}
+}
+
+func TestGRPCDetect(t *testing.T) {
+	client := newTestClient(t, newFakeServer(t))
+
+	res, err := client.Detect(context.Background(), &detectorpb.DetectRequest{
+		Image: &detectorpb.DetectRequest_Encoded{Encoded: encodePNG(t, 640, 480)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Model != "fake" || res.Width != 640 || len(res.Detections) != 1 {
+		t.Fatalf("unexpected response %v", res)
+	}
+	d := res.Detections[0]
+	if d.Class != "car" || !proto.Equal(d.Box, &detectorpb.Box{X0: 20, Y0: 20, X1: 120, Y1: 220}) {
+		t.Fatalf("unexpected detection %v", d)
+	}
+
+	// Filtering out the only class detected leaves nothing
+	req := rawRequest(640, 480)
+	req.Classes = []uint32{0}
+	res, err = client.Detect(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Detections) != 0 {
+		t.Fatalf("expected no detections, got %d", len(res.Detections))
+	}
+
+	// A raised threshold does the same
+	req = rawRequest(640, 480)
+	req.Confidence = proto.Float32(0.95)
+	res, err = client.Detect(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Detections) != 0 {
+		t.Fatalf("expected no detections, got %d", len(res.Detections))
+	}
+}
+
+func TestGRPCDetectErrors(t *testing.T) {
+	s := newFakeServer(t)
+	client := newTestClient(t, s)
+
+	unknown := rawRequest(640, 480)
+	unknown.Model = "nope"
+	short := rawRequest(640, 480)
+	short.GetRaw().Rgb = short.GetRaw().Rgb[:10]
+	huge := rawRequest(1, 1)
+	huge.GetRaw().Width, huge.GetRaw().Height = 100000, 100000
+
+	tests := []struct {
+		name string
+		req  *detectorpb.DetectRequest
+		code codes.Code
+	}{
+		{"unknown model", unknown, codes.NotFound},
+		{"no image", &detectorpb.DetectRequest{}, codes.InvalidArgument},
+		{"short raw image", short, codes.InvalidArgument},
+		{"bad image", &detectorpb.DetectRequest{Image: &detectorpb.DetectRequest_Encoded{Encoded: []byte("nope")}}, codes.InvalidArgument},
+		{"too many raw pixels", huge, codes.InvalidArgument},
+		{"too many pixels", &detectorpb.DetectRequest{Image: &detectorpb.DetectRequest_Encoded{Encoded: hugePNG(t)}}, codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		_, err := client.Detect(context.Background(), tt.req)
+		if status.Code(err) != tt.code {
+			t.Errorf("%s: expected %s, got %v", tt.name, tt.code, err)
+		}
+	}
+	if _, err := requestTensor(rawRequest(100, 100), 1000); !errors.Is(err, errTooLarge) {
+		t.Errorf("expected a raw image over the pixel limit rejected, got %v", err)
+	}
+
+	// With every model busy the call deadline expires waiting for one
+	_, m, _ := s.model("")
+	yolov5, err := m.pool.Get(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.pool.Put(yolov5)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.Detect(ctx, rawRequest(640, 480)); status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("expected DeadlineExceeded, got %v", err)
+	}
+}
+
+// failingBackend fails every forward pass, like a broken model.
+type failingBackend struct{}
+
+func (failingBackend) Forward(*goyolov5.Tensor) ([]float32, []int, error) {
+	return nil, nil, errors.New("forward failed")
+}
+
+func TestGRPCDetectBackendError(t *testing.T) {
+	pool, err := goyolov5.NewPool(goyolov5.NewYoloV5WithBackend(failingBackend{}, 320, goyolov5.NClasses, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newServer(1 << 20)
+	if err := s.addModel("broken", pool, goyolov5.COCONames); err != nil {
+		t.Fatal(err)
+	}
+	s.setReady()
+
+	if _, err := newTestClient(t, s).Detect(context.Background(), rawRequest(640, 480)); status.Code(err) != codes.Internal {
+		t.Fatalf("expected Internal for a backend failure, got %v", err)
+	}
+}
+
+func TestGRPCDetectStream(t *testing.T) {
+	client := newTestClient(t, newFakeServer(t))
+
+	stream, err := client.DetectStream(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 5; i++ {
+		req := rawRequest(640, 480)
+		req.FrameId = uint64(i)
+		if err := stream.Send(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 5; i++ {
+		res, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.FrameId != uint64(i) || len(res.Detections) != 1 {
+			t.Fatalf("unexpected response %v", res)
+		}
+	}
+}
+
+func TestGRPCDetectStreamTimeout(t *testing.T) {
+	s := newFakeServer(t)
+	client := newTestClient(t, s)
+
+	_, m, _ := s.model("")
+	yolov5, err := m.pool.Get(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.pool.Put(yolov5)
+
+	stream, err := client.DetectStream(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := rawRequest(640, 480)
+	req.TimeoutMs = 20
+	if err := stream.Send(req); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("expected DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestProtoDetectionsClassifications(t *testing.T) {
+	detections := protoDetections([]goyolov5.Detection{{
+		Class: "car", ClassIndex: 2,
+		Classifications: []goyolov5.Classification{{ClassIndex: 1, Class: "sedan", Score: 0.75}},
+	}})
+	want := []*detectorpb.Classification{{Class: "sedan", ClassIndex: 1, Score: 0.75}}
+	if len(detections) != 1 || len(detections[0].Classifications) != 1 || !proto.Equal(detections[0].Classifications[0], want[0]) {
+		t.Fatalf("expected the classification carried over, got %v", detections)
+	}
+}
@@ -0,0 +1,156 @@
+// Command goyolov5-server serves YOLOv5 models over HTTP.
+//
+//	goyolov5-server -model yolov5s=weights/yolov5s/yolov5s.torchscript.cpu.640.pt -addr :8080
+//	curl --data-binary @people.png -H 'Content-Type: image/png' localhost:8080/v1/detect
+//
+// With -grpc-addr it also serves the detectorpb.Detector gRPC service.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/danhilltech/goyolov5"
+	"github.com/danhilltech/goyolov5/detectorpb"
+	"google.golang.org/grpc"
+)
+
+// modelFlags collects repeated -model name=path flags.
+type modelFlags [][2]string
+
+func (m *modelFlags) String() string {
+	var s []string
+	for _, f := range *m {
+		s = append(s, f[0]+"="+f[1])
+	}
+	return strings.Join(s, ",")
+}
+
+func (m *modelFlags) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return fmt.Errorf("expected name=path, got %q", value)
+	}
+	*m = append(*m, [2]string{parts[0], parts[1]})
+	return nil
+}
+
+func main() {
+	var models modelFlags
+	flag.Var(&models, "model", "model to serve as name=path, repeatable; the first is the default")
+	addr := flag.String("addr", ":8080", "listen address")
+	grpcAddr := flag.String("grpc-addr", "", "gRPC listen address, empty to disable")
+	replicas := flag.Int("replicas", 1, "copies of each model to load, for concurrent requests")
+	maxBatch := flag.Int("max-batch", 1, "batch up to this many concurrent requests per forward pass, for models exported with a batch dimension")
+	maxWait := flag.Duration("max-wait", 5*time.Millisecond, "longest a batch waits for more requests once a model is free")
+	fixedBatch := flag.Int("fixed-batch", 0, "batch size of models exported with a fixed batch dimension, to pad batches to")
+	size := flag.Int("size", 640, "model input size")
+	gpu := flag.Bool("gpu", false, "run on the GPU")
+	half := flag.Bool("half", false, "use half precision weights")
+	maxBody := flag.Int64("max-body", 20<<20, "maximum request body in bytes")
+	maxPixels := flag.Int64("max-pixels", defaultMaxPixels, "maximum width times height of a request image")
+	shutdownTimeout := flag.Duration("shutdown-timeout", 10*time.Second, "time allowed for in-flight requests on shutdown")
+	flag.Parse()
+
+	if len(models) == 0 {
+		fmt.Fprintln(os.Stderr, "at least one -model is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	device := goyolov5.DeviceCPU
+	if *gpu {
+		device = goyolov5.DeviceGPU
+	}
+
+	if *replicas < 1 {
+		fmt.Fprintln(os.Stderr, "-replicas must be at least 1")
+		os.Exit(2)
+	}
+
+	s := newServer(*maxBody)
+	s.maxPixels = *maxPixels
+	s.batch = goyolov5.BatcherConfig{MaxBatch: *maxBatch, MaxWait: *maxWait, FixedBatch: *fixedBatch}
+	httpServer := &http.Server{
+		Addr:              *addr,
+		Handler:           s.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Serve health checks while the models load
+	errc := make(chan error, 2)
+	go func() {
+		log.Printf("listening on %s", *addr)
+		errc <- httpServer.ListenAndServe()
+	}()
+
+	var grpcServer *grpc.Server
+	if *grpcAddr != "" {
+		lis, err := net.Listen("tcp", *grpcAddr)
+		if err != nil {
+			log.Fatal(err)
+		}
+		grpcServer = grpc.NewServer()
+		detectorpb.RegisterDetectorServer(grpcServer, &detectorService{server: s})
+		go func() {
+			log.Printf("gRPC listening on %s", *grpcAddr)
+			errc <- grpcServer.Serve(lis)
+		}()
+	}
+
+	for _, m := range models {
+		log.Printf("loading %s from %s", m[0], m[1])
+		yolov5s := make([]*goyolov5.YoloV5, *replicas)
+		for i := range yolov5s {
+			yolov5, err := goyolov5.NewYoloV5(m[1], device, *size, *half)
+			if err != nil {
+				log.Fatalf("loading %s: %v", m[0], err)
+			}
+			yolov5s[i] = yolov5
+		}
+		pool, err := goyolov5.NewPool(yolov5s...)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := s.addModel(m[0], pool, yolov5s[0].Info().ClassNames); err != nil {
+			log.Fatal(err)
+		}
+	}
+	s.setReady()
+	log.Printf("ready")
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case err := <-errc:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	case sig := <-stop:
+		log.Printf("%s, shutting down", sig)
+		ctx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+		defer cancel()
+		if grpcServer != nil {
+			go func() {
+				<-ctx.Done()
+				grpcServer.Stop()
+			}()
+			grpcServer.GracefulStop()
+		}
+		if err := httpServer.Shutdown(ctx); err != nil {
+			log.Fatalf("shutdown: %v", err)
+		}
+		s.close()
+	}
+}
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/danhilltech/goyolov5"
+)
+
+const (
+	defaultConfidence = 0.5
+	defaultNMS        = 0.4
+	defaultMaxPixels  = 50_000_000
+)
+
+// errTooLarge marks images over the server's body or pixel limits.
+var errTooLarge = errors.New("image too large")
+
+// detector runs inference for a model: a goyolov5.Pool, or a goyolov5.Batcher
+// over one.
+type detector interface {
+	Infer(ctx context.Context, inputTensorRaw *goyolov5.Tensor, confidenceThreshold float32, nmsThreshold float64, annotateTensor *goyolov5.Tensor, opts ...goyolov5.InferOption) ([][]goyolov5.Prediction, error)
+}
+
+// model is a pool of replicas of one set of weights.
+type model struct {
+	pool     *goyolov5.Pool
+	batcher  *goyolov5.Batcher
+	detector detector
+	names    []string
+}
+
+type server struct {
+	mu           sync.RWMutex
+	models       map[string]*model
+	defaultModel string
+	maxBody      int64
+	maxPixels    int64
+	batch        goyolov5.BatcherConfig
+	ready        int32
+}
+
+// countingBody counts the bytes read from a request body. Under
+// http.MaxBytesReader it reads past the limit only when the limit was hit.
+type countingBody struct {
+	io.ReadCloser
+	n int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
+
+type detectResponse struct {
+	Model      string               `json:"model"`
+	Width      int                  `json:"width"`
+	Height     int                  `json:"height"`
+	Detections []goyolov5.Detection `json:"detections"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func newServer(maxBody int64) *server {
+	return &server{
+		models:    map[string]*model{},
+		maxBody:   maxBody,
+		maxPixels: defaultMaxPixels,
+	}
+}
+
+// addModel registers a loaded model. The first model added is the default.
+// With a batch size above 1, or a fixed batch size, requests to it are
+// batched.
+func (s *server) addModel(name string, pool *goyolov5.Pool, names []string) error {
+	m := &model{pool: pool, detector: pool, names: names}
+	if s.batch.MaxBatch > 1 || s.batch.FixedBatch > 0 {
+		batcher, err := goyolov5.NewBatcher(pool, s.batch)
+		if err != nil {
+			return err
+		}
+		m.batcher = batcher
+		m.detector = batcher
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.defaultModel == "" {
+		s.defaultModel = name
+	}
+	s.models[name] = m
+	return nil
+}
+
+// close stops the models' batchers, logging how well requests were batched.
+func (s *server) close() {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for name, m := range s.models {
+		if m.batcher == nil {
+			continue
+		}
+		m.batcher.Close()
+		stats := m.batcher.Stats()
+		log.Printf("%s: %d requests in %d batches, mean batch size %.2f", name, stats.Requests, stats.Batches, stats.MeanBatchSize())
+	}
+}
+
+// model looks up a model by name, or the default model for an empty name.
+func (s *server) model(name string) (string, *model, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if name == "" {
+		name = s.defaultModel
+	}
+	m, ok := s.models[name]
+	if !ok {
+		return name, nil, fmt.Errorf("unknown model %q", name)
+	}
+	return name, m, nil
+}
+
+func (s *server) setReady() {
+	atomic.StoreInt32(&s.ready, 1)
+}
+
+func (s *server) isReady() bool {
+	return atomic.LoadInt32(&s.ready) == 1
+}
+
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", s.handleHealth)
+	mux.HandleFunc("/readyz", s.handleReady)
+	mux.HandleFunc("/v1/detect", s.handleDetect)
+	return mux
+}
+
+func (s *server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	io.WriteString(w, "ok\n")
+}
+
+func (s *server) handleReady(w http.ResponseWriter, r *http.Request) {
+	if !s.isReady() {
+		http.Error(w, "loading models", http.StatusServiceUnavailable)
+		return
+	}
+	io.WriteString(w, "ready\n")
+}
+
+// handleDetect runs a model over an uploaded image. The image is the request
+// body, either a JPEG or PNG, a multipart form with an "image" file, or raw
+// RGB bytes (application/octet-stream) with width and height query parameters.
+// Optional query parameters are model, confidence, nms and annotate, which
+// returns the annotated image as png or jpeg instead of JSON.
+func (s *server) handleDetect(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+	if !s.isReady() {
+		writeError(w, http.StatusServiceUnavailable, errors.New("models are still loading"))
+		return
+	}
+
+	query := r.URL.Query()
+
+	name, m, err := s.model(query.Get("model"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	confidence, err := floatParam(query.Get("confidence"), defaultConfidence)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	nms, err := floatParam(query.Get("nms"), defaultNMS)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	annotate := query.Get("annotate")
+	if annotate != "" && annotate != "png" && annotate != "jpeg" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("annotate must be png or jpeg, got %q", annotate))
+		return
+	}
+
+	body := &countingBody{ReadCloser: r.Body}
+	r.Body = http.MaxBytesReader(w, body, s.maxBody)
+	tensor, err := s.readTensor(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, errTooLarge) || body.n > s.maxBody {
+			status = http.StatusRequestEntityTooLarge
+		}
+		writeError(w, status, err)
+		return
+	}
+
+	var annotated *goyolov5.Tensor
+	if annotate != "" {
+		annotated = goyolov5.NewTensorFromImage(tensor)
+	}
+
+	predictions, err := m.detector.Infer(r.Context(), tensor, float32(confidence), nms, annotated, goyolov5.WithAnnotator(goyolov5.NewAnnotator(m.names)))
+	if err != nil {
+		status := http.StatusUnprocessableEntity
+		if r.Context().Err() != nil {
+			status = http.StatusServiceUnavailable
+		}
+		writeError(w, status, err)
+		return
+	}
+
+	switch annotate {
+	case "png":
+		w.Header().Set("Content-Type", "image/png")
+		if err := png.Encode(w, annotated); err != nil {
+			log.Printf("encoding png: %v", err)
+		}
+		return
+	case "jpeg":
+		w.Header().Set("Content-Type", "image/jpeg")
+		if err := jpeg.Encode(w, annotated, nil); err != nil {
+			log.Printf("encoding jpeg: %v", err)
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, detectResponse{
+		Model:      name,
+		Width:      tensor.Rect.Dx(),
+		Height:     tensor.Rect.Dy(),
+		Detections: goyolov5.NewDetections(predictions[0], m.names),
+	})
+}
+
+func (s *server) readTensor(r *http.Request) (*goyolov5.Tensor, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid content type: %w", err)
+	}
+
+	switch mediaType {
+	case "multipart/form-data":
+		file, _, err := r.FormFile("image")
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		return decodeImage(file, s.maxPixels)
+	case "image/jpeg", "image/png":
+		// Read the whole body first so a size limit error is not hidden by
+		// the decoder.
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		return decodeImage(bytes.NewReader(data), s.maxPixels)
+	case "application/octet-stream":
+		query := r.URL.Query()
+		width, err := strconv.Atoi(query.Get("width"))
+		if err != nil || width <= 0 {
+			return nil, errors.New("raw RGB needs a positive width")
+		}
+		height, err := strconv.Atoi(query.Get("height"))
+		if err != nil || height <= 0 {
+			return nil, errors.New("raw RGB needs a positive height")
+		}
+		// Check the size before allocating, without overflowing 3*width*height
+		if width > int(s.maxBody/3)/height || int64(width) > s.maxPixels/int64(height) {
+			return nil, fmt.Errorf("%w: %dx%d RGB is over the %d byte body or %d pixel limit", errTooLarge, width, height, s.maxBody, s.maxPixels)
+		}
+		if r.ContentLength >= 0 && r.ContentLength < int64(3*width*height) {
+			return nil, fmt.Errorf("raw %dx%d RGB needs %d bytes, got %d", width, height, 3*width*height, r.ContentLength)
+		}
+		tensor := goyolov5.NewTensor(image.Rect(0, 0, width, height))
+		if _, err := io.ReadFull(r.Body, tensor.Pix); err != nil {
+			return nil, fmt.Errorf("reading %dx%d RGB: %w", width, height, err)
+		}
+		return tensor, nil
+	}
+	return nil, fmt.Errorf("unsupported content type %q", mediaType)
+}
+
+// decodeImage decodes a JPEG or PNG, checking the size in its header against
+// maxPixels before decoding.
+func decodeImage(r io.Reader, maxPixels int64) (*goyolov5.Tensor, error) {
+	var header bytes.Buffer
+	config, _, err := image.DecodeConfig(io.TeeReader(r, &header))
+	if err != nil {
+		return nil, err
+	}
+	if int64(config.Width)*int64(config.Height) > maxPixels {
+		return nil, fmt.Errorf("%w: %dx%d is over the %d pixel limit", errTooLarge, config.Width, config.Height, maxPixels)
+	}
+
+	img, _, err := image.Decode(io.MultiReader(&header, r))
+	if err != nil {
+		return nil, err
+	}
+	return goyolov5.NewTensorFromImage(img), nil
+}
+
+func floatParam(value string, def float64) (float64, error) {
+	if value == "" {
+		return def, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || f < 0 || f > 1 {
+		return 0, fmt.Errorf("threshold must be in [0, 1], got %q", value)
+	}
+	return f, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("encoding response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
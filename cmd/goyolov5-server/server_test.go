@@ -0,0 +1,274 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/danhilltech/goyolov5"
+)
+
+// newFakeServer serves one FakeBackend model, named fake, that always detects
+// a car.
+func newFakeServer(t *testing.T) *server {
+	backend := goyolov5.NewFakeBackend(goyolov5.NClasses, 0, 10,
+		goyolov5.FakeDetection{Rect: image.Rect(10, 10, 60, 110), Confidence: 0.9, ClassIndex: 2, ClassConfidence: 0.8},
+	)
+	pool, err := goyolov5.NewPool(goyolov5.NewYoloV5WithBackend(backend, 320, goyolov5.NClasses, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newServer(1 << 20)
+	if err := s.addModel("fake", pool, goyolov5.COCONames); err != nil {
+		t.Fatal(err)
+	}
+	s.setReady()
+	return s
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	ts := httptest.NewServer(newFakeServer(t).handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func encodePNG(t *testing.T, w, h int) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// hugePNG returns a small PNG whose header declares it 60000x60000.
+func hugePNG(t *testing.T) []byte {
+	data := encodePNG(t, 1, 1)
+	// IHDR's width and height follow the signature, chunk length and type,
+	// and its CRC covers the type and data
+	binary.BigEndian.PutUint32(data[16:], 60000)
+	binary.BigEndian.PutUint32(data[20:], 60000)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+	return data
+}
+
+func decodeDetections(t *testing.T, resp *http.Response) detectResponse {
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var e errorResponse
+		json.NewDecoder(resp.Body).Decode(&e)
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, e.Error)
+	}
+	var res detectResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func TestDetectPNG(t *testing.T) {
+	ts := newTestServer(t)
+
+	resp, err := http.Post(ts.URL+"/v1/detect", "image/png", bytes.NewReader(encodePNG(t, 640, 480)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := decodeDetections(t, resp)
+
+	if res.Model != "fake" || res.Width != 640 || res.Height != 480 {
+		t.Fatalf("unexpected response %+v", res)
+	}
+	if len(res.Detections) != 1 {
+		t.Fatalf("expected 1 detection, got %d", len(res.Detections))
+	}
+	d := res.Detections[0]
+	if d.Class != "car" || d.Box != [4]int{20, 20, 120, 220} {
+		t.Fatalf("unexpected detection %+v", d)
+	}
+}
+
+func TestDetectMultipartAndRaw(t *testing.T) {
+	ts := newTestServer(t)
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("image", "frame.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fw.Write(encodePNG(t, 640, 480))
+	mw.Close()
+
+	resp, err := http.Post(ts.URL+"/v1/detect?model=fake", mw.FormDataContentType(), &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := decodeDetections(t, resp); len(res.Detections) != 1 {
+		t.Fatalf("expected 1 detection, got %d", len(res.Detections))
+	}
+
+	raw := make([]byte, 640*480*3)
+	resp, err = http.Post(ts.URL+"/v1/detect?width=640&height=480", "application/octet-stream", bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := decodeDetections(t, resp); res.Width != 640 || len(res.Detections) != 1 {
+		t.Fatalf("unexpected response %+v", res)
+	}
+}
+
+func TestDetectAnnotated(t *testing.T) {
+	ts := newTestServer(t)
+
+	resp, err := http.Post(ts.URL+"/v1/detect?annotate=png", "image/png", bytes.NewReader(encodePNG(t, 640, 480)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.Header.Get("Content-Type") != "image/png" {
+		t.Fatalf("expected a png, got %q", resp.Header.Get("Content-Type"))
+	}
+	img, err := png.Decode(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The car box, in the car colour of the default palette
+	if got := color.RGBAModel.Convert(img.At(20, 20)); got != goyolov5.DefaultPalette[2] {
+		t.Fatalf("expected the box drawn at (20,20), got %v", got)
+	}
+}
+
+func TestDetectErrors(t *testing.T) {
+	ts := newTestServer(t)
+
+	var multipartBody bytes.Buffer
+	mw := multipart.NewWriter(&multipartBody)
+	fw, err := mw.CreateFormFile("image", "frame.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fw.Write(make([]byte, 2<<20))
+	mw.Close()
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		contentType string
+		body        []byte
+		status      int
+	}{
+		{"method", http.MethodGet, "/v1/detect", "", nil, http.StatusMethodNotAllowed},
+		{"unknown model", http.MethodPost, "/v1/detect?model=nope", "image/png", encodePNG(t, 640, 480), http.StatusNotFound},
+		{"bad threshold", http.MethodPost, "/v1/detect?confidence=2", "image/png", encodePNG(t, 640, 480), http.StatusBadRequest},
+		{"not an image", http.MethodPost, "/v1/detect", "image/png", []byte("nope"), http.StatusBadRequest},
+		{"content type", http.MethodPost, "/v1/detect", "text/plain", []byte("nope"), http.StatusBadRequest},
+		{"raw size", http.MethodPost, "/v1/detect?width=640&height=480", "application/octet-stream", make([]byte, 10), http.StatusBadRequest},
+		{"too large", http.MethodPost, "/v1/detect", "image/png", make([]byte, 2<<20), http.StatusRequestEntityTooLarge},
+		{"multipart too large", http.MethodPost, "/v1/detect", mw.FormDataContentType(), multipartBody.Bytes(), http.StatusRequestEntityTooLarge},
+		{"too many pixels", http.MethodPost, "/v1/detect", "image/png", hugePNG(t), http.StatusRequestEntityTooLarge},
+		{"raw too large", http.MethodPost, "/v1/detect?width=100000&height=100000", "application/octet-stream", make([]byte, 10), http.StatusRequestEntityTooLarge},
+		{"raw overflow", http.MethodPost, "/v1/detect?width=4294967296&height=4294967296", "application/octet-stream", make([]byte, 10), http.StatusRequestEntityTooLarge},
+		{"too small to letterbox", http.MethodPost, "/v1/detect", "image/png", encodePNG(t, 100, 100), http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		req, err := http.NewRequest(tt.method, ts.URL+tt.path, bytes.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tt.contentType != "" {
+			req.Header.Set("Content-Type", tt.contentType)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.status, resp.StatusCode)
+		}
+	}
+}
+
+func TestHealthAndReadiness(t *testing.T) {
+	s := newServer(1 << 20)
+	ts := httptest.NewServer(s.handler())
+	defer ts.Close()
+
+	for path, status := range map[string]int{"/healthz": http.StatusOK, "/readyz": http.StatusServiceUnavailable} {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != status {
+			t.Fatalf("%s: expected %d, got %d", path, status, resp.StatusCode)
+		}
+	}
+
+	s.setReady()
+	resp, err := http.Get(ts.URL + "/readyz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected ready, got %d", resp.StatusCode)
+	}
+}
+
+func TestDetectBatched(t *testing.T) {
+	backend := goyolov5.NewFakeBackend(goyolov5.NClasses, 0, 10,
+		goyolov5.FakeDetection{Rect: image.Rect(10, 10, 60, 110), Confidence: 0.9, ClassIndex: 2, ClassConfidence: 0.8},
+	)
+	pool, err := goyolov5.NewPool(goyolov5.NewYoloV5WithBackend(backend, 320, goyolov5.NClasses, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newServer(1 << 20)
+	s.batch = goyolov5.BatcherConfig{MaxBatch: 4, MaxWait: 20 * time.Millisecond}
+	if err := s.addModel("fake", pool, goyolov5.COCONames); err != nil {
+		t.Fatal(err)
+	}
+	s.setReady()
+	ts := httptest.NewServer(s.handler())
+	defer ts.Close()
+
+	body := encodePNG(t, 640, 480)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := http.Post(ts.URL+"/v1/detect", "image/png", bytes.NewReader(body))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			var res detectResponse
+			json.NewDecoder(resp.Body).Decode(&res)
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK || len(res.Detections) != 1 {
+				t.Errorf("unexpected response %d %+v", resp.StatusCode, res)
+			}
+		}()
+	}
+	wg.Wait()
+
+	s.close()
+	_, m, _ := s.model("")
+	if stats := m.batcher.Stats(); stats.Requests != 8 || stats.MeanBatchSize() < 1 {
+		t.Fatalf("unexpected batch stats %+v", stats)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/danhilltech/goyolov5"
+)
+
+type benchOptions struct {
+	runs       int
+	warmup     int
+	confidence float64
+	nms        float64
+}
+
+func benchCommand(args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	var model modelOptions
+	model.register(fs)
+	var opts benchOptions
+	imagePath := fs.String("image", "", "image to run on, default a blank 1280x720 frame")
+	fs.IntVar(&opts.runs, "n", 100, "timed runs")
+	fs.IntVar(&opts.warmup, "warmup", 10, "untimed runs before timing")
+	fs.Float64Var(&opts.confidence, "conf", 0.5, "confidence threshold")
+	fs.Float64Var(&opts.nms, "nms", 0.4, "NMS IoU threshold")
+	fs.Parse(args)
+
+	if opts.runs < 1 {
+		return fmt.Errorf("-n must be at least 1")
+	}
+
+	tensor := goyolov5.NewTensor(image.Rect(0, 0, 1280, 720))
+	if *imagePath != "" {
+		var err error
+		if tensor, err = readImage(*imagePath); err != nil {
+			return err
+		}
+	}
+
+	yolov5, err := model.load()
+	if err != nil {
+		return err
+	}
+	return bench(os.Stdout, yolov5, tensor, opts)
+}
+
+func bench(w io.Writer, yolov5 *goyolov5.YoloV5, tensor *goyolov5.Tensor, opts benchOptions) error {
+	for i := 0; i < opts.warmup; i++ {
+		if _, err := yolov5.Infer(tensor, float32(opts.confidence), opts.nms, nil); err != nil {
+			return err
+		}
+	}
+
+	stages := []string{"preprocess", "forward", "postprocess", "total"}
+	durations := make([][]time.Duration, len(stages))
+	for i := 0; i < opts.runs; i++ {
+		var timings goyolov5.Timings
+		start := time.Now()
+		if _, err := yolov5.Infer(tensor, float32(opts.confidence), opts.nms, nil, goyolov5.WithTimings(&timings)); err != nil {
+			return err
+		}
+		total := time.Since(start)
+
+		for s, d := range []time.Duration{timings.Preprocess, timings.Forward, timings.Postprocess, total} {
+			durations[s] = append(durations[s], d)
+		}
+	}
+
+	fmt.Fprintf(w, "%s, %dx%d input, %d runs after %d warmup\n", yolov5.Info().Name, tensor.Rect.Dx(), tensor.Rect.Dy(), opts.runs, opts.warmup)
+	fmt.Fprintf(w, "%-12s %10s %10s %10s\n", "stage", "mean", "p50", "p95")
+	for s, stage := range stages {
+		mean, p50, p95 := summarize(durations[s])
+		fmt.Fprintf(w, "%-12s %10s %10s %10s\n", stage, round(mean), round(p50), round(p95))
+	}
+	return nil
+}
+
+// summarize returns the mean, median and 95th percentile of durations, using
+// the nearest rank.
+func summarize(durations []time.Duration) (mean, p50, p95 time.Duration) {
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var sum time.Duration
+	for _, d := range sorted {
+		sum += d
+	}
+	return sum / time.Duration(len(sorted)), percentile(sorted, 50), percentile(sorted, 95)
+}
+
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func round(d time.Duration) time.Duration {
+	return d.Round(time.Microsecond)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danhilltech/goyolov5"
+)
+
+func TestSummarize(t *testing.T) {
+	var durations []time.Duration
+	for i := 100; i >= 1; i-- {
+		durations = append(durations, time.Duration(i)*time.Millisecond)
+	}
+
+	mean, p50, p95 := summarize(durations)
+	if mean != 50500*time.Microsecond || p50 != 50*time.Millisecond || p95 != 95*time.Millisecond {
+		t.Fatalf("unexpected summary %v %v %v", mean, p50, p95)
+	}
+	if durations[0] != 100*time.Millisecond {
+		t.Fatal("expected the input left unsorted")
+	}
+
+	if _, p50, p95 := summarize([]time.Duration{time.Second}); p50 != time.Second || p95 != time.Second {
+		t.Fatalf("unexpected single run summary %v %v", p50, p95)
+	}
+}
+
+func TestBenchAndInfo(t *testing.T) {
+	yolov5 := newFakeYoloV5()
+
+	var buf bytes.Buffer
+	if err := bench(&buf, yolov5, goyolov5.NewTensor(image.Rect(0, 0, 640, 480)), benchOptions{runs: 5, warmup: 1, confidence: 0.5, nms: 0.4}); err != nil {
+		t.Fatal(err)
+	}
+	for _, stage := range []string{"preprocess", "forward", "postprocess", "total"} {
+		if !strings.Contains(buf.String(), stage) {
+			t.Fatalf("expected %s timings in\n%s", stage, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := printInfo(&buf, yolov5); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"output shape: [1 10 85]", "stride:       unknown", "classes:      80", " 79 toothbrush"} {
+		if !strings.Contains(buf.String(), s) {
+			t.Fatalf("expected %q in\n%s", s, buf.String())
+		}
+	}
+}
@@ -0,0 +1,371 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/danhilltech/goyolov5"
+)
+
+type detectOptions struct {
+	out        string
+	confidence float64
+	nms        float64
+	labels     string
+	annotate   bool
+	thickness  int
+	augment    bool
+	classes    []uint
+	redactor   *goyolov5.Redactor
+	cropSize   int
+	// classifier, if set, classifies the predictions of classifierClasses,
+	// classifierBatch crops per forward pass.
+	classifier        *goyolov5.Classifier
+	classifierClasses []uint
+	classifierBatch   int
+}
+
+func detectCommand(args []string) error {
+	fs := flag.NewFlagSet("detect", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: goyolov5 detect -model path [flags] image-or-directory...")
+		fs.PrintDefaults()
+	}
+	var model modelOptions
+	model.register(fs)
+	var opts detectOptions
+	var classes, redact, redactClasses string
+	var redactMargin float64
+	var classifier, classifierClasses string
+	var classifierSize int
+	fs.StringVar(&opts.out, "out", "detections", "output directory")
+	fs.Float64Var(&opts.confidence, "conf", 0.5, "confidence threshold")
+	fs.Float64Var(&opts.nms, "nms", 0.4, "NMS IoU threshold")
+	fs.StringVar(&opts.labels, "labels", "json", "label format: json, yolo or none")
+	fs.BoolVar(&opts.annotate, "annotate", true, "write annotated images")
+	fs.IntVar(&opts.thickness, "thickness", 0, "annotation line width, default scaled to the image")
+	fs.BoolVar(&opts.augment, "augment", false, "use test-time augmentation")
+	fs.StringVar(&classes, "classes", "", "comma separated class indices to keep, default all")
+	fs.IntVar(&opts.cropSize, "crops", 0, "also write square crops of each detection at this size, 0 to skip")
+	fs.StringVar(&classifier, "classifier", "", "TorchScript (.pt) or ONNX (.onnx) classifier to run on each detection")
+	fs.IntVar(&classifierSize, "classifier-size", 224, "classifier input size")
+	fs.StringVar(&classifierClasses, "classifier-classes", "", "comma separated class indices to classify, default all")
+	fs.IntVar(&opts.classifierBatch, "classifier-batch", 8, "crops per classifier forward pass; above 1 needs a classifier exported with a dynamic batch dimension")
+	fs.StringVar(&redact, "redact", "", "redact detections in annotated images: blur, pixelate or fill")
+	fs.StringVar(&redactClasses, "redact-classes", "", "comma separated class indices to redact, default all")
+	fs.Float64Var(&redactMargin, "redact-margin", 0.1, "fraction of each box's size to grow redactions by")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("no images given")
+	}
+	switch opts.labels {
+	case "json", "yolo", "none":
+	default:
+		return fmt.Errorf("unknown label format %q", opts.labels)
+	}
+	var err error
+	if opts.classes, err = parseClasses(classes); err != nil {
+		return err
+	}
+	if redact != "" {
+		opts.redactor = &goyolov5.Redactor{Margin: redactMargin}
+		if opts.redactor.Style, err = parseRedactStyle(redact); err != nil {
+			return err
+		}
+		if opts.redactor.Classes, err = parseClasses(redactClasses); err != nil {
+			return err
+		}
+	}
+
+	if classifier != "" {
+		if opts.classifierBatch < 1 {
+			return fmt.Errorf("-classifier-batch must be at least 1, got %d", opts.classifierBatch)
+		}
+		if opts.classifierClasses, err = parseClasses(classifierClasses); err != nil {
+			return err
+		}
+		device := goyolov5.DeviceCPU
+		if model.gpu {
+			device = goyolov5.DeviceGPU
+		}
+		if strings.EqualFold(filepath.Ext(classifier), ".onnx") {
+			opts.classifier, err = goyolov5.NewClassifierONNX(classifier, device, classifierSize)
+		} else {
+			opts.classifier, err = goyolov5.NewClassifier(classifier, device, classifierSize, model.half)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	yolov5, err := model.load()
+	if err != nil {
+		return err
+	}
+	return detect(yolov5, fs.Args(), opts)
+}
+
+func parseRedactStyle(value string) (goyolov5.RedactStyle, error) {
+	for _, style := range []goyolov5.RedactStyle{goyolov5.RedactBlur, goyolov5.RedactPixelate, goyolov5.RedactFill} {
+		if value == style.String() {
+			return style, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown redaction %q", value)
+}
+
+func parseClasses(value string) ([]uint, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var classes []uint
+	for _, s := range strings.Split(value, ",") {
+		c, err := strconv.ParseUint(strings.TrimSpace(s), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid class index %q", s)
+		}
+		classes = append(classes, uint(c))
+	}
+	return classes, nil
+}
+
+// imageInput is an image to run on and where its outputs go, relative to the
+// output directory.
+type imageInput struct {
+	path string
+	rel  string
+}
+
+// listImages expands directories, recursively, into the JPEG and PNG files
+// they contain. Outputs keep their path relative to the directory given.
+func listImages(paths []string) ([]imageInput, error) {
+	var inputs []imageInput
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			inputs = append(inputs, imageInput{path: path, rel: filepath.Base(path)})
+			continue
+		}
+		err = filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || !isImage(p) {
+				return nil
+			}
+			rel, err := filepath.Rel(path, p)
+			if err != nil {
+				return err
+			}
+			inputs = append(inputs, imageInput{path: p, rel: rel})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return inputs, nil
+}
+
+func isImage(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jpg", ".jpeg", ".png":
+		return true
+	}
+	return false
+}
+
+func detect(yolov5 *goyolov5.YoloV5, paths []string, opts detectOptions) error {
+	inputs, err := listImages(paths)
+	if err != nil {
+		return err
+	}
+	names := yolov5.Info().ClassNames
+
+	annotator := &goyolov5.Annotator{Names: names, Thickness: opts.thickness}
+	var inferOpts []goyolov5.InferOption
+	if opts.redactor == nil {
+		inferOpts = append(inferOpts, goyolov5.WithAnnotator(annotator))
+	}
+	if opts.augment {
+		inferOpts = append(inferOpts, goyolov5.WithAugment())
+	}
+	if opts.classes != nil {
+		inferOpts = append(inferOpts, goyolov5.WithClasses(opts.classes...))
+	}
+	infer := yolov5.Infer
+	if opts.classifier != nil {
+		cascade, err := goyolov5.NewCascade(yolov5, opts.classifier, goyolov5.CascadeOptions{Classes: opts.classifierClasses, BatchSize: opts.classifierBatch, Margin: 0.1})
+		if err != nil {
+			return err
+		}
+		infer = cascade.Infer
+	}
+
+	for _, input := range inputs {
+		tensor, err := readImage(input.path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", input.path, err)
+		}
+
+		var annotated *goyolov5.Tensor
+		if opts.annotate {
+			annotated = goyolov5.NewTensorFromImage(tensor)
+		}
+
+		// Redaction goes under the annotations, so Infer leaves them to us
+		inferAnnotated := annotated
+		if opts.redactor != nil {
+			inferAnnotated = nil
+		}
+		predictions, err := infer(tensor, float32(opts.confidence), opts.nms, inferAnnotated, inferOpts...)
+		if err != nil {
+			return fmt.Errorf("%s: %w", input.path, err)
+		}
+		if annotated != nil && opts.redactor != nil {
+			opts.redactor.Redact(annotated, predictions[0])
+			annotator.Annotate(annotated, predictions[0])
+		}
+
+		out := filepath.Join(opts.out, input.rel)
+		if err := os.MkdirAll(filepath.Dir(out), 0755); err != nil {
+			return err
+		}
+		stem := strings.TrimSuffix(out, filepath.Ext(out))
+
+		if annotated != nil {
+			if err := writeImage(out, annotated); err != nil {
+				return err
+			}
+		}
+		if opts.cropSize > 0 {
+			if err := writeCrops(stem+"_crops", tensor, predictions[0], names, opts.cropSize); err != nil {
+				return err
+			}
+		}
+		switch opts.labels {
+		case "json":
+			err = writeJSONLabels(stem+".json", input.path, tensor.Rect, goyolov5.NewDetections(predictions[0], names))
+		case "yolo":
+			err = writeYOLOLabels(stem+".txt", tensor.Rect, predictions[0])
+		}
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%s: %d detections\n", input.path, len(predictions[0]))
+	}
+	return nil
+}
+
+func readImage(path string) (*goyolov5.Tensor, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, err
+	}
+	return goyolov5.NewTensorFromImage(img), nil
+}
+
+// writeCrops writes a square crop of each prediction to dir, named by its
+// index and class, with a little context around each box.
+func writeCrops(dir string, tensor *goyolov5.Tensor, predictions []goyolov5.Prediction, names []string, size int) error {
+	if len(predictions) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	crops := goyolov5.CropPredictions(tensor, predictions, goyolov5.CropOptions{Size: size, Margin: 0.1})
+	for i, crop := range crops {
+		if crop == nil {
+			continue
+		}
+		class := strconv.Itoa(int(predictions[i].ClassIndex))
+		if int(predictions[i].ClassIndex) < len(names) {
+			class = fileSafe(names[predictions[i].ClassIndex])
+		}
+		if err := writeImage(filepath.Join(dir, fmt.Sprintf("%03d_%s.jpg", i, class)), crop); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fileSafe replaces every rune of name but letters, digits, '-' and '_' with
+// '_', so class names from a model file cannot leave the crop directory.
+func fileSafe(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// writeImage encodes img as a JPEG or PNG, by the extension of path.
+func writeImage(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jpg", ".jpeg":
+		err = jpeg.Encode(f, img, &jpeg.Options{Quality: 90})
+	default:
+		err = png.Encode(f, img)
+	}
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+type imageLabels struct {
+	Image      string               `json:"image"`
+	Width      int                  `json:"width"`
+	Height     int                  `json:"height"`
+	Detections []goyolov5.Detection `json:"detections"`
+}
+
+func writeJSONLabels(path, image string, rect image.Rectangle, detections []goyolov5.Detection) error {
+	data, err := json.MarshalIndent(imageLabels{Image: image, Width: rect.Dx(), Height: rect.Dy(), Detections: detections}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+func writeYOLOLabels(path string, rect image.Rectangle, predictions []goyolov5.Prediction) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := goyolov5.WriteYOLOLabels(f, predictions, rect.Dx(), rect.Dy(), true); err != nil {
+		return err
+	}
+	return f.Close()
+}
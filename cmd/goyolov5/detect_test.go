@@ -0,0 +1,231 @@
+package main
+
+import (
+	"encoding/json"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/danhilltech/goyolov5"
+)
+
+func newFakeYoloV5() *goyolov5.YoloV5 {
+	backend := goyolov5.NewFakeBackend(goyolov5.NClasses, 0, 10,
+		goyolov5.FakeDetection{Rect: image.Rect(10, 10, 60, 110), Confidence: 0.9, ClassIndex: 2, ClassConfidence: 0.8},
+		goyolov5.FakeDetection{Rect: image.Rect(100, 100, 150, 150), Confidence: 0.8, ClassIndex: 0, ClassConfidence: 0.8},
+	)
+	return goyolov5.NewYoloV5WithBackend(backend, 320, goyolov5.NClasses, 0)
+}
+
+func writeTestImages(t *testing.T, dir string, names ...string) {
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := writeImage(path, goyolov5.NewTensor(image.Rect(0, 0, 640, 480))); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	in, out := t.TempDir(), t.TempDir()
+	writeTestImages(t, in, "a.png", "sub/b.jpg")
+	if err := os.WriteFile(filepath.Join(in, "notes.txt"), []byte("skipped"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := detect(newFakeYoloV5(), []string{in}, detectOptions{out: out, confidence: 0.5, nms: 0.4, labels: "json", annotate: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"a.png", "a.json", "sub/b.jpg", "sub/b.json"} {
+		if _, err := os.Stat(filepath.Join(out, name)); err != nil {
+			t.Fatalf("expected output %s: %v", name, err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(out, "a.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var labels imageLabels
+	if err := json.Unmarshal(data, &labels); err != nil {
+		t.Fatal(err)
+	}
+	if labels.Width != 640 || len(labels.Detections) != 2 || labels.Detections[0].Class != "person" {
+		t.Fatalf("unexpected labels %+v", labels)
+	}
+}
+
+func TestDetectYOLOLabels(t *testing.T) {
+	in, out := t.TempDir(), t.TempDir()
+	writeTestImages(t, in, "a.png")
+
+	err := detect(newFakeYoloV5(), []string{filepath.Join(in, "a.png")}, detectOptions{out: out, confidence: 0.5, nms: 0.4, labels: "yolo", classes: []uint{2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(out, "a.png")); !os.IsNotExist(err) {
+		t.Fatal("expected no annotated image")
+	}
+	data, err := os.ReadFile(filepath.Join(out, "a.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1 || !strings.HasPrefix(lines[0], "2 ") {
+		t.Fatalf("expected one class 2 label, got %q", data)
+	}
+}
+
+func TestDetectRedact(t *testing.T) {
+	in, out := t.TempDir(), t.TempDir()
+	white := goyolov5.NewTensor(image.Rect(0, 0, 640, 480))
+	white.FillRect(white.Rect, color.White)
+	if err := writeImage(filepath.Join(in, "a.png"), white); err != nil {
+		t.Fatal(err)
+	}
+
+	redactor := &goyolov5.Redactor{Style: goyolov5.RedactFill, Classes: []uint{0}}
+	err := detect(newFakeYoloV5(), []string{in}, detectOptions{out: out, confidence: 0.5, nms: 0.4, labels: "none", annotate: true, thickness: 2, redactor: redactor})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := readImage(filepath.Join(out, "a.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The person is filled, with its box drawn on top, and the car is not
+	if c := img.RGBAAt(250, 250); c != (color.RGBA{0, 0, 0, 255}) {
+		t.Errorf("expected the person redacted, got %v", c)
+	}
+	if c := img.RGBAAt(200, 250); c != goyolov5.DefaultPalette[0] {
+		t.Errorf("expected the person's box over the redaction, got %v", c)
+	}
+	if c := img.RGBAAt(70, 120); c != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("expected the car left alone, got %v", c)
+	}
+}
+
+func TestDetectCrops(t *testing.T) {
+	in, out := t.TempDir(), t.TempDir()
+	writeTestImages(t, in, "a.png")
+
+	err := detect(newFakeYoloV5(), []string{in}, detectOptions{out: out, confidence: 0.5, nms: 0.4, labels: "none", cropSize: 64})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"000_person.jpg", "001_car.jpg"} {
+		img, err := readImage(filepath.Join(out, "a_crops", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if img.Rect != image.Rect(0, 0, 64, 64) {
+			t.Errorf("%s: unexpected size %v", name, img.Rect)
+		}
+	}
+}
+
+func TestWriteCropsClassNames(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "crops")
+	tensor := goyolov5.NewTensor(image.Rect(0, 0, 100, 100))
+	predictions := []goyolov5.Prediction{{Rect: image.Rect(10, 10, 50, 50)}}
+	if err := writeCrops(dir, tensor, predictions, []string{"../traffic light"}, 32); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(filepath.Dir(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected only the crop directory, got %v", entries)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "000____traffic_light.jpg")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// constantClassifier is a classifier backend that always prefers its second
+// class. It records the size of each batch.
+type constantClassifier struct {
+	batches []int
+}
+
+func (c *constantClassifier) Forward(input *goyolov5.Tensor) ([]float32, []int, error) {
+	return c.ForwardBatch([]*goyolov5.Tensor{input})
+}
+
+func (c *constantClassifier) ForwardBatch(inputs []*goyolov5.Tensor) ([]float32, []int, error) {
+	c.batches = append(c.batches, len(inputs))
+	out := make([]float32, 0, 2*len(inputs))
+	for range inputs {
+		out = append(out, 0, 5)
+	}
+	return out, []int{len(inputs), 2}, nil
+}
+
+func TestDetectClassifier(t *testing.T) {
+	in, out := t.TempDir(), t.TempDir()
+	writeTestImages(t, in, "a.png")
+
+	classifier := goyolov5.NewClassifierWithBackend(&constantClassifier{}, 64)
+	classifier.SetClassNames([]string{"hatchback", "sedan"})
+	err := detect(newFakeYoloV5(), []string{in}, detectOptions{out: out, confidence: 0.5, nms: 0.4, labels: "json", classifier: classifier, classifierClasses: []uint{2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(out, "a.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var labels imageLabels
+	if err := json.Unmarshal(data, &labels); err != nil {
+		t.Fatal(err)
+	}
+	person, car := labels.Detections[0], labels.Detections[1]
+	if len(person.Classifications) != 0 || len(car.Classifications) != 1 || car.Classifications[0].Class != "sedan" {
+		t.Fatalf("expected the car classified as a sedan, got %+v", labels.Detections)
+	}
+
+	// Every class's crops share one forward pass
+	backend := &constantClassifier{}
+	classifier = goyolov5.NewClassifierWithBackend(backend, 64)
+	err = detect(newFakeYoloV5(), []string{in}, detectOptions{out: t.TempDir(), confidence: 0.5, nms: 0.4, labels: "json", classifier: classifier, classifierBatch: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(backend.batches, []int{2}) {
+		t.Fatalf("expected both crops in one batch, got %v", backend.batches)
+	}
+}
+
+func TestParseRedactStyle(t *testing.T) {
+	if style, err := parseRedactStyle("pixelate"); err != nil || style != goyolov5.RedactPixelate {
+		t.Fatalf("unexpected style %v, %v", style, err)
+	}
+	if _, err := parseRedactStyle("smudge"); err == nil {
+		t.Fatal("expected an error for an unknown style")
+	}
+}
+
+func TestParseClasses(t *testing.T) {
+	classes, err := parseClasses("0, 2,16")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(classes, []uint{0, 2, 16}) {
+		t.Fatalf("unexpected classes %v", classes)
+	}
+	if _, err := parseClasses("person"); err == nil {
+		t.Fatal("expected an error for a class name")
+	}
+}
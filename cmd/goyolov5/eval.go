@@ -0,0 +1,123 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/danhilltech/goyolov5"
+	"github.com/danhilltech/goyolov5/dataset"
+	"github.com/danhilltech/goyolov5/eval"
+)
+
+type evalOptions struct {
+	coco       string
+	images     string
+	labels     string
+	data       string
+	split      string
+	confidence float64
+	nms        float64
+	confusion  string
+	save       string
+}
+
+func evalCommand(args []string) error {
+	fs := flag.NewFlagSet("eval", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: goyolov5 eval -model path (-data data.yaml | -images dir (-coco instances.json | -labels dir)) [flags]")
+		fs.PrintDefaults()
+	}
+	var model modelOptions
+	model.register(fs)
+	var opts evalOptions
+	fs.StringVar(&opts.coco, "coco", "", "COCO instances JSON ground truth")
+	fs.StringVar(&opts.labels, "labels", "", "directory of YOLO txt ground truth, mirroring -images")
+	fs.StringVar(&opts.images, "images", "", "image directory")
+	fs.StringVar(&opts.data, "data", "", "dataset data.yaml, instead of -images and ground truth flags")
+	fs.StringVar(&opts.split, "split", "val", "dataset split to evaluate with -data")
+	fs.Float64Var(&opts.confidence, "conf", 0.001, "confidence threshold")
+	fs.Float64Var(&opts.nms, "nms", 0.6, "NMS IoU threshold")
+	fs.StringVar(&opts.confusion, "confusion", "", "write the confusion matrix as CSV to this file")
+	fs.StringVar(&opts.save, "save", "", "write detections as COCO results JSON to this file")
+	fs.Parse(args)
+
+	switch {
+	case opts.data != "":
+		if opts.images != "" || opts.coco != "" || opts.labels != "" {
+			fs.Usage()
+			return fmt.Errorf("-data cannot be used with -images, -coco or -labels")
+		}
+	case opts.images == "":
+		fs.Usage()
+		return fmt.Errorf("-data or -images is required")
+	case (opts.coco == "") == (opts.labels == ""):
+		fs.Usage()
+		return fmt.Errorf("exactly one of -coco and -labels is required")
+	}
+
+	yolov5, err := model.load()
+	if err != nil {
+		return err
+	}
+	return evaluate(os.Stdout, yolov5, opts)
+}
+
+func evaluate(w io.Writer, yolov5 *goyolov5.YoloV5, opts evalOptions) error {
+	var gt *eval.GroundTruth
+	var err error
+	switch {
+	case opts.data != "":
+		gt, err = loadDataset(opts.data, opts.split)
+	case opts.coco != "":
+		gt, err = eval.LoadCOCO(opts.coco)
+	default:
+		gt, err = eval.LoadYOLO(opts.images, opts.labels, yolov5.Info().ClassNames)
+	}
+	if err != nil {
+		return err
+	}
+
+	detections, err := eval.Run(yolov5, gt, opts.images, float32(opts.confidence), opts.nms)
+	if err != nil {
+		return err
+	}
+	report := eval.Evaluate(gt, detections)
+
+	if opts.save != "" {
+		if err := writeFile(opts.save, func(w io.Writer) error { return eval.WriteCOCODetections(w, gt, detections) }); err != nil {
+			return err
+		}
+	}
+	if opts.confusion != "" {
+		if err := writeFile(opts.confusion, report.Confusion.WriteCSV); err != nil {
+			return err
+		}
+	}
+	return report.WriteTable(w)
+}
+
+func loadDataset(data, name string) (*eval.GroundTruth, error) {
+	config, err := dataset.LoadConfig(data)
+	if err != nil {
+		return nil, err
+	}
+	split, err := config.Split(name)
+	if err != nil {
+		return nil, err
+	}
+	return eval.LoadDataset(split)
+}
+
+func writeFile(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
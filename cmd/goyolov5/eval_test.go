@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/danhilltech/goyolov5"
+)
+
+func TestEvaluate(t *testing.T) {
+	images, labels := t.TempDir(), t.TempDir()
+	writeTestImages(t, images, "a.png", "b.png")
+
+	// Ground truth is the model's own detections, so every class has AP 1
+	yolov5 := newFakeYoloV5()
+	tensor, err := readImage(filepath.Join(images, "a.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	predictions, err := yolov5.Infer(tensor, 0.5, 0.4, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var label bytes.Buffer
+	if err := goyolov5.WriteYOLOLabels(&label, predictions[0], tensor.Rect.Dx(), tensor.Rect.Dy(), false); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(labels, name), label.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	confusion := filepath.Join(t.TempDir(), "confusion.csv")
+	var out bytes.Buffer
+	err = evaluate(&out, yolov5, evalOptions{images: images, labels: labels, confidence: 0.001, nms: 0.6, confusion: confusion})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected a header, a total and two classes, got:\n%s", out.String())
+	}
+	if fields := strings.Fields(lines[1]); fields[0] != "all" || fields[3] != "1.0000" {
+		t.Errorf("expected mAP50 1, got %q", lines[1])
+	}
+	if _, err := os.Stat(confusion); err != nil {
+		t.Errorf("expected confusion matrix: %v", err)
+	}
+}
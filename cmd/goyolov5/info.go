@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/danhilltech/goyolov5"
+)
+
+func infoCommand(args []string) error {
+	fs := flag.NewFlagSet("info", flag.ExitOnError)
+	var model modelOptions
+	model.register(fs)
+	fs.Parse(args)
+
+	yolov5, err := model.load()
+	if err != nil {
+		return err
+	}
+	return printInfo(os.Stdout, yolov5)
+}
+
+func printInfo(w io.Writer, yolov5 *goyolov5.YoloV5) error {
+	shape, err := yolov5.OutputShape()
+	if err != nil {
+		return err
+	}
+	info := yolov5.Info()
+
+	stride := "unknown"
+	if info.Stride > 0 {
+		stride = fmt.Sprint(info.Stride)
+	}
+
+	fmt.Fprintf(w, "model:        %s\n", info.Name)
+	fmt.Fprintf(w, "input:        %dx%d\n", info.Size, info.Size)
+	fmt.Fprintf(w, "output shape: %v\n", shape)
+	fmt.Fprintf(w, "stride:       %s\n", stride)
+	fmt.Fprintf(w, "keypoints:    %d\n", info.Keypoints)
+	fmt.Fprintf(w, "classes:      %d\n", info.Classes)
+	for i, name := range info.ClassNames {
+		fmt.Fprintf(w, "  %3d %s\n", i, strings.TrimSpace(name))
+	}
+	return nil
+}
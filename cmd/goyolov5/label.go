@@ -0,0 +1,154 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/danhilltech/goyolov5"
+	"github.com/danhilltech/goyolov5/autolabel"
+)
+
+type labelOptions struct {
+	format      string
+	out         string
+	imagePrefix string
+	labeler     autolabel.Options
+}
+
+// defaultLabelOutputs are where each format goes without -out.
+var defaultLabelOutputs = map[string]string{
+	"cvat":        "annotations.xml",
+	"labelstudio": "tasks.json",
+	"yolo":        "labels",
+}
+
+func labelCommand(args []string) error {
+	fs := flag.NewFlagSet("label", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: goyolov5 label -model path -format cvat|labelstudio|yolo [flags] image-or-directory...")
+		fs.PrintDefaults()
+	}
+	var model modelOptions
+	model.register(fs)
+	var opts labelOptions
+	var classConfidence, rename string
+	fs.StringVar(&opts.format, "format", "cvat", "export format: cvat, labelstudio or yolo")
+	fs.StringVar(&opts.out, "out", "", "output file, or directory for yolo; default annotations.xml, tasks.json or labels")
+	fs.StringVar(&opts.imagePrefix, "image-prefix", "", "Label Studio image URL prefix, such as /data/local-files/?d=")
+	fs.Float64Var(&opts.labeler.Confidence, "conf", 0.25, "confidence threshold")
+	fs.Float64Var(&opts.labeler.NMS, "nms", 0.45, "NMS IoU threshold")
+	fs.StringVar(&classConfidence, "class-conf", "", "per-class confidence thresholds, as class=threshold,...")
+	fs.StringVar(&rename, "rename", "", "label names for model classes, as class=label,...; an empty label skips the class")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("no images given")
+	}
+	if _, ok := defaultLabelOutputs[opts.format]; !ok {
+		return fmt.Errorf("unknown export format %q", opts.format)
+	}
+	if opts.out == "" {
+		opts.out = defaultLabelOutputs[opts.format]
+	}
+
+	thresholds, err := parseAssignments(classConfidence)
+	if err != nil {
+		return err
+	}
+	opts.labeler.ClassConfidence = map[string]float64{}
+	for class, value := range thresholds {
+		if opts.labeler.ClassConfidence[class], err = strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("invalid confidence %q for %s", value, class)
+		}
+	}
+	if opts.labeler.Rename, err = parseAssignments(rename); err != nil {
+		return err
+	}
+
+	yolov5, err := model.load()
+	if err != nil {
+		return err
+	}
+	return label(yolov5, fs.Args(), opts)
+}
+
+// parseAssignments parses "key=value,..." lists. Keys may contain spaces, as
+// class names such as "traffic light" do.
+func parseAssignments(value string) (map[string]string, error) {
+	assignments := map[string]string{}
+	if value == "" {
+		return assignments, nil
+	}
+	for _, s := range strings.Split(value, ",") {
+		i := strings.LastIndex(s, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("expected class=value, got %q", s)
+		}
+		assignments[strings.TrimSpace(s[:i])] = strings.TrimSpace(s[i+1:])
+	}
+	return assignments, nil
+}
+
+func label(yolov5 *goyolov5.YoloV5, paths []string, opts labelOptions) error {
+	inputs, err := listImages(paths)
+	if err != nil {
+		return err
+	}
+	labeler, err := autolabel.NewLabeler(yolov5, opts.labeler)
+	if err != nil {
+		return err
+	}
+
+	var w autolabel.Writer
+	var f *os.File
+	if opts.format == "yolo" {
+		w, err = autolabel.NewYOLOWriter(opts.out, labeler.Labels())
+	} else {
+		if f, err = os.Create(opts.out); err != nil {
+			return err
+		}
+		defer f.Close()
+		if opts.format == "cvat" {
+			w, err = autolabel.NewCVATWriter(f, labeler.Labels())
+		} else {
+			w, err = autolabel.NewLabelStudioWriter(f, labeler.Labels(), autolabel.LabelStudioOptions{
+				ImagePrefix:  opts.imagePrefix,
+				ModelVersion: yolov5.Info().Name,
+			})
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	var total int
+	for _, input := range inputs {
+		tensor, err := readImage(input.path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", input.path, err)
+		}
+		img, err := labeler.Label(input.rel, tensor)
+		if err != nil {
+			return fmt.Errorf("%s: %w", input.path, err)
+		}
+		if err := w.Write(img); err != nil {
+			return err
+		}
+		total += len(img.Predictions)
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	if f != nil {
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("%d images, %d boxes written to %s\n", len(inputs), total, opts.out)
+	return nil
+}
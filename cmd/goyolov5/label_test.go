@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/danhilltech/goyolov5/autolabel"
+)
+
+func TestLabel(t *testing.T) {
+	in, out := t.TempDir(), t.TempDir()
+	writeTestImages(t, in, "a.png", "sub/b.jpg")
+
+	opts := labelOptions{
+		format:  "yolo",
+		out:     filepath.Join(out, "labels"),
+		labeler: autolabel.Options{Confidence: 0.5, NMS: 0.45, Rename: map[string]string{"person": ""}},
+	}
+	if err := label(newFakeYoloV5(), []string{in}, opts); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(out, "labels/sub/b.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 1 || !strings.HasPrefix(lines[0], "1 ") {
+		t.Errorf("expected one car, labelled 1 after person is skipped, got %q", data)
+	}
+
+	opts.format, opts.out = "labelstudio", filepath.Join(out, "tasks.json")
+	if err := label(newFakeYoloV5(), []string{in}, opts); err != nil {
+		t.Fatal(err)
+	}
+	if data, err = os.ReadFile(opts.out); err != nil || !strings.Contains(string(data), `"image":"sub/b.jpg"`) {
+		t.Errorf("unexpected tasks %s, %v", data, err)
+	}
+}
+
+func TestParseAssignments(t *testing.T) {
+	got, err := parseAssignments("person=0.5, traffic light = 0.3,car=")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"person": "0.5", "traffic light": "0.3", "car": ""}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if _, err := parseAssignments("person"); err == nil {
+		t.Error("expected an error without =")
+	}
+}
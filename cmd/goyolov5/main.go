@@ -0,0 +1,97 @@
+// Command goyolov5 runs YOLOv5 models from the command line.
+//
+//	goyolov5 detect -model weights/yolov5s/yolov5s.torchscript.cpu.640.pt -out out/ images/
+//	goyolov5 info -model weights/yolov5s/yolov5s.torchscript.cpu.640.pt
+//	goyolov5 bench -model weights/yolov5s/yolov5s.torchscript.cpu.640.pt -n 200
+//	goyolov5 eval -model weights/yolov5s/yolov5s.torchscript.cpu.640.pt -images val2017/ -coco instances_val2017.json
+//	goyolov5 validate -data datasets/coco128/data.yaml
+//	goyolov5 label -model weights/yolov5s/yolov5s.torchscript.cpu.640.pt -format cvat -out annotations.xml images/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/danhilltech/goyolov5"
+)
+
+const usage = `usage: goyolov5 <command> [flags]
+
+commands:
+  detect    run a model over images or directories of images
+  info      print a model's output shape, classes and stride
+  bench     time repeated runs of a model
+  eval      measure a model's mAP against ground truth
+  validate  check a dataset's images and labels
+  label     pre-annotate images for CVAT, Label Studio or YOLO txt
+
+Run goyolov5 <command> -h for a command's flags.
+`
+
+// modelOptions are the flags every command uses to load a model.
+type modelOptions struct {
+	path      string
+	size      int
+	gpu       bool
+	half      bool
+	classes   int
+	keypoints int
+}
+
+func (o *modelOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.path, "model", "", "TorchScript (.pt) or ONNX (.onnx) model to load")
+	fs.IntVar(&o.size, "size", 640, "model input size")
+	fs.BoolVar(&o.gpu, "gpu", false, "run on the GPU")
+	fs.BoolVar(&o.half, "half", false, "use half precision weights")
+	fs.IntVar(&o.classes, "nc", goyolov5.NClasses, "number of classes the model predicts")
+	fs.IntVar(&o.keypoints, "keypoints", 0, "number of keypoints for pose models")
+}
+
+func (o *modelOptions) load() (*goyolov5.YoloV5, error) {
+	if o.path == "" {
+		return nil, fmt.Errorf("-model is required")
+	}
+	device := goyolov5.DeviceCPU
+	if o.gpu {
+		device = goyolov5.DeviceGPU
+	}
+
+	if strings.EqualFold(filepath.Ext(o.path), ".onnx") {
+		if o.classes != goyolov5.NClasses || o.keypoints != 0 {
+			return nil, fmt.Errorf("ONNX models must be 80 class detection models")
+		}
+		return goyolov5.NewYoloV5ONNX(o.path, device, o.size)
+	}
+	if o.classes != goyolov5.NClasses || o.keypoints != 0 {
+		return goyolov5.NewYoloV5Pose(o.path, device, o.size, o.half, o.classes, o.keypoints)
+	}
+	return goyolov5.NewYoloV5(o.path, device, o.size, o.half)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	commands := map[string]func([]string) error{
+		"detect":   detectCommand,
+		"info":     infoCommand,
+		"bench":    benchCommand,
+		"eval":     evalCommand,
+		"validate": validateCommand,
+		"label":    labelCommand,
+	}
+	command, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+	if err := command(os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "goyolov5 %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/danhilltech/goyolov5/dataset"
+)
+
+func validateCommand(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: goyolov5 validate -data data.yaml [flags]")
+		fs.PrintDefaults()
+	}
+	data := fs.String("data", "", "dataset data.yaml")
+	split := fs.String("split", "", "split to check, default all")
+	fs.Parse(args)
+
+	if *data == "" {
+		fs.Usage()
+		return fmt.Errorf("-data is required")
+	}
+	config, err := dataset.LoadConfig(*data)
+	if err != nil {
+		return err
+	}
+	splits := config.SplitNames()
+	if *split != "" {
+		splits = []string{*split}
+	}
+	return validate(os.Stdout, config, splits)
+}
+
+// validate prints every issue of the splits and a summary per split, and
+// fails if any issue stops samples loading.
+func validate(w io.Writer, config *dataset.Config, splits []string) error {
+	var fatal int
+	for _, name := range splits {
+		split, err := config.Split(name)
+		if err != nil {
+			return err
+		}
+
+		counts := map[dataset.IssueKind]int{}
+		for _, issue := range split.Validate() {
+			fmt.Fprintln(w, issue)
+			counts[issue.Kind]++
+			if issue.Kind.Fatal() {
+				fatal++
+			}
+		}
+
+		fmt.Fprintf(w, "%s: %d images", name, split.Len())
+		for kind := dataset.MissingImage; kind <= dataset.DuplicateLabel; kind++ {
+			if counts[kind] > 0 {
+				fmt.Fprintf(w, ", %d %s", counts[kind], kind)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+
+	if fatal > 0 {
+		return fmt.Errorf("%d labels or images cannot be loaded", fatal)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/danhilltech/goyolov5/dataset"
+)
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	writeTestImages(t, filepath.Join(dir, "images"), "train/a.png", "train/b.png", "val/c.png")
+	for name, content := range map[string]string{
+		"data.yaml":          "train: images/train\nval: images/val\nnames: [person]\n",
+		"labels/train/a.txt": "0 0.5 0.5 0.2 0.2\n0 0.5 0.5 0.2 0.2\n",
+		"labels/train/b.txt": "1 0.5 0.5 0.2 0.2\n",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	config, err := dataset.LoadConfig(filepath.Join(dir, "data.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := validate(&out, config, config.SplitNames()); err == nil {
+		t.Error("expected an error for the unknown class")
+	}
+	for _, expected := range []string{
+		"train: 2 images, 1 unknown class, 1 duplicate label",
+		"val: 1 images, 1 missing label",
+		"b.txt:1: unknown class",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q in:\n%s", expected, out.String())
+		}
+	}
+
+	out.Reset()
+	if err := validate(&out, config, []string{"val"}); err != nil {
+		t.Errorf("expected only warnings, got %v", err)
+	}
+}
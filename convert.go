@@ -0,0 +1,129 @@
+package goyolov5
+
+import (
+	"image"
+)
+
+// The copy functions fill a tensor from an image with the same bounds. The
+// typed ones read pixels directly, reproducing what the image's At and
+// color.RGBAModel give, which copyImage does for any image.
+
+// copyImage copies any image, pixel by pixel, through its colour model.
+func (dst *Tensor) copyImage(src image.Image) {
+	r := dst.Rect
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			dst.Set(x, y, src.At(x, y))
+		}
+	}
+}
+
+func (dst *Tensor) copyTensor(src *Tensor) {
+	r := dst.Rect
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		i := src.PixOffset(r.Min.X, y)
+		copy(dst.Pix[dst.PixOffset(r.Min.X, y):], src.Pix[i:i+3*r.Dx()])
+	}
+}
+
+func (dst *Tensor) copyRGBA(src *image.RGBA) {
+	r := dst.Rect
+	w := r.Dx()
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		s := src.Pix[src.PixOffset(r.Min.X, y):][: 4*w : 4*w]
+		d := dst.Pix[dst.PixOffset(r.Min.X, y):][: 3*w : 3*w]
+		for i, j := 0, 0; i < len(s); i, j = i+4, j+3 {
+			d[j+0] = s[i+0]
+			d[j+1] = s[i+1]
+			d[j+2] = s[i+2]
+		}
+	}
+}
+
+// copyNRGBA premultiplies by alpha like color.NRGBA.RGBA.
+func (dst *Tensor) copyNRGBA(src *image.NRGBA) {
+	r := dst.Rect
+	w := r.Dx()
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		s := src.Pix[src.PixOffset(r.Min.X, y):][: 4*w : 4*w]
+		d := dst.Pix[dst.PixOffset(r.Min.X, y):][: 3*w : 3*w]
+		for i, j := 0, 0; i < len(s); i, j = i+4, j+3 {
+			a := uint32(s[i+3])
+			if a == 0xff {
+				d[j+0] = s[i+0]
+				d[j+1] = s[i+1]
+				d[j+2] = s[i+2]
+				continue
+			}
+			d[j+0] = uint8(uint32(s[i+0]) * 0x101 * a / 0xff >> 8)
+			d[j+1] = uint8(uint32(s[i+1]) * 0x101 * a / 0xff >> 8)
+			d[j+2] = uint8(uint32(s[i+2]) * 0x101 * a / 0xff >> 8)
+		}
+	}
+}
+
+func (dst *Tensor) copyGray(src *image.Gray) {
+	r := dst.Rect
+	w := r.Dx()
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		s := src.Pix[src.PixOffset(r.Min.X, y):][:w:w]
+		d := dst.Pix[dst.PixOffset(r.Min.X, y):][: 3*w : 3*w]
+		for i, j := 0, 0; i < len(s); i, j = i+1, j+3 {
+			d[j+0] = s[i]
+			d[j+1] = s[i]
+			d[j+2] = s[i]
+		}
+	}
+}
+
+// copyYCbCr handles every subsampling ratio, finding chroma samples like
+// image.YCbCr.COffset. Its conversion is color.YCbCr.RGBA's, which can differ
+// by one from color.YCbCrToRGB.
+func (dst *Tensor) copyYCbCr(src *image.YCbCr) {
+	r := dst.Rect
+	w := r.Dx()
+
+	div := 1
+	switch src.SubsampleRatio {
+	case image.YCbCrSubsampleRatio422, image.YCbCrSubsampleRatio420:
+		div = 2
+	case image.YCbCrSubsampleRatio411, image.YCbCrSubsampleRatio410:
+		div = 4
+	}
+	// Chroma column of each pixel in a row, relative to the row's first
+	cols := make([]int, w)
+	for i := range cols {
+		x := r.Min.X + i
+		cols[i] = x/div - r.Min.X/div
+	}
+
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		ys := src.Y[src.YOffset(r.Min.X, y):][:w:w]
+		ci := src.COffset(r.Min.X, y)
+		cbs, crs := src.Cb[ci:], src.Cr[ci:]
+		d := dst.Pix[dst.PixOffset(r.Min.X, y):][: 3*w : 3*w]
+		for i, j := 0, 0; i < len(ys); i, j = i+1, j+3 {
+			// Inlined copy of color.YCbCr.RGBA, keeping the top 8 bits
+			c := cols[i]
+			yy1 := int32(ys[i]) * 0x10101
+			cb1 := int32(cbs[c]) - 128
+			cr1 := int32(crs[c]) - 128
+			d[j+0] = clampFixed(yy1 + 91881*cr1)
+			d[j+1] = clampFixed(yy1 - 22554*cb1 - 46802*cr1)
+			d[j+2] = clampFixed(yy1 + 116130*cb1)
+		}
+	}
+}
+
+// clampFixed takes the integer part of a 16.16 fixed point value, saturating
+// to a byte.
+func clampFixed(v int32) uint8 {
+	v >>= 16
+	if v < 0 {
+		v = 0
+	}
+	if v > 0xff {
+		v = 0xff
+	}
+	return uint8(v)
+}
@@ -0,0 +1,127 @@
+package goyolov5
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"math/rand"
+	"testing"
+)
+
+// genericTensor copies img through its colour model, the path the typed
+// copies must match.
+func genericTensor(img image.Image) *Tensor {
+	t := NewTensor(img.Bounds())
+	t.copyImage(img)
+	return t
+}
+
+func randomize(rng *rand.Rand, pix ...[]uint8) {
+	for _, p := range pix {
+		rng.Read(p)
+	}
+}
+
+// testImages returns an image of each fast path type, with odd bounds and
+// random pixels.
+func testImages(rng *rand.Rand) map[string]image.Image {
+	r := image.Rect(-3, 5, 38, 30)
+	images := map[string]image.Image{}
+
+	rgba := image.NewRGBA(r)
+	randomize(rng, rgba.Pix)
+	images["rgba"] = rgba
+
+	nrgba := image.NewNRGBA(r)
+	randomize(rng, nrgba.Pix)
+	for i := 3; i < len(nrgba.Pix); i += 16 {
+		nrgba.Pix[i] = 0xff
+	}
+	images["nrgba"] = nrgba
+
+	gray := image.NewGray(r)
+	randomize(rng, gray.Pix)
+	images["gray"] = gray
+
+	tensor := NewTensor(r)
+	randomize(rng, tensor.Pix)
+	images["tensor"] = tensor
+
+	for _, ratio := range []image.YCbCrSubsampleRatio{
+		image.YCbCrSubsampleRatio444, image.YCbCrSubsampleRatio422, image.YCbCrSubsampleRatio420,
+		image.YCbCrSubsampleRatio440, image.YCbCrSubsampleRatio411, image.YCbCrSubsampleRatio410,
+	} {
+		ycbcr := image.NewYCbCr(r, ratio)
+		randomize(rng, ycbcr.Y, ycbcr.Cb, ycbcr.Cr)
+		images[ratio.String()] = ycbcr
+	}
+	return images
+}
+
+func TestNewTensorFromImage(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for name, img := range testImages(rng) {
+		want := genericTensor(img)
+		got := NewTensorFromImage(img)
+		if got.Rect != want.Rect || !bytes.Equal(got.Pix, want.Pix) {
+			t.Errorf("%s: differs from the generic copy", name)
+		}
+
+		// Sub-images start mid chroma block and mid row
+		sub := img.(interface {
+			SubImage(image.Rectangle) image.Image
+		}).SubImage(image.Rect(-1, 7, 33, 28))
+		want = genericTensor(sub)
+		got = NewTensorFromImage(sub)
+		if got.Rect != want.Rect || !bytes.Equal(got.Pix, want.Pix) {
+			t.Errorf("%s: sub-image differs from the generic copy", name)
+		}
+	}
+}
+
+func TestNewTensorFromYCbCrExhaustive(t *testing.T) {
+	// Every Cb, Cr pair in a row, for a spread of Y, including the clamped
+	// extremes
+	img := image.NewYCbCr(image.Rect(0, 0, 256*256, 1), image.YCbCrSubsampleRatio444)
+	for cb := 0; cb < 256; cb++ {
+		for cr := 0; cr < 256; cr++ {
+			img.Cb[cb*256+cr], img.Cr[cb*256+cr] = uint8(cb), uint8(cr)
+		}
+	}
+	for y := 0; y < 256; y += 15 {
+		for i := range img.Y {
+			img.Y[i] = uint8(y)
+		}
+		if !bytes.Equal(NewTensorFromImage(img).Pix, genericTensor(img).Pix) {
+			t.Fatalf("Y %d: differs from the generic copy", y)
+		}
+	}
+}
+
+func BenchmarkNewTensorFromImage(b *testing.B) {
+	r := image.Rect(0, 0, 1920, 1080)
+	rng := rand.New(rand.NewSource(1))
+
+	rgba := image.NewRGBA(r)
+	nrgba := image.NewNRGBA(r)
+	gray := image.NewGray(r)
+	tensor := NewTensor(r)
+	ycbcr := image.NewYCbCr(r, image.YCbCrSubsampleRatio420)
+	randomize(rng, rgba.Pix, nrgba.Pix, gray.Pix, tensor.Pix, ycbcr.Y, ycbcr.Cb, ycbcr.Cr)
+
+	for _, c := range []struct {
+		name string
+		img  image.Image
+	}{{"RGBA", rgba}, {"NRGBA", nrgba}, {"YCbCr420", ycbcr}, {"Gray", gray}, {"Tensor", tensor}} {
+		b.Run(c.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				NewTensorFromImage(c.img)
+			}
+		})
+		b.Run(fmt.Sprintf("%sGeneric", c.name), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				genericTensor(c.img)
+			}
+		})
+	}
+}
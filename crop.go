@@ -0,0 +1,88 @@
+package goyolov5
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// Crop returns the part of the image inside r, sharing its pixels like
+// image.RGBA.SubImage: drawing on the crop draws on the image. The crop keeps
+// the image's coordinates, so Infer on it returns predictions placed in the
+// image.
+func (p *Tensor) Crop(r image.Rectangle) *Tensor {
+	r = r.Intersect(p.Rect)
+	if r.Empty() {
+		return &Tensor{}
+	}
+	i := p.PixOffset(r.Min.X, r.Min.Y)
+	return &Tensor{Pix: p.Pix[i:], Stride: p.Stride, Rect: r}
+}
+
+// SubImage implements the SubImage method of the standard image types with
+// Crop.
+func (p *Tensor) SubImage(r image.Rectangle) image.Image {
+	return p.Crop(r)
+}
+
+// SquareCrop copies the part of the image inside r into the centre of a
+// square padded with pad, so the object keeps its aspect ratio, and resizes
+// it to size x size unless size is 0. The crop's origin is (0, 0). It returns
+// nil if r is outside the image.
+func (p *Tensor) SquareCrop(r image.Rectangle, size int, pad color.Color) *Tensor {
+	r = r.Intersect(p.Rect)
+	if r.Empty() {
+		return nil
+	}
+
+	side := maxSide(r)
+	square := NewTensor(image.Rect(0, 0, side, side))
+	if r, g, b, _ := pad.RGBA(); r|g|b != 0 {
+		square.FillRect(square.Rect, pad)
+	}
+	offset := image.Pt((side-r.Dx())/2, (side-r.Dy())/2)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		i := p.PixOffset(r.Min.X, y)
+		copy(square.Pix[square.PixOffset(offset.X, offset.Y+y-r.Min.Y):], p.Pix[i:i+3*r.Dx()])
+	}
+
+	if size > 0 && size != side {
+		return square.resize(size, size)
+	}
+	return square
+}
+
+// CropOptions configures CropPredictions.
+type CropOptions struct {
+	// Size is the side of every crop, or 0 to keep each crop at its padded
+	// size.
+	Size int
+	// Margin grows boxes by a fraction of their width and height on each
+	// side, and MarginPixels by a fixed number of pixels, to include some
+	// context around objects.
+	Margin       float64
+	MarginPixels int
+	// Pad is the colour squaring crops, black if zero.
+	Pad color.RGBA
+}
+
+// CropPredictions returns a square crop of each prediction, in order, ready to
+// encode as a thumbnail or to pass to a second-stage model. Crops of boxes
+// entirely outside the image are nil.
+func CropPredictions(img *Tensor, predictions []Prediction, opts CropOptions) []*Tensor {
+	pad := opts.Pad
+	pad.A = 255
+	crops := make([]*Tensor, len(predictions))
+	for i, pred := range predictions {
+		crops[i] = img.SquareCrop(expandRect(pred.Rect, opts.Margin, opts.MarginPixels), opts.Size, pad)
+	}
+	return crops
+}
+
+// expandRect grows r by margin times its width and height, rounded up, plus
+// pixels on each side.
+func expandRect(r image.Rectangle, margin float64, pixels int) image.Rectangle {
+	dx := int(math.Ceil(margin*float64(r.Dx()))) + pixels
+	dy := int(math.Ceil(margin*float64(r.Dy()))) + pixels
+	return image.Rect(r.Min.X-dx, r.Min.Y-dy, r.Max.X+dx, r.Max.Y+dy)
+}
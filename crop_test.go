@@ -0,0 +1,128 @@
+package goyolov5
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// numbered returns a tensor whose pixels encode their coordinates.
+func numbered(r image.Rectangle) *Tensor {
+	img := NewTensor(r)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 7, 255})
+		}
+	}
+	return img
+}
+
+func TestCrop(t *testing.T) {
+	img := numbered(image.Rect(0, 0, 100, 80))
+	crop := img.Crop(image.Rect(20, 30, 60, 200))
+	if crop.Rect != image.Rect(20, 30, 60, 80) {
+		t.Fatalf("expected the crop clipped to the image, got %v", crop.Rect)
+	}
+	if c := crop.RGBAAt(25, 35); c != (color.RGBA{25, 35, 7, 255}) {
+		t.Errorf("expected the crop in image coordinates, got %v", c)
+	}
+	if c := crop.RGBAAt(10, 10); c != (color.RGBA{}) {
+		t.Errorf("expected nothing outside the crop, got %v", c)
+	}
+
+	crop.Set(40, 40, color.White)
+	if c := img.RGBAAt(40, 40); c != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("expected the crop to share pixels, got %v", c)
+	}
+
+	if sub := img.SubImage(image.Rect(0, 0, 10, 10)).(*Tensor); sub.Rect != image.Rect(0, 0, 10, 10) {
+		t.Errorf("unexpected sub-image %v", sub.Rect)
+	}
+	if empty := img.Crop(image.Rect(200, 200, 300, 300)); !empty.Rect.Empty() || len(empty.Pix) != 0 {
+		t.Errorf("expected an empty crop, got %v", empty.Rect)
+	}
+
+	// Copies respect the crop's origin and stride
+	copied := NewTensorFromImage(crop)
+	if copied.Rect != crop.Rect || copied.RGBAAt(59, 79) != (color.RGBA{59, 79, 7, 255}) {
+		t.Errorf("unexpected copy of a crop %v %v", copied.Rect, copied.RGBAAt(59, 79))
+	}
+	square, extraX, extraY, err := crop.ToSquareShape()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if square.Rect != image.Rect(0, 0, 50, 50) || extraX != 10 || extraY != 0 {
+		t.Fatalf("unexpected square %v, %d, %d", square.Rect, extraX, extraY)
+	}
+	if c := square.RGBAAt(1, 0); c != (color.RGBA{21, 30, 7, 255}) {
+		t.Errorf("expected the crop at the top left of the square, got %v", c)
+	}
+	if c := square.RGBAAt(45, 0); c != (color.RGBA{0, 0, 0, 255}) {
+		t.Errorf("expected padding right of the crop, got %v", c)
+	}
+}
+
+func TestSquareCrop(t *testing.T) {
+	img := numbered(image.Rect(0, 0, 100, 80))
+
+	// A tall box is centred with padding either side
+	crop := img.SquareCrop(image.Rect(10, 10, 14, 20), 0, color.RGBA{114, 114, 114, 255})
+	if crop.Rect != image.Rect(0, 0, 10, 10) {
+		t.Fatalf("unexpected crop %v", crop.Rect)
+	}
+	if c := crop.RGBAAt(3, 0); c != (color.RGBA{10, 10, 7, 255}) {
+		t.Errorf("expected the box centred, got %v", c)
+	}
+	if c := crop.RGBAAt(2, 5); c != (color.RGBA{114, 114, 114, 255}) {
+		t.Errorf("expected padding, got %v", c)
+	}
+
+	// Small boxes are upscaled
+	up := img.SquareCrop(image.Rect(10, 10, 20, 20), 40, color.Black)
+	if up.Rect != image.Rect(0, 0, 40, 40) {
+		t.Fatalf("unexpected crop %v", up.Rect)
+	}
+	if c := up.RGBAAt(39, 39); c != (color.RGBA{19, 19, 7, 255}) {
+		t.Errorf("unexpected corner %v", c)
+	}
+
+	if img.SquareCrop(image.Rect(-10, -10, 0, 0), 32, color.Black) != nil {
+		t.Error("expected no crop outside the image")
+	}
+}
+
+func TestCropPredictions(t *testing.T) {
+	img := numbered(image.Rect(0, 0, 100, 80))
+	preds := []Prediction{
+		{Rect: image.Rect(40, 30, 60, 40)},
+		{Rect: image.Rect(200, 200, 220, 220)},
+	}
+	crops := CropPredictions(img, preds, CropOptions{Size: 32, Margin: 0.5, MarginPixels: 2})
+	if len(crops) != 2 || crops[1] != nil {
+		t.Fatalf("expected a crop and a nil, got %v", crops)
+	}
+	if crops[0].Rect != image.Rect(0, 0, 32, 32) {
+		t.Fatalf("unexpected crop %v", crops[0].Rect)
+	}
+	// The box grows to (28, 23)-(72, 47), 44x24, padded to 44x44
+	if c := crops[0].RGBAAt(0, 16); c != (color.RGBA{28, 35, 7, 255}) {
+		t.Errorf("expected the expanded box, got %v", c)
+	}
+	if c := crops[0].RGBAAt(0, 0); c != (color.RGBA{0, 0, 0, 255}) {
+		t.Errorf("expected padding above, got %v", c)
+	}
+}
+
+func TestInferCrop(t *testing.T) {
+	backend := NewFakeBackend(NClasses, 0, 10, FakeDetection{Rect: image.Rect(10, 10, 60, 110), Confidence: 0.9, ClassIndex: 2, ClassConfidence: 0.8})
+	yolov5 := NewYoloV5WithBackend(backend, 320, NClasses, 0)
+
+	img := NewTensor(image.Rect(0, 0, 640, 480))
+	predictions, err := yolov5.Infer(img.Crop(image.Rect(100, 50, 420, 370)), 0.5, 0.4, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(predictions[0]) != 1 || predictions[0][0].Rect != image.Rect(110, 60, 160, 160) {
+		t.Fatalf("expected the prediction in image coordinates, got %v", predictions)
+	}
+}
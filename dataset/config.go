@@ -0,0 +1,157 @@
+// Package dataset reads datasets in the Ultralytics YOLO layout: a data.yaml
+// naming the classes and splits, images under images/ and one YOLO txt label
+// file per image under the matching path in labels/. It validates labels and
+// loads samples as goyolov5 tensors for evaluation and labelling tools.
+package dataset
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is a parsed data.yaml.
+type Config struct {
+	// Path is the dataset root that relative split paths resolve against.
+	Path string
+	// Splits are the image sources of each split, such as "train" and "val".
+	// A source is a directory searched recursively for images, a text file
+	// listing one image per line, or a single image.
+	Splits map[string][]string
+	// Names are the class names by class index.
+	Names []string
+	// Keypoints and KeypointDims are kpt_shape for pose datasets: the number
+	// of keypoints per object and whether each has 2 values, x and y, or 3
+	// with a visibility.
+	Keypoints    int
+	KeypointDims int
+}
+
+// sources is a split's value, a single path or a list of them.
+type sources []string
+
+func (s *sources) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		var path string
+		if err := value.Decode(&path); err != nil {
+			return err
+		}
+		*s = sources{path}
+		return nil
+	}
+	var paths []string
+	if err := value.Decode(&paths); err != nil {
+		return err
+	}
+	*s = paths
+	return nil
+}
+
+// names is the names value, a list or a map from class index to name.
+type names []string
+
+func (n *names) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.SequenceNode {
+		var list []string
+		if err := value.Decode(&list); err != nil {
+			return err
+		}
+		*n = list
+		return nil
+	}
+
+	var byIndex map[int]string
+	if err := value.Decode(&byIndex); err != nil {
+		return err
+	}
+	list := make([]string, len(byIndex))
+	for i, name := range byIndex {
+		if i < 0 || i >= len(list) {
+			return fmt.Errorf("class indices must run from 0 to %d, got %d", len(list)-1, i)
+		}
+		list[i] = name
+	}
+	*n = list
+	return nil
+}
+
+type rawConfig struct {
+	Path     string  `yaml:"path"`
+	Train    sources `yaml:"train"`
+	Val      sources `yaml:"val"`
+	Test     sources `yaml:"test"`
+	NC       int     `yaml:"nc"`
+	Names    names   `yaml:"names"`
+	KptShape []int   `yaml:"kpt_shape"`
+}
+
+// LoadConfig reads a data.yaml. A relative path, or no path at all, is taken
+// relative to the directory of the file rather than the working directory.
+// Without names, classes are named by index from nc.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw rawConfig
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	root := raw.Path
+	if !filepath.IsAbs(root) {
+		root = filepath.Join(filepath.Dir(path), root)
+	}
+	config := &Config{
+		Path:   filepath.Clean(root),
+		Splits: map[string][]string{},
+		Names:  raw.Names,
+	}
+
+	switch {
+	case config.Names == nil && raw.NC > 0:
+		for i := 0; i < raw.NC; i++ {
+			config.Names = append(config.Names, fmt.Sprintf("class%d", i))
+		}
+	case config.Names == nil:
+		return nil, fmt.Errorf("%s: no names or nc", path)
+	case raw.NC > 0 && raw.NC != len(config.Names):
+		return nil, fmt.Errorf("%s: nc is %d but there are %d names", path, raw.NC, len(config.Names))
+	}
+
+	switch len(raw.KptShape) {
+	case 0:
+	case 2:
+		config.Keypoints, config.KeypointDims = raw.KptShape[0], raw.KptShape[1]
+		if config.Keypoints < 1 || (config.KeypointDims != 2 && config.KeypointDims != 3) {
+			return nil, fmt.Errorf("%s: invalid kpt_shape %v", path, raw.KptShape)
+		}
+	default:
+		return nil, fmt.Errorf("%s: invalid kpt_shape %v", path, raw.KptShape)
+	}
+
+	for name, srcs := range map[string]sources{"train": raw.Train, "val": raw.Val, "test": raw.Test} {
+		for _, src := range srcs {
+			if !filepath.IsAbs(src) {
+				src = filepath.Join(config.Path, src)
+			}
+			config.Splits[name] = append(config.Splits[name], src)
+		}
+	}
+	return config, nil
+}
+
+// SplitNames returns the names of the splits with sources, sorted.
+func (c *Config) SplitNames() []string {
+	var names []string
+	for name, srcs := range c.Splits {
+		if len(srcs) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,105 @@
+package dataset
+
+import (
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeFiles creates files under dir, with PNG images for .png names.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if filepath.Ext(name) != ".png" {
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := png.Encode(f, image.NewRGBA(image.Rect(0, 0, 64, 48))); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"data.yaml": `
+path: coco8
+train: [images/train, extra.txt]
+val: images/val
+nc: 2
+names:
+  0: person
+  1: bicycle
+`,
+	})
+
+	config, err := LoadConfig(filepath.Join(dir, "data.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := filepath.Join(dir, "coco8")
+	if config.Path != root {
+		t.Errorf("expected path %s, got %s", root, config.Path)
+	}
+	if !reflect.DeepEqual(config.Names, []string{"person", "bicycle"}) {
+		t.Errorf("unexpected names %v", config.Names)
+	}
+	expected := map[string][]string{
+		"train": {filepath.Join(root, "images/train"), filepath.Join(root, "extra.txt")},
+		"val":   {filepath.Join(root, "images/val")},
+	}
+	if !reflect.DeepEqual(config.Splits, expected) {
+		t.Errorf("expected splits %v, got %v", expected, config.Splits)
+	}
+	if !reflect.DeepEqual(config.SplitNames(), []string{"train", "val"}) {
+		t.Errorf("unexpected split names %v", config.SplitNames())
+	}
+}
+
+func TestLoadConfigPose(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"pose.yaml": "train: images\nkpt_shape: [17, 3]\nnames: [person]\n",
+	})
+
+	config, err := LoadConfig(filepath.Join(dir, "pose.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Path != dir {
+		t.Errorf("expected path %s, got %s", dir, config.Path)
+	}
+	if format := config.Format(); format != (Format{Classes: 1, Keypoints: 17, KeypointDims: 3}) {
+		t.Errorf("unexpected format %+v", format)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	for name, content := range map[string]string{
+		"no names":      "train: images\n",
+		"nc mismatch":   "train: images\nnc: 3\nnames: [a, b]\n",
+		"index gap":     "train: images\nnames: {0: a, 2: b}\n",
+		"bad kpt_shape": "train: images\nnames: [a]\nkpt_shape: [17, 4]\n",
+		"bad yaml":      "train: [images\n",
+	} {
+		path := filepath.Join(t.TempDir(), "data.yaml")
+		writeFiles(t, filepath.Dir(path), map[string]string{"data.yaml": content})
+		if _, err := LoadConfig(path); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package dataset
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Label is one object of a YOLO txt label file. Coordinates are normalised
+// by the image size to [0, 1].
+type Label struct {
+	ClassIndex int
+	// X and Y are the box center, W and H its size.
+	X, Y, W, H float64
+	// Keypoints are x, y and visibility for pose datasets. Visibility is 2
+	// for datasets without it, as in COCO for labelled visible keypoints.
+	Keypoints [][3]float64
+}
+
+// Rect returns the label's box in pixels of an image of the given size.
+func (l Label) Rect(width, height int) image.Rectangle {
+	w, h := l.W*float64(width), l.H*float64(height)
+	x, y := l.X*float64(width)-w/2, l.Y*float64(height)-h/2
+	return image.Rect(int(math.Round(x)), int(math.Round(y)), int(math.Round(x+w)), int(math.Round(y+h)))
+}
+
+// Format is what a label file of a dataset holds: class indices below
+// Classes and, for pose datasets, Keypoints of KeypointDims values.
+type Format struct {
+	Classes      int
+	Keypoints    int
+	KeypointDims int
+}
+
+// Format returns the label format of the config's dataset.
+func (c *Config) Format() Format {
+	return Format{Classes: len(c.Names), Keypoints: c.Keypoints, KeypointDims: c.KeypointDims}
+}
+
+// boundsTolerance allows for rounding in label files written with few digits.
+const boundsTolerance = 1e-6
+
+// ReadLabels reads a YOLO txt label file. A missing file means an image
+// without objects. It fails on the first line with an issue Validate counts
+// as fatal; duplicate lines are kept.
+func ReadLabels(path string, format Format) ([]Label, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	labels, issues := parseLabels(f, path, format)
+	for _, issue := range issues {
+		if issue.Kind.Fatal() {
+			return nil, issue
+		}
+	}
+	return labels, nil
+}
+
+// parseLabels reads every line it can and reports the rest as issues. Lines
+// with polygon segments, as in segmentation datasets, become the polygon's
+// bounding box.
+func parseLabels(r io.Reader, path string, format Format) ([]Label, []Issue) {
+	var labels []Label
+	var issues []Issue
+	report := func(kind IssueKind, line int, format string, args ...interface{}) {
+		issues = append(issues, Issue{Kind: kind, Path: path, Line: line, Message: fmt.Sprintf(format, args...)})
+	}
+
+	fields := 5 + format.Keypoints*format.KeypointDims
+	seen := map[string]int{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.Join(strings.Fields(scanner.Text()), " ")
+		if text == "" {
+			continue
+		}
+		if first, ok := seen[text]; ok {
+			report(DuplicateLabel, line, "duplicate of line %d", first)
+		} else {
+			seen[text] = line
+		}
+
+		values := strings.Fields(text)
+		segment := format.Keypoints == 0 && len(values) >= 7 && len(values)%2 == 1
+		if len(values) != fields && !segment {
+			report(MalformedLabel, line, "expected %d fields, got %d", fields, len(values))
+			continue
+		}
+
+		class, err := strconv.Atoi(values[0])
+		if err != nil {
+			report(MalformedLabel, line, "invalid class %q", values[0])
+			continue
+		}
+		coords := make([]float64, len(values)-1)
+		valid := true
+		for i := range coords {
+			coords[i], err = strconv.ParseFloat(values[i+1], 64)
+			if err != nil || math.IsNaN(coords[i]) || math.IsInf(coords[i], 0) {
+				report(MalformedLabel, line, "invalid number %q", values[i+1])
+				valid = false
+				break
+			}
+		}
+		if !valid {
+			continue
+		}
+		if class < 0 || class >= format.Classes {
+			report(UnknownClass, line, "class %d is not in [0, %d)", class, format.Classes)
+			continue
+		}
+
+		label := Label{ClassIndex: class}
+		if segment {
+			label.X, label.Y, label.W, label.H = segmentBox(coords)
+		} else {
+			label.X, label.Y, label.W, label.H = coords[0], coords[1], coords[2], coords[3]
+			for k := 0; k < format.Keypoints; k++ {
+				kp := coords[4+k*format.KeypointDims:]
+				keypoint := [3]float64{kp[0], kp[1], 2}
+				if format.KeypointDims == 3 {
+					keypoint[2] = kp[2]
+				}
+				label.Keypoints = append(label.Keypoints, keypoint)
+			}
+		}
+
+		if label.W <= 0 || label.H <= 0 {
+			report(EmptyBox, line, "box has size %gx%g", label.W, label.H)
+			continue
+		}
+		if !inBounds(label.X-label.W/2, label.Y-label.H/2) || !inBounds(label.X+label.W/2, label.Y+label.H/2) {
+			report(OutOfRange, line, "box %g %g %g %g is outside the image", label.X, label.Y, label.W, label.H)
+			continue
+		}
+		inRange := true
+		for _, kp := range label.Keypoints {
+			// Unlabelled keypoints are often written as 0 0 0
+			if kp[2] != 0 && !inBounds(kp[0], kp[1]) {
+				inRange = false
+			}
+		}
+		if !inRange {
+			report(OutOfRange, line, "keypoint outside the image")
+			continue
+		}
+
+		labels = append(labels, label)
+	}
+	if err := scanner.Err(); err != nil {
+		report(MalformedLabel, 0, "%v", err)
+	}
+	return labels, issues
+}
+
+func inBounds(x, y float64) bool {
+	return x >= -boundsTolerance && x <= 1+boundsTolerance && y >= -boundsTolerance && y <= 1+boundsTolerance
+}
+
+// segmentBox returns the center and size of the box around polygon points.
+func segmentBox(points []float64) (x, y, w, h float64) {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for i := 0; i+1 < len(points); i += 2 {
+		minX, maxX = math.Min(minX, points[i]), math.Max(maxX, points[i])
+		minY, maxY = math.Min(minY, points[i+1]), math.Max(maxY, points[i+1])
+	}
+	return (minX + maxX) / 2, (minY + maxY) / 2, maxX - minX, maxY - minY
+}
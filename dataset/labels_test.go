@@ -0,0 +1,80 @@
+package dataset
+
+import (
+	"image"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseLabels(t *testing.T) {
+	input := strings.Join([]string{
+		"0 0.5 0.5 0.2 0.4",
+		"",
+		"1 0.1 0.1 0.2 0.2 extra",
+		"x 0.5 0.5 0.2 0.2",
+		"3 0.5 0.5 0.2 0.2",
+		"0 0.5 0.5 0 0.2",
+		"0 0.95 0.5 0.2 0.2",
+		"0 0.5 0.5 NaN 0.2",
+		"1  0.25 0.25 0.1 0.1",
+		"1 0.25 0.25 0.1 0.1",
+		"2 0.1 0.2 0.3 0.2 0.3 0.6",
+	}, "\n")
+
+	labels, issues := parseLabels(strings.NewReader(input), "a.txt", Format{Classes: 3})
+
+	expected := []Label{
+		{ClassIndex: 0, X: 0.5, Y: 0.5, W: 0.2, H: 0.4},
+		{ClassIndex: 1, X: 0.25, Y: 0.25, W: 0.1, H: 0.1},
+		{ClassIndex: 1, X: 0.25, Y: 0.25, W: 0.1, H: 0.1},
+		{ClassIndex: 2, X: 0.2, Y: 0.4, W: 0.2, H: 0.4},
+	}
+	if len(labels) != len(expected) {
+		t.Fatalf("expected %d labels, got %+v", len(expected), labels)
+	}
+	for i := range expected {
+		l, e := labels[i], expected[i]
+		if l.ClassIndex != e.ClassIndex || !near(l.X, e.X) || !near(l.Y, e.Y) || !near(l.W, e.W) || !near(l.H, e.H) {
+			t.Errorf("label %d: expected %+v, got %+v", i, e, l)
+		}
+	}
+
+	var kinds []IssueKind
+	var lines []int
+	for _, issue := range issues {
+		kinds = append(kinds, issue.Kind)
+		lines = append(lines, issue.Line)
+	}
+	if !reflect.DeepEqual(kinds, []IssueKind{MalformedLabel, MalformedLabel, UnknownClass, EmptyBox, OutOfRange, MalformedLabel, DuplicateLabel}) {
+		t.Errorf("unexpected issues %v", issues)
+	}
+	if !reflect.DeepEqual(lines, []int{3, 4, 5, 6, 7, 8, 10}) {
+		t.Errorf("unexpected issue lines %v", lines)
+	}
+	if msg := issues[2].Error(); msg != "a.txt:5: unknown class: class 3 is not in [0, 3)" {
+		t.Errorf("unexpected message %q", msg)
+	}
+}
+
+func TestParseLabelsKeypoints(t *testing.T) {
+	format := Format{Classes: 1, Keypoints: 2, KeypointDims: 2}
+	labels, issues := parseLabels(strings.NewReader("0 0.5 0.5 0.2 0.2 0.45 0.45 0.55 0.55\n0 0.5 0.5 0.2 0.2 0.5 0.5\n0 0.5 0.5 0.2 0.2 1.5 0.5 0.5 0.5\n"), "p.txt", format)
+	if len(labels) != 1 || !reflect.DeepEqual(labels[0].Keypoints, [][3]float64{{0.45, 0.45, 2}, {0.55, 0.55, 2}}) {
+		t.Errorf("unexpected labels %+v", labels)
+	}
+	if len(issues) != 2 || issues[0].Kind != MalformedLabel || issues[1].Kind != OutOfRange {
+		t.Errorf("unexpected issues %v", issues)
+	}
+}
+
+func TestLabelRect(t *testing.T) {
+	label := Label{X: 0.5, Y: 0.25, W: 0.5, H: 0.5}
+	if r := label.Rect(640, 480); r != image.Rect(160, 0, 480, 240) {
+		t.Errorf("unexpected rect %v", r)
+	}
+}
+
+func near(a, b float64) bool {
+	return a-b < 1e-9 && b-a < 1e-9
+}
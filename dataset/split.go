@@ -0,0 +1,180 @@
+package dataset
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/danhilltech/goyolov5"
+)
+
+// Split is the images of one split of a dataset.
+type Split struct {
+	Name string
+	// Images are the image paths in sorted order.
+	Images []string
+	// Names are the class names by class index.
+	Names  []string
+	Format Format
+}
+
+// Sample is an image with its labels.
+type Sample struct {
+	ImagePath string
+	LabelPath string
+	Tensor    *goyolov5.Tensor
+	Labels    []Label
+}
+
+// Split lists the images of the named split. Directories are searched
+// recursively for JPEG and PNG files, the formats goyolov5 decodes, and
+// text files list one image per line, relative to the text file.
+func (c *Config) Split(name string) (*Split, error) {
+	srcs, ok := c.Splits[name]
+	if !ok || len(srcs) == 0 {
+		return nil, fmt.Errorf("dataset has no %s split", name)
+	}
+
+	split := &Split{Name: name, Names: c.Names, Format: c.Format()}
+	for _, src := range srcs {
+		images, err := listImages(src)
+		if err != nil {
+			return nil, err
+		}
+		split.Images = append(split.Images, images...)
+	}
+	sort.Strings(split.Images)
+	return split, nil
+}
+
+func listImages(src string) ([]string, error) {
+	info, err := os.Stat(src)
+	if err != nil {
+		return nil, err
+	}
+
+	var images []string
+	switch {
+	case info.IsDir():
+		err = filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() && isImage(path) {
+				images = append(images, path)
+			}
+			return nil
+		})
+	case strings.EqualFold(filepath.Ext(src), ".txt"):
+		images, err = readImageList(src)
+	case isImage(src):
+		images = []string{src}
+	default:
+		err = fmt.Errorf("%s is not a directory, image list or image", src)
+	}
+	return images, err
+}
+
+func readImageList(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var images []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || !isImage(line) {
+			continue
+		}
+		if !filepath.IsAbs(line) {
+			line = filepath.Join(filepath.Dir(path), line)
+		}
+		images = append(images, line)
+	}
+	return images, scanner.Err()
+}
+
+func isImage(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jpg", ".jpeg", ".png":
+		return true
+	}
+	return false
+}
+
+// LabelPath returns the label file of an image: the last images directory
+// of its path replaced by labels, and its extension by .txt.
+func LabelPath(imagePath string) string {
+	sep := string(filepath.Separator)
+	images, labels := sep+"images"+sep, sep+"labels"+sep
+	if i := strings.LastIndex(imagePath, images); i >= 0 {
+		imagePath = imagePath[:i] + labels + imagePath[i+len(images):]
+	} else if strings.HasPrefix(imagePath, "images"+sep) {
+		imagePath = "labels" + sep + strings.TrimPrefix(imagePath, "images"+sep)
+	}
+	return strings.TrimSuffix(imagePath, filepath.Ext(imagePath)) + ".txt"
+}
+
+// Len is the number of images in the split.
+func (s *Split) Len() int {
+	return len(s.Images)
+}
+
+// Labels reads the labels of the i-th image without decoding it.
+func (s *Split) Labels(i int) ([]Label, error) {
+	return ReadLabels(LabelPath(s.Images[i]), s.Format)
+}
+
+// Load decodes the i-th image and reads its labels.
+func (s *Split) Load(i int) (*Sample, error) {
+	sample := &Sample{ImagePath: s.Images[i], LabelPath: LabelPath(s.Images[i])}
+
+	tensor, err := readImage(sample.ImagePath)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", sample.ImagePath, err)
+	}
+	sample.Tensor = tensor
+
+	if sample.Labels, err = ReadLabels(sample.LabelPath, s.Format); err != nil {
+		return nil, err
+	}
+	return sample, nil
+}
+
+// Walk loads every sample in order, stopping at the first error from loading
+// or from fn.
+func (s *Split) Walk(fn func(*Sample) error) error {
+	for i := range s.Images {
+		sample, err := s.Load(i)
+		if err != nil {
+			return err
+		}
+		if err := fn(sample); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readImage(path string) (*goyolov5.Tensor, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, err
+	}
+	return goyolov5.NewTensorFromImage(img), nil
+}
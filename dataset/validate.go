@@ -0,0 +1,122 @@
+package dataset
+
+import (
+	"fmt"
+	"image"
+	"os"
+)
+
+// IssueKind classifies a problem Validate found.
+type IssueKind int
+
+const (
+	// MissingImage is an image listed by a split that does not exist.
+	MissingImage IssueKind = iota
+	// UnreadableImage is an image that is not a readable JPEG or PNG.
+	UnreadableImage
+	// MissingLabel is an image without a label file, which is allowed for
+	// background images but often means labels are in the wrong place.
+	MissingLabel
+	// MalformedLabel is a label line with the wrong number of fields or
+	// values that are not numbers.
+	MalformedLabel
+	// UnknownClass is a label with a class index the dataset has no name for.
+	UnknownClass
+	// OutOfRange is a box or keypoint outside the image.
+	OutOfRange
+	// EmptyBox is a box without area.
+	EmptyBox
+	// DuplicateLabel is a label line repeated in the same file.
+	DuplicateLabel
+)
+
+func (k IssueKind) String() string {
+	switch k {
+	case MissingImage:
+		return "missing image"
+	case UnreadableImage:
+		return "unreadable image"
+	case MissingLabel:
+		return "missing label"
+	case MalformedLabel:
+		return "malformed label"
+	case UnknownClass:
+		return "unknown class"
+	case OutOfRange:
+		return "out of range"
+	case EmptyBox:
+		return "empty box"
+	case DuplicateLabel:
+		return "duplicate label"
+	}
+	return "unknown"
+}
+
+// Fatal reports whether the issue stops a sample loading. Missing label
+// files and duplicate labels only warn.
+func (k IssueKind) Fatal() bool {
+	return k != MissingLabel && k != DuplicateLabel
+}
+
+// Issue is a problem with one file of a dataset.
+type Issue struct {
+	Kind IssueKind
+	Path string
+	// Line is the label file line, or 0 for problems with a whole file.
+	Line    int
+	Message string
+}
+
+func (i Issue) Error() string {
+	if i.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s: %s", i.Path, i.Line, i.Kind, i.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", i.Path, i.Kind, i.Message)
+}
+
+// Validate checks every image of the split and its labels, and returns the
+// issues found in image order.
+func (s *Split) Validate() []Issue {
+	var issues []Issue
+	for _, path := range s.Images {
+		if issue, ok := checkImage(path); !ok {
+			issues = append(issues, issue)
+			continue
+		}
+
+		labelPath := LabelPath(path)
+		f, err := os.Open(labelPath)
+		if os.IsNotExist(err) {
+			issues = append(issues, Issue{Kind: MissingLabel, Path: labelPath, Message: "no label file"})
+			continue
+		}
+		if err != nil {
+			issues = append(issues, Issue{Kind: MalformedLabel, Path: labelPath, Message: err.Error()})
+			continue
+		}
+		_, fileIssues := parseLabels(f, labelPath, s.Format)
+		f.Close()
+		issues = append(issues, fileIssues...)
+	}
+	return issues
+}
+
+func checkImage(path string) (Issue, bool) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return Issue{Kind: MissingImage, Path: path, Message: "no such file"}, false
+	}
+	if err != nil {
+		return Issue{Kind: UnreadableImage, Path: path, Message: err.Error()}, false
+	}
+	defer f.Close()
+
+	config, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return Issue{Kind: UnreadableImage, Path: path, Message: err.Error()}, false
+	}
+	if config.Width == 0 || config.Height == 0 {
+		return Issue{Kind: UnreadableImage, Path: path, Message: "image is empty"}, false
+	}
+	return Issue{}, true
+}
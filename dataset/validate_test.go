@@ -0,0 +1,108 @@
+package dataset
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeDataset(t *testing.T) *Config {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"data.yaml":            "train: images/train\nval: val.txt\nnames: [person, car]\n",
+		"images/train/a.png":   "",
+		"images/train/b/c.png": "",
+		"images/train/d.png":   "",
+		"images/train/notes":   "not an image",
+		"labels/train/a.txt":   "0 0.5 0.5 0.5 0.5\n1 0.25 0.25 0.1 0.1\n",
+		"labels/train/b/c.txt": "2 0.5 0.5 0.5 0.5\n",
+		"images/val/e.png":     "",
+		"images/val/bad.jpg":   "not a jpeg",
+		"val.txt":              "images/val/e.png\nimages/val/missing.png\n" + filepath.Join(dir, "images/val/bad.jpg") + "\n",
+	})
+
+	config, err := LoadConfig(filepath.Join(dir, "data.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return config
+}
+
+func TestSplit(t *testing.T) {
+	config := writeDataset(t)
+
+	split, err := config.Split("train")
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := config.Path
+	expected := []string{
+		filepath.Join(root, "images/train/a.png"),
+		filepath.Join(root, "images/train/b/c.png"),
+		filepath.Join(root, "images/train/d.png"),
+	}
+	if !reflect.DeepEqual(split.Images, expected) {
+		t.Fatalf("expected images %v, got %v", expected, split.Images)
+	}
+
+	sample, err := split.Load(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sample.Tensor.Rect.Dx() != 64 || len(sample.Labels) != 2 || sample.LabelPath != filepath.Join(root, "labels/train/a.txt") {
+		t.Errorf("unexpected sample %+v", sample)
+	}
+
+	// b/c.txt has an unknown class
+	var seen int
+	err = split.Walk(func(s *Sample) error {
+		seen++
+		return nil
+	})
+	var issue Issue
+	if !errors.As(err, &issue) || issue.Kind != UnknownClass || seen != 1 {
+		t.Errorf("expected an unknown class after one sample, got %v after %d", err, seen)
+	}
+
+	// Images without label files have no objects
+	if labels, err := split.Labels(2); err != nil || len(labels) != 0 {
+		t.Errorf("expected no labels, got %v, %v", labels, err)
+	}
+
+	if _, err := config.Split("test"); err == nil {
+		t.Error("expected an error for a missing split")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	config := writeDataset(t)
+
+	var kinds []IssueKind
+	for _, name := range []string{"train", "val"} {
+		split, err := config.Split(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, issue := range split.Validate() {
+			kinds = append(kinds, issue.Kind)
+		}
+	}
+
+	expected := []IssueKind{UnknownClass, MissingLabel, UnreadableImage, MissingLabel, MissingImage}
+	if !reflect.DeepEqual(kinds, expected) {
+		t.Errorf("expected issues %v, got %v", expected, kinds)
+	}
+}
+
+func TestLabelPath(t *testing.T) {
+	for path, expected := range map[string]string{
+		"/data/images/train/images/a.jpg": "/data/images/train/labels/a.txt",
+		"images/b.png":                    "labels/b.txt",
+		"/data/c.jpeg":                    "/data/c.txt",
+	} {
+		if got := LabelPath(filepath.FromSlash(path)); got != filepath.FromSlash(expected) {
+			t.Errorf("%s: expected %s, got %s", path, expected, got)
+		}
+	}
+}
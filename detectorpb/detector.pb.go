@@ -0,0 +1,843 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.28.1
+// 	protoc        (unknown)
+// source: detectorpb/detector.proto
+
+package detectorpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// RawImage is packed 8-bit RGB, row by row.
+type RawImage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Width  int32  `protobuf:"varint,1,opt,name=width,proto3" json:"width,omitempty"`
+	Height int32  `protobuf:"varint,2,opt,name=height,proto3" json:"height,omitempty"`
+	Rgb    []byte `protobuf:"bytes,3,opt,name=rgb,proto3" json:"rgb,omitempty"`
+}
+
+func (x *RawImage) Reset() {
+	*x = RawImage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_detectorpb_detector_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RawImage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RawImage) ProtoMessage() {}
+
+func (x *RawImage) ProtoReflect() protoreflect.Message {
+	mi := &file_detectorpb_detector_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RawImage.ProtoReflect.Descriptor instead.
+func (*RawImage) Descriptor() ([]byte, []int) {
+	return file_detectorpb_detector_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *RawImage) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *RawImage) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *RawImage) GetRgb() []byte {
+	if x != nil {
+		return x.Rgb
+	}
+	return nil
+}
+
+type DetectRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// model selects a loaded model by name. Empty selects the default model.
+	Model string `protobuf:"bytes,1,opt,name=model,proto3" json:"model,omitempty"`
+	// Types that are assignable to Image:
+	//	*DetectRequest_Encoded
+	//	*DetectRequest_Raw
+	Image isDetectRequest_Image `protobuf_oneof:"image"`
+	// confidence and nms override the server's default thresholds.
+	Confidence *float32 `protobuf:"fixed32,4,opt,name=confidence,proto3,oneof" json:"confidence,omitempty"`
+	Nms        *float32 `protobuf:"fixed32,5,opt,name=nms,proto3,oneof" json:"nms,omitempty"`
+	// classes keeps only detections of these class indices. Empty keeps all.
+	Classes []uint32 `protobuf:"varint,6,rep,packed,name=classes,proto3" json:"classes,omitempty"`
+	// frame_id is echoed in the response.
+	FrameId uint64 `protobuf:"varint,7,opt,name=frame_id,json=frameId,proto3" json:"frame_id,omitempty"`
+	// timeout_ms bounds this request within a stream, in addition to the call
+	// deadline. Zero means no per-request timeout.
+	TimeoutMs uint32 `protobuf:"varint,8,opt,name=timeout_ms,json=timeoutMs,proto3" json:"timeout_ms,omitempty"`
+}
+
+func (x *DetectRequest) Reset() {
+	*x = DetectRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_detectorpb_detector_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DetectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DetectRequest) ProtoMessage() {}
+
+func (x *DetectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_detectorpb_detector_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DetectRequest.ProtoReflect.Descriptor instead.
+func (*DetectRequest) Descriptor() ([]byte, []int) {
+	return file_detectorpb_detector_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *DetectRequest) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (m *DetectRequest) GetImage() isDetectRequest_Image {
+	if m != nil {
+		return m.Image
+	}
+	return nil
+}
+
+func (x *DetectRequest) GetEncoded() []byte {
+	if x, ok := x.GetImage().(*DetectRequest_Encoded); ok {
+		return x.Encoded
+	}
+	return nil
+}
+
+func (x *DetectRequest) GetRaw() *RawImage {
+	if x, ok := x.GetImage().(*DetectRequest_Raw); ok {
+		return x.Raw
+	}
+	return nil
+}
+
+func (x *DetectRequest) GetConfidence() float32 {
+	if x != nil && x.Confidence != nil {
+		return *x.Confidence
+	}
+	return 0
+}
+
+func (x *DetectRequest) GetNms() float32 {
+	if x != nil && x.Nms != nil {
+		return *x.Nms
+	}
+	return 0
+}
+
+func (x *DetectRequest) GetClasses() []uint32 {
+	if x != nil {
+		return x.Classes
+	}
+	return nil
+}
+
+func (x *DetectRequest) GetFrameId() uint64 {
+	if x != nil {
+		return x.FrameId
+	}
+	return 0
+}
+
+func (x *DetectRequest) GetTimeoutMs() uint32 {
+	if x != nil {
+		return x.TimeoutMs
+	}
+	return 0
+}
+
+type isDetectRequest_Image interface {
+	isDetectRequest_Image()
+}
+
+type DetectRequest_Encoded struct {
+	// encoded is a JPEG or PNG image.
+	Encoded []byte `protobuf:"bytes,2,opt,name=encoded,proto3,oneof"`
+}
+
+type DetectRequest_Raw struct {
+	Raw *RawImage `protobuf:"bytes,3,opt,name=raw,proto3,oneof"`
+}
+
+func (*DetectRequest_Encoded) isDetectRequest_Image() {}
+
+func (*DetectRequest_Raw) isDetectRequest_Image() {}
+
+type Box struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	X0 int32 `protobuf:"varint,1,opt,name=x0,proto3" json:"x0,omitempty"`
+	Y0 int32 `protobuf:"varint,2,opt,name=y0,proto3" json:"y0,omitempty"`
+	X1 int32 `protobuf:"varint,3,opt,name=x1,proto3" json:"x1,omitempty"`
+	Y1 int32 `protobuf:"varint,4,opt,name=y1,proto3" json:"y1,omitempty"`
+}
+
+func (x *Box) Reset() {
+	*x = Box{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_detectorpb_detector_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Box) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Box) ProtoMessage() {}
+
+func (x *Box) ProtoReflect() protoreflect.Message {
+	mi := &file_detectorpb_detector_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Box.ProtoReflect.Descriptor instead.
+func (*Box) Descriptor() ([]byte, []int) {
+	return file_detectorpb_detector_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Box) GetX0() int32 {
+	if x != nil {
+		return x.X0
+	}
+	return 0
+}
+
+func (x *Box) GetY0() int32 {
+	if x != nil {
+		return x.Y0
+	}
+	return 0
+}
+
+func (x *Box) GetX1() int32 {
+	if x != nil {
+		return x.X1
+	}
+	return 0
+}
+
+func (x *Box) GetY1() int32 {
+	if x != nil {
+		return x.Y1
+	}
+	return 0
+}
+
+type Keypoint struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	X          float32 `protobuf:"fixed32,1,opt,name=x,proto3" json:"x,omitempty"`
+	Y          float32 `protobuf:"fixed32,2,opt,name=y,proto3" json:"y,omitempty"`
+	Visibility float32 `protobuf:"fixed32,3,opt,name=visibility,proto3" json:"visibility,omitempty"`
+}
+
+func (x *Keypoint) Reset() {
+	*x = Keypoint{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_detectorpb_detector_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Keypoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Keypoint) ProtoMessage() {}
+
+func (x *Keypoint) ProtoReflect() protoreflect.Message {
+	mi := &file_detectorpb_detector_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Keypoint.ProtoReflect.Descriptor instead.
+func (*Keypoint) Descriptor() ([]byte, []int) {
+	return file_detectorpb_detector_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Keypoint) GetX() float32 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *Keypoint) GetY() float32 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+func (x *Keypoint) GetVisibility() float32 {
+	if x != nil {
+		return x.Visibility
+	}
+	return 0
+}
+
+// Classification is one class from a second-stage classifier, such as a
+// cascade's, with its softmax probability.
+type Classification struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Class      string  `protobuf:"bytes,1,opt,name=class,proto3" json:"class,omitempty"`
+	ClassIndex uint32  `protobuf:"varint,2,opt,name=class_index,json=classIndex,proto3" json:"class_index,omitempty"`
+	Score      float32 `protobuf:"fixed32,3,opt,name=score,proto3" json:"score,omitempty"`
+}
+
+func (x *Classification) Reset() {
+	*x = Classification{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_detectorpb_detector_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Classification) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Classification) ProtoMessage() {}
+
+func (x *Classification) ProtoReflect() protoreflect.Message {
+	mi := &file_detectorpb_detector_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Classification.ProtoReflect.Descriptor instead.
+func (*Classification) Descriptor() ([]byte, []int) {
+	return file_detectorpb_detector_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Classification) GetClass() string {
+	if x != nil {
+		return x.Class
+	}
+	return ""
+}
+
+func (x *Classification) GetClassIndex() uint32 {
+	if x != nil {
+		return x.ClassIndex
+	}
+	return 0
+}
+
+func (x *Classification) GetScore() float32 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+type Detection struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Class           string      `protobuf:"bytes,1,opt,name=class,proto3" json:"class,omitempty"`
+	ClassIndex      uint32      `protobuf:"varint,2,opt,name=class_index,json=classIndex,proto3" json:"class_index,omitempty"`
+	Confidence      float32     `protobuf:"fixed32,3,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	ClassConfidence float32     `protobuf:"fixed32,4,opt,name=class_confidence,json=classConfidence,proto3" json:"class_confidence,omitempty"`
+	Box             *Box        `protobuf:"bytes,5,opt,name=box,proto3" json:"box,omitempty"`
+	Keypoints       []*Keypoint `protobuf:"bytes,6,rep,name=keypoints,proto3" json:"keypoints,omitempty"`
+	// classifications are the top classes of a cascade's classifier, most
+	// likely first.
+	Classifications []*Classification `protobuf:"bytes,7,rep,name=classifications,proto3" json:"classifications,omitempty"`
+}
+
+func (x *Detection) Reset() {
+	*x = Detection{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_detectorpb_detector_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Detection) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Detection) ProtoMessage() {}
+
+func (x *Detection) ProtoReflect() protoreflect.Message {
+	mi := &file_detectorpb_detector_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Detection.ProtoReflect.Descriptor instead.
+func (*Detection) Descriptor() ([]byte, []int) {
+	return file_detectorpb_detector_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Detection) GetClass() string {
+	if x != nil {
+		return x.Class
+	}
+	return ""
+}
+
+func (x *Detection) GetClassIndex() uint32 {
+	if x != nil {
+		return x.ClassIndex
+	}
+	return 0
+}
+
+func (x *Detection) GetConfidence() float32 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+func (x *Detection) GetClassConfidence() float32 {
+	if x != nil {
+		return x.ClassConfidence
+	}
+	return 0
+}
+
+func (x *Detection) GetBox() *Box {
+	if x != nil {
+		return x.Box
+	}
+	return nil
+}
+
+func (x *Detection) GetKeypoints() []*Keypoint {
+	if x != nil {
+		return x.Keypoints
+	}
+	return nil
+}
+
+func (x *Detection) GetClassifications() []*Classification {
+	if x != nil {
+		return x.Classifications
+	}
+	return nil
+}
+
+type DetectResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Model      string       `protobuf:"bytes,1,opt,name=model,proto3" json:"model,omitempty"`
+	FrameId    uint64       `protobuf:"varint,2,opt,name=frame_id,json=frameId,proto3" json:"frame_id,omitempty"`
+	Width      int32        `protobuf:"varint,3,opt,name=width,proto3" json:"width,omitempty"`
+	Height     int32        `protobuf:"varint,4,opt,name=height,proto3" json:"height,omitempty"`
+	Detections []*Detection `protobuf:"bytes,5,rep,name=detections,proto3" json:"detections,omitempty"`
+}
+
+func (x *DetectResponse) Reset() {
+	*x = DetectResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_detectorpb_detector_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DetectResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DetectResponse) ProtoMessage() {}
+
+func (x *DetectResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_detectorpb_detector_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DetectResponse.ProtoReflect.Descriptor instead.
+func (*DetectResponse) Descriptor() ([]byte, []int) {
+	return file_detectorpb_detector_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DetectResponse) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *DetectResponse) GetFrameId() uint64 {
+	if x != nil {
+		return x.FrameId
+	}
+	return 0
+}
+
+func (x *DetectResponse) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *DetectResponse) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *DetectResponse) GetDetections() []*Detection {
+	if x != nil {
+		return x.Detections
+	}
+	return nil
+}
+
+var File_detectorpb_detector_proto protoreflect.FileDescriptor
+
+var file_detectorpb_detector_proto_rawDesc = []byte{
+	0x0a, 0x19, 0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x70, 0x62, 0x2f, 0x64, 0x65, 0x74,
+	0x65, 0x63, 0x74, 0x6f, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x14, 0x67, 0x6f, 0x79,
+	0x6f, 0x6c, 0x6f, 0x76, 0x35, 0x2e, 0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x2e, 0x76,
+	0x31, 0x22, 0x4a, 0x0a, 0x08, 0x52, 0x61, 0x77, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x12, 0x14, 0x0a,
+	0x05, 0x77, 0x69, 0x64, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x77, 0x69,
+	0x64, 0x74, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x72,
+	0x67, 0x62, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x72, 0x67, 0x62, 0x22, 0xa5, 0x02,
+	0x0a, 0x0d, 0x44, 0x65, 0x74, 0x65, 0x63, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x14, 0x0a, 0x05, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x12, 0x1a, 0x0a, 0x07, 0x65, 0x6e, 0x63, 0x6f, 0x64, 0x65, 0x64,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x48, 0x00, 0x52, 0x07, 0x65, 0x6e, 0x63, 0x6f, 0x64, 0x65,
+	0x64, 0x12, 0x32, 0x0a, 0x03, 0x72, 0x61, 0x77, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e,
+	0x2e, 0x67, 0x6f, 0x79, 0x6f, 0x6c, 0x6f, 0x76, 0x35, 0x2e, 0x64, 0x65, 0x74, 0x65, 0x63, 0x74,
+	0x6f, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x61, 0x77, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x48, 0x00,
+	0x52, 0x03, 0x72, 0x61, 0x77, 0x12, 0x23, 0x0a, 0x0a, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65,
+	0x6e, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x02, 0x48, 0x01, 0x52, 0x0a, 0x63, 0x6f, 0x6e,
+	0x66, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x88, 0x01, 0x01, 0x12, 0x15, 0x0a, 0x03, 0x6e, 0x6d,
+	0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x02, 0x48, 0x02, 0x52, 0x03, 0x6e, 0x6d, 0x73, 0x88, 0x01,
+	0x01, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x65, 0x73, 0x18, 0x06, 0x20, 0x03,
+	0x28, 0x0d, 0x52, 0x07, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x65, 0x73, 0x12, 0x19, 0x0a, 0x08, 0x66,
+	0x72, 0x61, 0x6d, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x66,
+	0x72, 0x61, 0x6d, 0x65, 0x49, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75,
+	0x74, 0x5f, 0x6d, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65,
+	0x6f, 0x75, 0x74, 0x4d, 0x73, 0x42, 0x07, 0x0a, 0x05, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x42, 0x0d,
+	0x0a, 0x0b, 0x5f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x42, 0x06, 0x0a,
+	0x04, 0x5f, 0x6e, 0x6d, 0x73, 0x22, 0x45, 0x0a, 0x03, 0x42, 0x6f, 0x78, 0x12, 0x0e, 0x0a, 0x02,
+	0x78, 0x30, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x78, 0x30, 0x12, 0x0e, 0x0a, 0x02,
+	0x79, 0x30, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x79, 0x30, 0x12, 0x0e, 0x0a, 0x02,
+	0x78, 0x31, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x78, 0x31, 0x12, 0x0e, 0x0a, 0x02,
+	0x79, 0x31, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x79, 0x31, 0x22, 0x46, 0x0a, 0x08,
+	0x4b, 0x65, 0x79, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x0c, 0x0a, 0x01, 0x78, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x02, 0x52, 0x01, 0x78, 0x12, 0x0c, 0x0a, 0x01, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x02, 0x52, 0x01, 0x79, 0x12, 0x1e, 0x0a, 0x0a, 0x76, 0x69, 0x73, 0x69, 0x62, 0x69, 0x6c, 0x69,
+	0x74, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x02, 0x52, 0x0a, 0x76, 0x69, 0x73, 0x69, 0x62, 0x69,
+	0x6c, 0x69, 0x74, 0x79, 0x22, 0x5d, 0x0a, 0x0e, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x69, 0x66, 0x69,
+	0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x12, 0x1f, 0x0a, 0x0b,
+	0x63, 0x6c, 0x61, 0x73, 0x73, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x0a, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x14, 0x0a,
+	0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x02, 0x52, 0x05, 0x73, 0x63,
+	0x6f, 0x72, 0x65, 0x22, 0xc8, 0x02, 0x0a, 0x09, 0x44, 0x65, 0x74, 0x65, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x63, 0x6c, 0x61, 0x73, 0x73,
+	0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x63, 0x6c,
+	0x61, 0x73, 0x73, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x02, 0x52, 0x0a, 0x63, 0x6f,
+	0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x29, 0x0a, 0x10, 0x63, 0x6c, 0x61, 0x73,
+	0x73, 0x5f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x02, 0x52, 0x0f, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65,
+	0x6e, 0x63, 0x65, 0x12, 0x2b, 0x0a, 0x03, 0x62, 0x6f, 0x78, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x19, 0x2e, 0x67, 0x6f, 0x79, 0x6f, 0x6c, 0x6f, 0x76, 0x35, 0x2e, 0x64, 0x65, 0x74, 0x65,
+	0x63, 0x74, 0x6f, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x6f, 0x78, 0x52, 0x03, 0x62, 0x6f, 0x78,
+	0x12, 0x3c, 0x0a, 0x09, 0x6b, 0x65, 0x79, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x18, 0x06, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x67, 0x6f, 0x79, 0x6f, 0x6c, 0x6f, 0x76, 0x35, 0x2e, 0x64,
+	0x65, 0x74, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x4b, 0x65, 0x79, 0x70, 0x6f,
+	0x69, 0x6e, 0x74, 0x52, 0x09, 0x6b, 0x65, 0x79, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x12, 0x4e,
+	0x0a, 0x0f, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x24, 0x2e, 0x67, 0x6f, 0x79, 0x6f, 0x6c, 0x6f,
+	0x76, 0x35, 0x2e, 0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x43,
+	0x6c, 0x61, 0x73, 0x73, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0f, 0x63,
+	0x6c, 0x61, 0x73, 0x73, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0xb0,
+	0x01, 0x0a, 0x0e, 0x44, 0x65, 0x74, 0x65, 0x63, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x14, 0x0a, 0x05, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x12, 0x19, 0x0a, 0x08, 0x66, 0x72, 0x61, 0x6d, 0x65,
+	0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x66, 0x72, 0x61, 0x6d, 0x65,
+	0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x77, 0x69, 0x64, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x05, 0x77, 0x69, 0x64, 0x74, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x65, 0x69, 0x67,
+	0x68, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74,
+	0x12, 0x3f, 0x0a, 0x0a, 0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x05,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x67, 0x6f, 0x79, 0x6f, 0x6c, 0x6f, 0x76, 0x35, 0x2e,
+	0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x74, 0x65,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0a, 0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x32, 0xbe, 0x01, 0x0a, 0x08, 0x44, 0x65, 0x74, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x12, 0x53,
+	0x0a, 0x06, 0x44, 0x65, 0x74, 0x65, 0x63, 0x74, 0x12, 0x23, 0x2e, 0x67, 0x6f, 0x79, 0x6f, 0x6c,
+	0x6f, 0x76, 0x35, 0x2e, 0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x2e, 0x76, 0x31, 0x2e,
+	0x44, 0x65, 0x74, 0x65, 0x63, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e,
+	0x67, 0x6f, 0x79, 0x6f, 0x6c, 0x6f, 0x76, 0x35, 0x2e, 0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x6f,
+	0x72, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x74, 0x65, 0x63, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x5d, 0x0a, 0x0c, 0x44, 0x65, 0x74, 0x65, 0x63, 0x74, 0x53, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x12, 0x23, 0x2e, 0x67, 0x6f, 0x79, 0x6f, 0x6c, 0x6f, 0x76, 0x35, 0x2e, 0x64,
+	0x65, 0x74, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x74, 0x65, 0x63,
+	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e, 0x67, 0x6f, 0x79, 0x6f, 0x6c,
+	0x6f, 0x76, 0x35, 0x2e, 0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x2e, 0x76, 0x31, 0x2e,
+	0x44, 0x65, 0x74, 0x65, 0x63, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01,
+	0x30, 0x01, 0x42, 0x2c, 0x5a, 0x2a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d,
+	0x2f, 0x64, 0x61, 0x6e, 0x68, 0x69, 0x6c, 0x6c, 0x74, 0x65, 0x63, 0x68, 0x2f, 0x67, 0x6f, 0x79,
+	0x6f, 0x6c, 0x6f, 0x76, 0x35, 0x2f, 0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x70, 0x62,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_detectorpb_detector_proto_rawDescOnce sync.Once
+	file_detectorpb_detector_proto_rawDescData = file_detectorpb_detector_proto_rawDesc
+)
+
+func file_detectorpb_detector_proto_rawDescGZIP() []byte {
+	file_detectorpb_detector_proto_rawDescOnce.Do(func() {
+		file_detectorpb_detector_proto_rawDescData = protoimpl.X.CompressGZIP(file_detectorpb_detector_proto_rawDescData)
+	})
+	return file_detectorpb_detector_proto_rawDescData
+}
+
+var file_detectorpb_detector_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_detectorpb_detector_proto_goTypes = []interface{}{
+	(*RawImage)(nil),       // 0: goyolov5.detector.v1.RawImage
+	(*DetectRequest)(nil),  // 1: goyolov5.detector.v1.DetectRequest
+	(*Box)(nil),            // 2: goyolov5.detector.v1.Box
+	(*Keypoint)(nil),       // 3: goyolov5.detector.v1.Keypoint
+	(*Classification)(nil), // 4: goyolov5.detector.v1.Classification
+	(*Detection)(nil),      // 5: goyolov5.detector.v1.Detection
+	(*DetectResponse)(nil), // 6: goyolov5.detector.v1.DetectResponse
+}
+var file_detectorpb_detector_proto_depIdxs = []int32{
+	0, // 0: goyolov5.detector.v1.DetectRequest.raw:type_name -> goyolov5.detector.v1.RawImage
+	2, // 1: goyolov5.detector.v1.Detection.box:type_name -> goyolov5.detector.v1.Box
+	3, // 2: goyolov5.detector.v1.Detection.keypoints:type_name -> goyolov5.detector.v1.Keypoint
+	4, // 3: goyolov5.detector.v1.Detection.classifications:type_name -> goyolov5.detector.v1.Classification
+	5, // 4: goyolov5.detector.v1.DetectResponse.detections:type_name -> goyolov5.detector.v1.Detection
+	1, // 5: goyolov5.detector.v1.Detector.Detect:input_type -> goyolov5.detector.v1.DetectRequest
+	1, // 6: goyolov5.detector.v1.Detector.DetectStream:input_type -> goyolov5.detector.v1.DetectRequest
+	6, // 7: goyolov5.detector.v1.Detector.Detect:output_type -> goyolov5.detector.v1.DetectResponse
+	6, // 8: goyolov5.detector.v1.Detector.DetectStream:output_type -> goyolov5.detector.v1.DetectResponse
+	7, // [7:9] is the sub-list for method output_type
+	5, // [5:7] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_detectorpb_detector_proto_init() }
+func file_detectorpb_detector_proto_init() {
+	if File_detectorpb_detector_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_detectorpb_detector_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RawImage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_detectorpb_detector_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DetectRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_detectorpb_detector_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Box); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_detectorpb_detector_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Keypoint); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_detectorpb_detector_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Classification); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_detectorpb_detector_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Detection); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_detectorpb_detector_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DetectResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_detectorpb_detector_proto_msgTypes[1].OneofWrappers = []interface{}{
+		(*DetectRequest_Encoded)(nil),
+		(*DetectRequest_Raw)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_detectorpb_detector_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_detectorpb_detector_proto_goTypes,
+		DependencyIndexes: file_detectorpb_detector_proto_depIdxs,
+		MessageInfos:      file_detectorpb_detector_proto_msgTypes,
+	}.Build()
+	File_detectorpb_detector_proto = out.File
+	file_detectorpb_detector_proto_rawDesc = nil
+	file_detectorpb_detector_proto_goTypes = nil
+	file_detectorpb_detector_proto_depIdxs = nil
+}
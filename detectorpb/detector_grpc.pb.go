@@ -0,0 +1,182 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: detectorpb/detector.proto
+
+package detectorpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// DetectorClient is the client API for Detector service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type DetectorClient interface {
+	// Detect runs a model over a single image. The call deadline bounds both
+	// waiting for a free model and inference.
+	Detect(ctx context.Context, in *DetectRequest, opts ...grpc.CallOption) (*DetectResponse, error)
+	// DetectStream runs a model over a stream of frames, e.g. from a video.
+	// Responses are sent in request order and echo each request's frame_id.
+	DetectStream(ctx context.Context, opts ...grpc.CallOption) (Detector_DetectStreamClient, error)
+}
+
+type detectorClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDetectorClient(cc grpc.ClientConnInterface) DetectorClient {
+	return &detectorClient{cc}
+}
+
+func (c *detectorClient) Detect(ctx context.Context, in *DetectRequest, opts ...grpc.CallOption) (*DetectResponse, error) {
+	out := new(DetectResponse)
+	err := c.cc.Invoke(ctx, "/goyolov5.detector.v1.Detector/Detect", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *detectorClient) DetectStream(ctx context.Context, opts ...grpc.CallOption) (Detector_DetectStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Detector_ServiceDesc.Streams[0], "/goyolov5.detector.v1.Detector/DetectStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &detectorDetectStreamClient{stream}
+	return x, nil
+}
+
+type Detector_DetectStreamClient interface {
+	Send(*DetectRequest) error
+	Recv() (*DetectResponse, error)
+	grpc.ClientStream
+}
+
+type detectorDetectStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *detectorDetectStreamClient) Send(m *DetectRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *detectorDetectStreamClient) Recv() (*DetectResponse, error) {
+	m := new(DetectResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// DetectorServer is the server API for Detector service.
+// All implementations must embed UnimplementedDetectorServer
+// for forward compatibility
+type DetectorServer interface {
+	// Detect runs a model over a single image. The call deadline bounds both
+	// waiting for a free model and inference.
+	Detect(context.Context, *DetectRequest) (*DetectResponse, error)
+	// DetectStream runs a model over a stream of frames, e.g. from a video.
+	// Responses are sent in request order and echo each request's frame_id.
+	DetectStream(Detector_DetectStreamServer) error
+	mustEmbedUnimplementedDetectorServer()
+}
+
+// UnimplementedDetectorServer must be embedded to have forward compatible implementations.
+type UnimplementedDetectorServer struct {
+}
+
+func (UnimplementedDetectorServer) Detect(context.Context, *DetectRequest) (*DetectResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Detect not implemented")
+}
+func (UnimplementedDetectorServer) DetectStream(Detector_DetectStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method DetectStream not implemented")
+}
+func (UnimplementedDetectorServer) mustEmbedUnimplementedDetectorServer() {}
+
+// UnsafeDetectorServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DetectorServer will
+// result in compilation errors.
+type UnsafeDetectorServer interface {
+	mustEmbedUnimplementedDetectorServer()
+}
+
+func RegisterDetectorServer(s grpc.ServiceRegistrar, srv DetectorServer) {
+	s.RegisterService(&Detector_ServiceDesc, srv)
+}
+
+func _Detector_Detect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DetectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DetectorServer).Detect(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/goyolov5.detector.v1.Detector/Detect",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DetectorServer).Detect(ctx, req.(*DetectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Detector_DetectStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(DetectorServer).DetectStream(&detectorDetectStreamServer{stream})
+}
+
+type Detector_DetectStreamServer interface {
+	Send(*DetectResponse) error
+	Recv() (*DetectRequest, error)
+	grpc.ServerStream
+}
+
+type detectorDetectStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *detectorDetectStreamServer) Send(m *DetectResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *detectorDetectStreamServer) Recv() (*DetectRequest, error) {
+	m := new(DetectRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Detector_ServiceDesc is the grpc.ServiceDesc for Detector service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Detector_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "goyolov5.detector.v1.Detector",
+	HandlerType: (*DetectorServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Detect",
+			Handler:    _Detector_Detect_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "DetectStream",
+			Handler:       _Detector_DetectStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "detectorpb/detector.proto",
+}
@@ -0,0 +1,173 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+type cocoImage struct {
+	ID       int64  `json:"id"`
+	FileName string `json:"file_name"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+}
+
+type cocoAnnotation struct {
+	ID         int64      `json:"id"`
+	ImageID    int64      `json:"image_id"`
+	CategoryID int64      `json:"category_id"`
+	BBox       [4]float64 `json:"bbox"`
+	IsCrowd    int        `json:"iscrowd"`
+}
+
+type cocoCategory struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type cocoDataset struct {
+	Images      []cocoImage      `json:"images"`
+	Annotations []cocoAnnotation `json:"annotations"`
+	Categories  []cocoCategory   `json:"categories"`
+}
+
+// cocoResult is one detection in the COCO results format.
+type cocoResult struct {
+	ImageID    int64      `json:"image_id"`
+	CategoryID int64      `json:"category_id"`
+	BBox       [4]float64 `json:"bbox"`
+	Score      float64    `json:"score"`
+}
+
+// LoadCOCO reads ground truth from a COCO instances JSON file. Classes are the
+// categories in ID order, so the 91 COCO category IDs map onto the 80 classes
+// of the released YOLOv5 weights.
+func LoadCOCO(path string) (*GroundTruth, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var dataset cocoDataset
+	if err := json.NewDecoder(f).Decode(&dataset); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	sort.SliceStable(dataset.Categories, func(i, j int) bool { return dataset.Categories[i].ID < dataset.Categories[j].ID })
+	gt := &GroundTruth{}
+	classes := map[int64]int{}
+	for i, c := range dataset.Categories {
+		classes[c.ID] = i
+		gt.Names = append(gt.Names, c.Name)
+		gt.CategoryIDs = append(gt.CategoryIDs, c.ID)
+	}
+
+	images := map[int64]int{}
+	for i, img := range dataset.Images {
+		images[img.ID] = i
+		gt.Images = append(gt.Images, Image{ID: img.ID, FileName: img.FileName, Width: img.Width, Height: img.Height})
+	}
+
+	for _, a := range dataset.Annotations {
+		i, ok := images[a.ImageID]
+		if !ok {
+			return nil, fmt.Errorf("%s: annotation %d has unknown image %d", path, a.ID, a.ImageID)
+		}
+		class, ok := classes[a.CategoryID]
+		if !ok {
+			return nil, fmt.Errorf("%s: annotation %d has unknown category %d", path, a.ID, a.CategoryID)
+		}
+		gt.Images[i].Objects = append(gt.Images[i].Objects, Object{
+			ClassIndex: class,
+			Box:        Box{X: a.BBox[0], Y: a.BBox[1], W: a.BBox[2], H: a.BBox[3]},
+			Crowd:      a.IsCrowd != 0,
+		})
+	}
+
+	return gt, nil
+}
+
+// LoadCOCODetections reads detections in the COCO results format, as written
+// by WriteCOCODetections, mapping their categories to gt's classes.
+func LoadCOCODetections(path string, gt *GroundTruth) (map[int64][]Detection, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var results []cocoResult
+	if err := json.NewDecoder(f).Decode(&results); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	classes := map[int64]int{}
+	for i, id := range gt.categoryIDs() {
+		classes[id] = i
+	}
+	images := map[int64]bool{}
+	for _, img := range gt.Images {
+		images[img.ID] = true
+	}
+
+	detections := map[int64][]Detection{}
+	for i, r := range results {
+		class, ok := classes[r.CategoryID]
+		if !ok {
+			return nil, fmt.Errorf("%s: result %d has unknown category %d", path, i, r.CategoryID)
+		}
+		if !images[r.ImageID] {
+			return nil, fmt.Errorf("%s: result %d has unknown image %d", path, i, r.ImageID)
+		}
+		detections[r.ImageID] = append(detections[r.ImageID], Detection{
+			ClassIndex: class,
+			Box:        Box{X: r.BBox[0], Y: r.BBox[1], W: r.BBox[2], H: r.BBox[3]},
+			Score:      r.Score,
+		})
+	}
+	return detections, nil
+}
+
+// WriteCOCODetections writes detections in the COCO results format, which
+// pycocotools' COCO.loadRes reads.
+func WriteCOCODetections(w io.Writer, gt *GroundTruth, detections map[int64][]Detection) error {
+	ids := make([]int64, 0, len(detections))
+	for id := range detections {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	categoryIDs := gt.categoryIDs()
+	results := []cocoResult{}
+	for _, id := range ids {
+		for _, d := range detections[id] {
+			if d.ClassIndex < 0 || d.ClassIndex >= len(categoryIDs) {
+				return fmt.Errorf("detection of unknown class %d", d.ClassIndex)
+			}
+			results = append(results, cocoResult{
+				ImageID:    id,
+				CategoryID: categoryIDs[d.ClassIndex],
+				BBox:       [4]float64{d.Box.X, d.Box.Y, d.Box.W, d.Box.H},
+				Score:      d.Score,
+			})
+		}
+	}
+	return json.NewEncoder(w).Encode(results)
+}
+
+// categoryIDs are the COCO category IDs by class index, defaulting to the
+// class index itself for datasets not loaded from COCO JSON.
+func (gt *GroundTruth) categoryIDs() []int64 {
+	if gt.CategoryIDs != nil {
+		return gt.CategoryIDs
+	}
+	ids := make([]int64, len(gt.Names))
+	for i := range ids {
+		ids[i] = int64(i)
+	}
+	return ids
+}
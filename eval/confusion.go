@@ -0,0 +1,110 @@
+package eval
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// ConfusionIoU is the IoU at which ConfusionMatrix pairs a detection with an
+// object.
+const ConfusionIoU = 0.5
+
+// ConfusionMatrix counts detections by predicted and actual class, with an
+// extra background class for missed objects and detections of nothing.
+type ConfusionMatrix struct {
+	Names []string
+	// Matrix[predicted][actual]; index len(Names) is the background.
+	Matrix [][]int
+}
+
+// NewConfusionMatrix returns an empty matrix over the named classes.
+func NewConfusionMatrix(names []string) *ConfusionMatrix {
+	matrix := make([][]int, len(names)+1)
+	for i := range matrix {
+		matrix[i] = make([]int, len(names)+1)
+	}
+	return &ConfusionMatrix{Names: names, Matrix: matrix}
+}
+
+// Background is the index of the background class.
+func (c *ConfusionMatrix) Background() int {
+	return len(c.Names)
+}
+
+// Add counts one image. Detections pair with objects of any class, highest
+// IoU first, when their IoU is above ConfusionIoU. Crowd objects and
+// detections of unknown classes are skipped.
+func (c *ConfusionMatrix) Add(objects []Object, detections []Detection) {
+	var objs []Object
+	for _, o := range objects {
+		if !o.Crowd && c.known(o.ClassIndex) {
+			objs = append(objs, o)
+		}
+	}
+	var dets []Detection
+	for _, d := range detections {
+		if c.known(d.ClassIndex) {
+			dets = append(dets, d)
+		}
+	}
+
+	type pair struct {
+		det, obj int
+		iou      float64
+	}
+	var pairs []pair
+	for i, d := range dets {
+		for j, o := range objs {
+			if v := iou(d.Box, o.Box, false); v > ConfusionIoU {
+				pairs = append(pairs, pair{i, j, v})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].iou > pairs[j].iou })
+
+	detMatched := make([]bool, len(dets))
+	objMatched := make([]bool, len(objs))
+	for _, p := range pairs {
+		if detMatched[p.det] || objMatched[p.obj] {
+			continue
+		}
+		detMatched[p.det], objMatched[p.obj] = true, true
+		c.Matrix[dets[p.det].ClassIndex][objs[p.obj].ClassIndex]++
+	}
+
+	background := c.Background()
+	for i, d := range dets {
+		if !detMatched[i] {
+			c.Matrix[d.ClassIndex][background]++
+		}
+	}
+	for j, o := range objs {
+		if !objMatched[j] {
+			c.Matrix[background][o.ClassIndex]++
+		}
+	}
+}
+
+func (c *ConfusionMatrix) known(classIndex int) bool {
+	return classIndex >= 0 && classIndex < len(c.Names)
+}
+
+// WriteCSV writes the matrix with a header row and column of class names,
+// predicted classes down and actual classes across.
+func (c *ConfusionMatrix) WriteCSV(w io.Writer) error {
+	names := append(append([]string(nil), c.Names...), "background")
+
+	cw := csv.NewWriter(w)
+	cw.Write(append([]string{"predicted/actual"}, names...))
+	for i, row := range c.Matrix {
+		record := []string{names[i]}
+		for _, v := range row {
+			record = append(record, strconv.Itoa(v))
+		}
+		cw.Write(record)
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,390 @@
+// Package eval measures detection accuracy against ground truth. Average
+// precision follows COCOeval from pycocotools for the "all" area range and up
+// to 100 detections per image, so numbers are comparable with published
+// YOLOv5 results.
+package eval
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// MaxDetections is the number of highest scoring detections per image and
+// class that count towards average precision, as in COCOeval.
+const MaxDetections = 100
+
+// Box is an axis aligned box in pixels, as x, y, width, height like COCO.
+type Box struct {
+	X, Y, W, H float64
+}
+
+// Object is a ground truth object.
+type Object struct {
+	ClassIndex int
+	Box        Box
+	// Crowd objects cover a group of instances. Detections matching them are
+	// neither true nor false positives.
+	Crowd bool
+}
+
+// Image is an image with its ground truth objects.
+type Image struct {
+	ID       int64
+	FileName string
+	Width    int
+	Height   int
+	Objects  []Object
+}
+
+// GroundTruth is a labelled dataset.
+type GroundTruth struct {
+	Images []Image
+	// Names are the class names by class index.
+	Names []string
+	// CategoryIDs are the COCO category IDs by class index, for datasets
+	// loaded from COCO JSON.
+	CategoryIDs []int64
+}
+
+// Detection is a predicted box.
+type Detection struct {
+	ClassIndex int
+	Box        Box
+	Score      float64
+}
+
+// ClassReport is the accuracy for one class.
+type ClassReport struct {
+	ClassIndex int
+	Name       string
+	// GroundTruth is the number of non-crowd objects.
+	GroundTruth int
+	Detections  int
+	// AP50 and AP are the average precision at IoU 0.5 and averaged over IoU
+	// 0.5 to 0.95, or -1 when the class has no ground truth, as in COCOeval.
+	AP50 float64
+	AP   float64
+	// Precision and Recall count every detection at IoU 0.5.
+	Precision float64
+	Recall    float64
+}
+
+// Report is the accuracy of a set of detections.
+type Report struct {
+	// MAP50 and MAP are the mean AP50 and AP over classes with ground truth.
+	MAP50 float64
+	MAP   float64
+	// Precision and Recall are means over classes with ground truth.
+	Precision float64
+	Recall    float64
+	Classes   []ClassReport
+	Confusion *ConfusionMatrix
+}
+
+// linspace matches numpy.linspace, which COCOeval uses for its thresholds.
+func linspace(start, stop float64, n int) []float64 {
+	step := (stop - start) / float64(n-1)
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = float64(i)*step + start
+	}
+	values[n-1] = stop
+	return values
+}
+
+var (
+	iouThresholds    = linspace(0.5, 0.95, 10)
+	recallThresholds = linspace(0, 1, 101)
+)
+
+// spacing is numpy.spacing(1), which COCOeval adds to avoid dividing by zero.
+const spacing = 2.220446049250313e-16
+
+// imageEval is COCOeval.evaluateImg's result for one image and class.
+type imageEval struct {
+	scores []float64
+	// matched and ignored are per IoU threshold, per detection.
+	matched [][]bool
+	ignored [][]bool
+	// truths is the number of non-ignored ground truth objects.
+	truths int
+}
+
+// Evaluate compares detections, keyed by image ID, to the ground truth.
+func Evaluate(gt *GroundTruth, detections map[int64][]Detection) *Report {
+	images := append([]Image(nil), gt.Images...)
+	sort.SliceStable(images, func(i, j int) bool { return images[i].ID < images[j].ID })
+
+	report := &Report{
+		Confusion: NewConfusionMatrix(gt.Names),
+	}
+
+	var valid int
+	for k, name := range gt.Names {
+		class := ClassReport{ClassIndex: k, Name: name, AP50: -1, AP: -1}
+
+		var evals []imageEval
+		for _, img := range images {
+			var objects []Object
+			for _, o := range img.Objects {
+				if o.ClassIndex == k {
+					objects = append(objects, o)
+					if !o.Crowd {
+						class.GroundTruth++
+					}
+				}
+			}
+			var dets []Detection
+			for _, d := range detections[img.ID] {
+				if d.ClassIndex == k {
+					dets = append(dets, d)
+				}
+			}
+			if len(objects) == 0 && len(dets) == 0 {
+				continue
+			}
+			evals = append(evals, evaluateImage(objects, dets))
+		}
+
+		precision, recall, tp, fp, ok := accumulate(evals)
+		for _, e := range evals {
+			class.Detections += len(e.scores)
+		}
+		if ok {
+			class.AP50 = mean(precision[0])
+			var sum float64
+			for _, p := range precision {
+				sum += mean(p)
+			}
+			class.AP = sum / float64(len(precision))
+			class.Recall = recall
+			if tp+fp > 0 {
+				class.Precision = tp / (tp + fp)
+			}
+
+			report.MAP50 += class.AP50
+			report.MAP += class.AP
+			report.Precision += class.Precision
+			report.Recall += class.Recall
+			valid++
+		}
+		report.Classes = append(report.Classes, class)
+	}
+
+	if valid == 0 {
+		report.MAP50, report.MAP = -1, -1
+	} else {
+		report.MAP50 /= float64(valid)
+		report.MAP /= float64(valid)
+		report.Precision /= float64(valid)
+		report.Recall /= float64(valid)
+	}
+
+	for _, img := range images {
+		report.Confusion.Add(img.Objects, detections[img.ID])
+	}
+
+	return report
+}
+
+// evaluateImage matches one image's detections of a class to its objects of
+// that class at every IoU threshold, like COCOeval.evaluateImg.
+func evaluateImage(objects []Object, dets []Detection) imageEval {
+	// Crowd objects last, so a match to a regular object is preferred
+	sort.SliceStable(objects, func(i, j int) bool { return !objects[i].Crowd && objects[j].Crowd })
+	sort.SliceStable(dets, func(i, j int) bool { return dets[i].Score > dets[j].Score })
+	if len(dets) > MaxDetections {
+		dets = dets[:MaxDetections]
+	}
+
+	ious := make([][]float64, len(dets))
+	for i, d := range dets {
+		ious[i] = make([]float64, len(objects))
+		for j, o := range objects {
+			ious[i][j] = iou(d.Box, o.Box, o.Crowd)
+		}
+	}
+
+	e := imageEval{
+		scores:  make([]float64, len(dets)),
+		matched: make([][]bool, len(iouThresholds)),
+		ignored: make([][]bool, len(iouThresholds)),
+	}
+	for i, d := range dets {
+		e.scores[i] = d.Score
+	}
+	for _, o := range objects {
+		if !o.Crowd {
+			e.truths++
+		}
+	}
+
+	for t, threshold := range iouThresholds {
+		e.matched[t] = make([]bool, len(dets))
+		e.ignored[t] = make([]bool, len(dets))
+		taken := make([]bool, len(objects))
+
+		for i := range dets {
+			best := threshold
+			if best > 1-1e-10 {
+				best = 1 - 1e-10
+			}
+			m := -1
+			for j, o := range objects {
+				// Crowd objects can match any number of detections
+				if taken[j] && !o.Crowd {
+					continue
+				}
+				// Stop at the crowd objects once a regular object matched
+				if m > -1 && !objects[m].Crowd && o.Crowd {
+					break
+				}
+				if ious[i][j] < best {
+					continue
+				}
+				best = ious[i][j]
+				m = j
+			}
+			if m == -1 {
+				continue
+			}
+			e.matched[t][i] = true
+			e.ignored[t][i] = objects[m].Crowd
+			taken[m] = true
+		}
+	}
+
+	return e
+}
+
+// accumulate computes interpolated precision at every IoU and recall
+// threshold over a class's image evaluations, like COCOeval.accumulate. It
+// also returns the recall and the true and false positives at IoU 0.5, and
+// false when the class has no ground truth.
+func accumulate(evals []imageEval) ([][]float64, float64, float64, float64, bool) {
+	var truths int
+	var scores []float64
+	for _, e := range evals {
+		truths += e.truths
+		scores = append(scores, e.scores...)
+	}
+	if truths == 0 {
+		return nil, 0, 0, 0, false
+	}
+
+	// Detections of all images, highest score first, ties in image order
+	order := make([]int, len(scores))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return scores[order[i]] > scores[order[j]] })
+
+	precision := make([][]float64, len(iouThresholds))
+	var recall50, tp50, fp50 float64
+	for t := range iouThresholds {
+		var matched, ignored []bool
+		for _, e := range evals {
+			matched = append(matched, e.matched[t]...)
+			ignored = append(ignored, e.ignored[t]...)
+		}
+
+		var tp, fp float64
+		pr := make([]float64, 0, len(order))
+		rc := make([]float64, 0, len(order))
+		for _, i := range order {
+			if ignored[i] {
+				// Ignored detections repeat the previous point
+			} else if matched[i] {
+				tp++
+			} else {
+				fp++
+			}
+			rc = append(rc, tp/float64(truths))
+			pr = append(pr, tp/(fp+tp+spacing))
+		}
+		if t == 0 {
+			tp50, fp50 = tp, fp
+			if len(rc) > 0 {
+				recall50 = rc[len(rc)-1]
+			}
+		}
+
+		// Make precision monotonically decreasing
+		for i := len(pr) - 1; i > 0; i-- {
+			if pr[i] > pr[i-1] {
+				pr[i-1] = pr[i]
+			}
+		}
+
+		q := make([]float64, len(recallThresholds))
+		for r, threshold := range recallThresholds {
+			i := sort.SearchFloat64s(rc, threshold)
+			if i >= len(pr) {
+				break
+			}
+			q[r] = pr[i]
+		}
+		precision[t] = q
+	}
+
+	return precision, recall50, tp50, fp50, true
+}
+
+// iou is the intersection over union of a detection and a ground truth box,
+// or the intersection over the detection's area for crowd boxes.
+func iou(d, g Box, crowd bool) float64 {
+	w := min(d.X+d.W, g.X+g.W) - max(d.X, g.X)
+	if w <= 0 {
+		return 0
+	}
+	h := min(d.Y+d.H, g.Y+g.H) - max(d.Y, g.Y)
+	if h <= 0 {
+		return 0
+	}
+	i := w * h
+	u := d.W*d.H + g.W*g.H - i
+	if crowd {
+		u = d.W * d.H
+	}
+	return i / u
+}
+
+func min(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// WriteTable writes the report as a plain text table, one row per class with
+// ground truth or detections.
+func (r *Report) WriteTable(w io.Writer) error {
+	row := "%-20s %8s %8s %10s %10s %10s %10s\n"
+	if _, err := fmt.Fprintf(w, row, "class", "objects", "dets", "P", "R", "mAP50", "mAP50-95"); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "%-20s %8s %8s %10.4f %10.4f %10.4f %10.4f\n", "all", "", "", r.Precision, r.Recall, r.MAP50, r.MAP)
+	for _, c := range r.Classes {
+		if c.GroundTruth == 0 && c.Detections == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "%-20s %8d %8d %10.4f %10.4f %10.4f %10.4f\n", c.Name, c.GroundTruth, c.Detections, c.Precision, c.Recall, c.AP50, c.AP)
+	}
+	return nil
+}
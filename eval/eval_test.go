@@ -0,0 +1,126 @@
+package eval
+
+import (
+	"math"
+	"testing"
+)
+
+func loadFixture(t *testing.T) (*GroundTruth, map[int64][]Detection) {
+	gt, err := LoadCOCO("testdata/instances.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	detections, err := LoadCOCODetections("testdata/detections.json", gt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return gt, detections
+}
+
+func TestEvaluateMatchesCOCOeval(t *testing.T) {
+	gt, detections := loadFixture(t)
+	report := Evaluate(gt, detections)
+
+	// Expected values are COCOeval's stats[0] and stats[1] and per-category
+	// precision means for testdata, see utils/eval_reference.py
+	expect := func(name string, got, want float64) {
+		if math.Abs(got-want) > 1e-12 {
+			t.Errorf("%s: expected %.17g, got %.17g", name, want, got)
+		}
+	}
+	expect("mAP50-95", report.MAP, 0.54246579419846774)
+	expect("mAP50", report.MAP50, 0.75436115040075435)
+
+	classes := []struct {
+		name     string
+		ap50, ap float64
+	}{
+		{"person", 0.65912305516265868, 0.38424556741388444},
+		{"car", 0.60396039603960394, 0.51023102310231072},
+		{"dog", 1, 0.73292079207920791},
+		{"boat", -1, -1},
+	}
+	for i, c := range classes {
+		if report.Classes[i].Name != c.name {
+			t.Fatalf("expected class %d to be %s, got %s", i, c.name, report.Classes[i].Name)
+		}
+		expect(c.name+" AP50", report.Classes[i].AP50, c.ap50)
+		expect(c.name+" AP", report.Classes[i].AP, c.ap)
+	}
+}
+
+func TestEvaluatePerfect(t *testing.T) {
+	gt := &GroundTruth{
+		Names: []string{"a", "b"},
+		Images: []Image{
+			{ID: 1, Objects: []Object{{ClassIndex: 0, Box: Box{10, 10, 50, 50}}, {ClassIndex: 1, Box: Box{100, 100, 20, 40}}}},
+			{ID: 2, Objects: []Object{{ClassIndex: 0, Box: Box{0, 0, 10, 10}}}},
+		},
+	}
+	detections := map[int64][]Detection{
+		1: {{ClassIndex: 0, Box: Box{10, 10, 50, 50}, Score: 0.9}, {ClassIndex: 1, Box: Box{100, 100, 20, 40}, Score: 0.8}},
+		2: {{ClassIndex: 0, Box: Box{0, 0, 10, 10}, Score: 0.7}, {ClassIndex: 1, Box: Box{200, 200, 10, 10}, Score: 0.6}},
+	}
+
+	report := Evaluate(gt, detections)
+	// Precision is tp/(tp+fp+eps), as in COCOeval
+	if math.Abs(report.MAP-1) > 1e-12 || math.Abs(report.MAP50-1) > 1e-12 {
+		t.Fatalf("expected perfect AP despite a low scoring false positive, got %f %f", report.MAP50, report.MAP)
+	}
+	b := report.Classes[1]
+	if b.GroundTruth != 1 || b.Detections != 2 || b.Precision != 0.5 || b.Recall != 1 {
+		t.Fatalf("unexpected class report %+v", b)
+	}
+
+	c := report.Confusion.Matrix
+	bg := report.Confusion.Background()
+	if c[0][0] != 2 || c[1][1] != 1 || c[1][bg] != 1 || c[bg][0] != 0 {
+		t.Fatalf("unexpected confusion matrix %v", c)
+	}
+}
+
+func TestConfusionMatrix(t *testing.T) {
+	gt, detections := loadFixture(t)
+	report := Evaluate(gt, detections)
+
+	c := report.Confusion
+	dog, car := 2, 1
+	if c.Matrix[car][dog] != 1 {
+		t.Fatalf("expected the dog detected as a car, got %v", c.Matrix)
+	}
+
+	// Every non-crowd object is counted once, in its actual class column
+	objects := 0
+	for _, img := range gt.Images {
+		for _, o := range img.Objects {
+			if !o.Crowd {
+				objects++
+			}
+		}
+	}
+	counted := 0
+	for _, row := range c.Matrix {
+		for actual, v := range row {
+			if actual != c.Background() {
+				counted += v
+			}
+		}
+	}
+	if counted != objects {
+		t.Fatalf("expected %d objects in the matrix, got %d", objects, counted)
+	}
+}
+
+func TestIoU(t *testing.T) {
+	d := Box{0, 0, 10, 10}
+	if v := iou(d, Box{5, 0, 10, 10}, false); math.Abs(v-50.0/150) > 1e-12 {
+		t.Fatalf("unexpected IoU %f", v)
+	}
+	// Crowd boxes divide by the detection's area
+	if v := iou(d, Box{5, 0, 100, 100}, true); v != 0.5 {
+		t.Fatalf("unexpected crowd IoU %f", v)
+	}
+	if v := iou(d, Box{10, 0, 10, 10}, false); v != 0 {
+		t.Fatalf("expected touching boxes not to overlap, got %f", v)
+	}
+}
@@ -0,0 +1,61 @@
+package eval
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+
+	"github.com/danhilltech/goyolov5"
+)
+
+// FromPredictions converts predictions to detections, scored by objectness
+// times class confidence like YOLOv5 val.
+func FromPredictions(predictions []goyolov5.Prediction) []Detection {
+	detections := make([]Detection, len(predictions))
+	for i, p := range predictions {
+		detections[i] = Detection{
+			ClassIndex: int(p.ClassIndex),
+			Box:        Box{X: float64(p.Rect.Min.X), Y: float64(p.Rect.Min.Y), W: float64(p.Rect.Dx()), H: float64(p.Rect.Dy())},
+			Score:      p.Confidence * p.ClassConfidence,
+		}
+	}
+	return detections
+}
+
+// Run runs yolov5 over every image of gt, read from imageDir by file name,
+// or by file name alone when imageDir is empty, and returns the detections
+// keyed by image ID. The model's class indices must match gt's. Use a low
+// confidence threshold, such as 0.001, for average precision comparable with
+// published results.
+func Run(yolov5 *goyolov5.YoloV5, gt *GroundTruth, imageDir string, confidenceThreshold float32, nmsThreshold float64, opts ...goyolov5.InferOption) (map[int64][]Detection, error) {
+	detections := map[int64][]Detection{}
+	for _, img := range gt.Images {
+		path := filepath.Join(imageDir, img.FileName)
+		tensor, err := readImage(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		predictions, err := yolov5.Infer(tensor, confidenceThreshold, nmsThreshold, nil, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		detections[img.ID] = FromPredictions(predictions[0])
+	}
+	return detections, nil
+}
+
+func readImage(path string) (*goyolov5.Tensor, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, err
+	}
+	return goyolov5.NewTensorFromImage(img), nil
+}
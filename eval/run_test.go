@@ -0,0 +1,29 @@
+package eval
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"github.com/danhilltech/goyolov5"
+)
+
+func TestFromPredictionsScore(t *testing.T) {
+	gt := &GroundTruth{
+		Names:  []string{"a"},
+		Images: []Image{{ID: 1, Objects: []Object{{ClassIndex: 0, Box: Box{10, 10, 40, 40}}}}},
+	}
+
+	// Equal objectness, so only the class confidence ranks the true positive
+	// above the false positive listed first
+	detections := map[int64][]Detection{1: FromPredictions([]goyolov5.Prediction{
+		{ClassIndex: 0, Rect: image.Rect(100, 100, 140, 140), Confidence: 0.9, ClassConfidence: 0.3},
+		{ClassIndex: 0, Rect: image.Rect(10, 10, 50, 50), Confidence: 0.9, ClassConfidence: 0.9},
+	})}
+	if d := detections[1]; d[0].Score >= d[1].Score {
+		t.Fatalf("expected scores of objectness times class confidence, got %v and %v", d[0].Score, d[1].Score)
+	}
+	if report := Evaluate(gt, detections); math.Abs(report.MAP50-1) > 1e-9 {
+		t.Errorf("expected the true positive ranked first for an mAP50 of 1, got %v", report.MAP50)
+	}
+}
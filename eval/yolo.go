@@ -0,0 +1,106 @@
+package eval
+
+import (
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/danhilltech/goyolov5/dataset"
+)
+
+// LoadYOLO reads ground truth in YOLO txt format. Every JPEG or PNG under
+// imagesDir is an image, labelled by the .txt file at the same relative path
+// under labelsDir with one "class x_center y_center width height" line per
+// object, normalised by the image size. Images without a label file have no
+// objects. Image IDs count from 1 in file name order. For datasets with a
+// data.yaml, use LoadDataset.
+func LoadYOLO(imagesDir, labelsDir string, names []string) (*GroundTruth, error) {
+	var files []string
+	err := filepath.Walk(imagesDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".jpg", ".jpeg", ".png":
+			if !info.IsDir() {
+				files = append(files, path)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	gt := &GroundTruth{Names: names}
+	for i, path := range files {
+		rel, err := filepath.Rel(imagesDir, path)
+		if err != nil {
+			return nil, err
+		}
+		width, height, err := imageSize(path)
+		if err != nil {
+			return nil, err
+		}
+
+		labels, err := dataset.ReadLabels(filepath.Join(labelsDir, strings.TrimSuffix(rel, filepath.Ext(rel))+".txt"), dataset.Format{Classes: len(names)})
+		if err != nil {
+			return nil, err
+		}
+		gt.Images = append(gt.Images, Image{ID: int64(i + 1), FileName: rel, Width: width, Height: height, Objects: objects(labels, width, height)})
+	}
+	return gt, nil
+}
+
+// LoadDataset reads the ground truth of a dataset split. Image IDs count from
+// 1 in the split's order and file names are the image paths, so Run takes an
+// empty image directory.
+func LoadDataset(split *dataset.Split) (*GroundTruth, error) {
+	gt := &GroundTruth{Names: split.Names}
+	for i, path := range split.Images {
+		width, height, err := imageSize(path)
+		if err != nil {
+			return nil, err
+		}
+		labels, err := split.Labels(i)
+		if err != nil {
+			return nil, err
+		}
+		gt.Images = append(gt.Images, Image{ID: int64(i + 1), FileName: path, Width: width, Height: height, Objects: objects(labels, width, height)})
+	}
+	return gt, nil
+}
+
+func imageSize(path string) (int, int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	config, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", path, err)
+	}
+	return config.Width, config.Height, nil
+}
+
+// objects converts labels to objects in pixels of an image of the given
+// size.
+func objects(labels []dataset.Label, width, height int) []Object {
+	objects := make([]Object, len(labels))
+	for i, l := range labels {
+		w, h := l.W*float64(width), l.H*float64(height)
+		objects[i] = Object{
+			ClassIndex: l.ClassIndex,
+			Box:        Box{X: l.X*float64(width) - w/2, Y: l.Y*float64(height) - h/2, W: w, H: h},
+		}
+	}
+	return objects
+}
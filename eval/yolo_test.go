@@ -0,0 +1,80 @@
+package eval
+
+import (
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/danhilltech/goyolov5/dataset"
+)
+
+func writeYOLODataset(t *testing.T) string {
+	dir := t.TempDir()
+	for _, name := range []string{"images/val/a.png", "images/val/b.png"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := png.Encode(f, image.NewRGBA(image.Rect(0, 0, 200, 100))); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "labels/val"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "labels/val/a.txt"), []byte("1 0.5 0.5 0.5 0.2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "data.yaml"), []byte("val: images/val\nnames: [person, car]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestLoadYOLO(t *testing.T) {
+	dir := writeYOLODataset(t)
+
+	gt, err := LoadYOLO(filepath.Join(dir, "images/val"), filepath.Join(dir, "labels/val"), []string{"person", "car"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Image{
+		{ID: 1, FileName: "a.png", Width: 200, Height: 100, Objects: []Object{{ClassIndex: 1, Box: Box{X: 50, Y: 40, W: 100, H: 20}}}},
+		{ID: 2, FileName: "b.png", Width: 200, Height: 100, Objects: []Object{}},
+	}
+	if !reflect.DeepEqual(gt.Images, expected) {
+		t.Errorf("expected %+v, got %+v", expected, gt.Images)
+	}
+}
+
+func TestLoadDataset(t *testing.T) {
+	dir := writeYOLODataset(t)
+
+	config, err := dataset.LoadConfig(filepath.Join(dir, "data.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	split, err := config.Split("val")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gt, err := LoadDataset(split)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(gt.Images) != 2 || gt.Images[0].FileName != filepath.Join(dir, "images/val/a.png") || len(gt.Images[0].Objects) != 1 {
+		t.Errorf("unexpected images %+v", gt.Images)
+	}
+	if !reflect.DeepEqual(gt.Names, []string{"person", "car"}) {
+		t.Errorf("unexpected names %v", gt.Names)
+	}
+}
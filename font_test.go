@@ -0,0 +1,43 @@
+package goyolov5
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestDrawText(t *testing.T) {
+	img := NewTensor(image.Rect(0, 0, 40, 20))
+	white := color.RGBA{255, 255, 255, 255}
+	img.DrawText(image.Pt(2, 3), "T1", 2, white)
+
+	// T's top bar is the first row of its 5 columns, magnified twice
+	for x := 2; x < 12; x++ {
+		if img.RGBAAt(x, 3) != white || img.RGBAAt(x, 4) != white {
+			t.Errorf("expected the top of T at %d", x)
+		}
+	}
+	if img.RGBAAt(2, 5) == white {
+		t.Error("expected T's bar to be 2 pixels high")
+	}
+	// T's stem is its middle column, down to the last row
+	if img.RGBAAt(6, 3+13) != white || img.RGBAAt(6, 3+14) == white {
+		t.Error("expected T's stem to end at row 7")
+	}
+	// 1 starts after 6 columns of advance
+	if img.RGBAAt(2+6*2+2, 3+13) != white {
+		t.Error("expected the base of 1")
+	}
+
+	// Text off the image and unknown characters are safe
+	img.DrawText(image.Pt(35, 15), "é\x00~", 3, white)
+}
+
+func TestTextSize(t *testing.T) {
+	if s := TextSize("", 2); s != (image.Point{}) {
+		t.Errorf("expected no size, got %v", s)
+	}
+	if s := TextSize("ab", 2); s != image.Pt(22, 14) {
+		t.Errorf("expected 22x14, got %v", s)
+	}
+}
@@ -0,0 +1,84 @@
+package goyolov5
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"io"
+)
+
+// COCONames are the class names of the 80 COCO classes the released YOLOv5
+// weights are trained on, by class index.
+var COCONames = []string{
+	"person", "bicycle", "car", "motorcycle", "airplane", "bus", "train", "truck", "boat", "traffic light",
+	"fire hydrant", "stop sign", "parking meter", "bench", "bird", "cat", "dog", "horse", "sheep", "cow",
+	"elephant", "bear", "zebra", "giraffe", "backpack", "umbrella", "handbag", "tie", "suitcase", "frisbee",
+	"skis", "snowboard", "sports ball", "kite", "baseball bat", "baseball glove", "skateboard", "surfboard", "tennis racket", "bottle",
+	"wine glass", "cup", "fork", "knife", "spoon", "bowl", "banana", "apple", "sandwich", "orange",
+	"broccoli", "carrot", "hot dog", "pizza", "donut", "cake", "chair", "couch", "potted plant", "bed",
+	"dining table", "toilet", "tv", "laptop", "mouse", "remote", "keyboard", "cell phone", "microwave", "oven",
+	"toaster", "sink", "refrigerator", "book", "clock", "vase", "scissors", "teddy bear", "hair drier", "toothbrush",
+}
+
+// Detection is a flat view of a Prediction for JSON encoding.
+type Detection struct {
+	Class           string  `json:"class,omitempty"`
+	ClassIndex      uint    `json:"class_index"`
+	Confidence      float64 `json:"confidence"`
+	ClassConfidence float64 `json:"class_confidence"`
+	// Box is x0, y0, x1, y1 in image coordinates.
+	Box       [4]int       `json:"box"`
+	Keypoints [][3]float64 `json:"keypoints,omitempty"`
+	// Classifications are a Cascade's second-stage classes.
+	Classifications []Classification `json:"classifications,omitempty"`
+}
+
+// NewDetections converts predictions to detections, naming each class from
+// names when it has an entry for it.
+func NewDetections(predictions []Prediction, names []string) []Detection {
+	detections := make([]Detection, len(predictions))
+	for i, p := range predictions {
+		d := Detection{
+			ClassIndex:      p.ClassIndex,
+			Confidence:      p.Confidence,
+			ClassConfidence: p.ClassConfidence,
+			Box:             [4]int{p.Rect.Min.X, p.Rect.Min.Y, p.Rect.Max.X, p.Rect.Max.Y},
+			Classifications: p.Classifications,
+		}
+		if int(p.ClassIndex) < len(names) {
+			d.Class = names[p.ClassIndex]
+		}
+		for _, k := range p.Keypoints {
+			d.Keypoints = append(d.Keypoints, [3]float64{float64(k.Point.X), float64(k.Point.Y), k.Visibility})
+		}
+		detections[i] = d
+	}
+	return detections
+}
+
+// WriteYOLOLabels writes predictions on a width x height image in YOLO txt
+// label format: one "class x_center y_center width height" line per
+// prediction, clipped to the image and normalised by its size. With
+// confidence, each line ends with the prediction's confidence.
+func WriteYOLOLabels(w io.Writer, predictions []Prediction, width, height int, confidence bool) error {
+	bw := bufio.NewWriter(w)
+	bounds := image.Rect(0, 0, width, height)
+	for _, p := range predictions {
+		r := p.Rect.Intersect(bounds)
+		if r.Empty() {
+			continue
+		}
+		fmt.Fprintf(bw, "%d %.6f %.6f %.6f %.6f",
+			p.ClassIndex,
+			float64(r.Min.X+r.Max.X)/2/float64(width),
+			float64(r.Min.Y+r.Max.Y)/2/float64(height),
+			float64(r.Dx())/float64(width),
+			float64(r.Dy())/float64(height),
+		)
+		if confidence {
+			fmt.Fprintf(bw, " %.6f", p.Confidence)
+		}
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,48 @@
+package goyolov5
+
+import (
+	"bytes"
+	"image"
+	"testing"
+)
+
+func TestNewDetections(t *testing.T) {
+	detections := NewDetections([]Prediction{
+		{Rect: image.Rect(1, 2, 3, 4), Confidence: 0.9, ClassIndex: 2, ClassConfidence: 0.8},
+		{Rect: image.Rect(1, 2, 3, 4), ClassIndex: 100, Keypoints: []Keypoint{{Point: image.Pt(5, 6), Visibility: 0.5}}},
+	}, COCONames)
+
+	if detections[0].Class != "car" || detections[0].Box != [4]int{1, 2, 3, 4} {
+		t.Fatalf("unexpected detection %+v", detections[0])
+	}
+	if detections[1].Class != "" || detections[1].Keypoints[0] != [3]float64{5, 6, 0.5} {
+		t.Fatalf("unexpected detection %+v", detections[1])
+	}
+}
+
+func TestWriteYOLOLabels(t *testing.T) {
+	predictions := []Prediction{
+		{Rect: image.Rect(100, 50, 300, 150), Confidence: 0.75, ClassIndex: 2},
+		// Clipped to the image
+		{Rect: image.Rect(-100, 0, 100, 200), Confidence: 0.5, ClassIndex: 0},
+		// Entirely outside the image
+		{Rect: image.Rect(500, 500, 600, 600), Confidence: 0.5, ClassIndex: 0},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteYOLOLabels(&buf, predictions, 400, 200, false); err != nil {
+		t.Fatal(err)
+	}
+	expected := "2 0.500000 0.500000 0.500000 0.500000\n0 0.125000 0.500000 0.250000 1.000000\n"
+	if buf.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, buf.String())
+	}
+
+	buf.Reset()
+	if err := WriteYOLOLabels(&buf, predictions[:1], 400, 200, true); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "2 0.500000 0.500000 0.500000 0.500000 0.750000\n" {
+		t.Fatalf("unexpected labels %q", buf.String())
+	}
+}
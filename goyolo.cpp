@@ -1,3 +1,5 @@
+//go:build cgo && !nolibtorch
+
 #include <torch/torch.h>
 #include <torch/script.h>
 #include <stdexcept>
@@ -35,11 +37,16 @@ tensor at_new_tensor()
     return nullptr;
 }
 
-module atm_load_on_device(char *filename, int device)
+module atm_load_on_device(char *filename, int device, char **config)
 {
     PROTECT(
-        return new torch::jit::script::Module(
-                   torch::jit::load(filename, device_of_int(device)));)
+        torch::jit::ExtraFilesMap extra_files{{"config.txt", ""}};
+        auto m = new torch::jit::script::Module(
+            torch::jit::load(filename, device_of_int(device), extra_files));
+        // YOLOv5's export.py stores the input shape, stride and class names here
+        if (!extra_files["config.txt"].empty())
+            *config = strdup(extra_files["config.txt"].c_str());
+        return m;)
     return nullptr;
 }
 
@@ -56,12 +63,12 @@ void init_module_half(module m)
         m->eval();)
 }
 
-void infer(module m, int device, void *data, int size, int half, void *dst)
+tensor infer(module m, int device, void *data, int batch, int size, int half, float *mean, float *std)
 {
     torch::NoGradGuard no_grad;
 
     PROTECT(
-        auto tensor_img = torch::from_blob(data, {1, size, size, 3}, torch::kByte).to(device_of_int(device));
+        auto tensor_img = torch::from_blob(data, {batch, size, size, 3}, torch::kByte).to(device_of_int(device));
         tensor_img = tensor_img.permute({0, 3, 1, 2}).contiguous(); // BHWC -> BCHW (Batch, Channel, Height, Width)
 
         if (half == 0)
@@ -71,18 +78,26 @@ void infer(module m, int device, void *data, int size, int half, void *dst)
         {
             tensor_img = tensor_img.to(torch::kFloat16).div(255);
         }
+        // Classification models expect per-channel normalised inputs
+        if (mean != nullptr && std != nullptr)
+        {
+            auto options = torch::TensorOptions().dtype(tensor_img.dtype()).device(tensor_img.device());
+            tensor_img = tensor_img.sub(torch::tensor({mean[0], mean[1], mean[2]}).view({1, 3, 1, 1}).to(options))
+                             .div(torch::tensor({std[0], std[1], std[2]}).view({1, 3, 1, 1}).to(options));
+        }
 
         std::vector<torch::jit::IValue>
             inputs;
         inputs.emplace_back(tensor_img);
         torch::jit::IValue output = m->forward(inputs);
 
-        auto detections = output.toTuple()->elements()[0].toTensor().to(torch::kFloat32).cpu().contiguous();
+        // Detection models return a tuple led by the predictions, classifiers a
+        // tensor of logits
+        auto result = output.isTuple() ? output.toTuple()->elements()[0].toTensor() : output.toTensor();
+        auto detections = result.to(torch::kFloat32).cpu().contiguous();
 
-        memcpy(dst, detections.data_ptr(), detections.numel() * detections.element_size());
-
-        return;)
-    return;
+        return new torch::Tensor(detections);)
+    return nullptr;
 }
 
 struct Rect
@@ -155,4 +170,13 @@ tensor at_get(tensor t, int index)
     return nullptr;
 }
 
+void at_copy_data(tensor t, void *dst, size_t numel)
+{
+    PROTECT(
+        if (t->numel() != (int64_t)numel)
+            throw std::runtime_error("unexpected tensor size " + std::to_string(t->numel()));
+
+        memcpy(dst, t->data_ptr(), numel * t->element_size());)
+}
+
 void at_free(tensor t) { delete (t); }
@@ -1,11 +1,13 @@
 package goyolov5
 
 import (
+	"fmt"
 	"image"
 	"image/color"
 	"math"
 	"path/filepath"
 	"sort"
+	"time"
 )
 
 type DeviceType = int32
@@ -27,11 +29,13 @@ const (
 )
 
 type YoloV5 struct {
-	model     Cmodule
-	device    DeviceType
-	size      int
-	modelName string
-	half      bool
+	backend    InferenceBackend
+	size       int
+	modelName  string
+	nClasses   int
+	nKeypoints int
+	names      []string
+	stride     int
 }
 
 type Bbox struct {
@@ -42,6 +46,8 @@ type Bbox struct {
 	confidence      float64
 	classIndex      uint
 	classConfidence float64
+	// keypoints holds the raw x, y, visibility triplets for keypoint-head models
+	keypoints []float32
 }
 
 type Prediction struct {
@@ -49,6 +55,10 @@ type Prediction struct {
 	Confidence      float64
 	ClassIndex      uint
 	ClassConfidence float64
+	Keypoints       []Keypoint
+	// Classifications are a second-stage classifier's classes for the
+	// prediction's crop, most likely first, as set by a Cascade.
+	Classifications []Classification
 }
 
 type ByConfBbox []Bbox
@@ -83,28 +93,63 @@ func DeviceCudaIfAvailable() DeviceType {
 }
 
 func NewYoloV5(path string, device DeviceType, size int, half bool) (*YoloV5, error) {
+	return newYoloV5(path, device, size, half, NClasses, 0)
+}
 
-	module := atmLoadOnDevice(path, device)
-	if err := TorchErr(); err != nil {
-		return nil, err
-	}
+// NewYoloV5Pose loads a keypoint-head model, e.g. a person pose model with
+// nClasses = 1 and nKeypoints = NKeypointsCOCO.
+func NewYoloV5Pose(path string, device DeviceType, size int, half bool, nClasses, nKeypoints int) (*YoloV5, error) {
+	return newYoloV5(path, device, size, half, nClasses, nKeypoints)
+}
 
-	atmInitModule(module, half)
-	if err := TorchErr(); err != nil {
+func newYoloV5(path string, device DeviceType, size int, half bool, nClasses, nKeypoints int) (*YoloV5, error) {
+	backend, config, err := newTorchBackend(path, device, half)
+	if err != nil {
 		return nil, err
 	}
 
-	yolov5 := &YoloV5{
-		model:     module,
-		device:    device,
-		size:      size,
-		modelName: filepath.Base(path),
-		half:      half,
+	yolov5 := NewYoloV5WithBackend(backend, size, nClasses, nKeypoints)
+	yolov5.modelName = filepath.Base(path)
+	if config != "" {
+		if err := yolov5.applyConfig(config); err != nil {
+			return nil, fmt.Errorf("reading %s config.txt: %w", yolov5.modelName, err)
+		}
 	}
 
 	return yolov5, nil
 }
 
+// NewYoloV5WithBackend wraps an already constructed InferenceBackend, such as a
+// FakeBackend in tests. nKeypoints is 0 for plain detection models.
+func NewYoloV5WithBackend(backend InferenceBackend, size int, nClasses, nKeypoints int) *YoloV5 {
+	return &YoloV5{
+		backend:    backend,
+		size:       size,
+		nClasses:   nClasses,
+		nKeypoints: nKeypoints,
+	}
+}
+
+// predSize is the number of values the model emits per candidate box.
+func (yolov5 *YoloV5) predSize() int {
+	return ClassesOffset + yolov5.nClasses + 3*yolov5.nKeypoints
+}
+
+// outputShape checks a raw backend output against the model layout and returns
+// the batch size and the number of candidate boxes per image.
+func (yolov5 *YoloV5) outputShape(output []float32, shape []int) (int, int, error) {
+	if len(shape) != 3 {
+		return 0, 0, fmt.Errorf("unexpected output shape %v", shape)
+	}
+	if shape[2] != yolov5.predSize() {
+		return 0, 0, fmt.Errorf("expected %d values per prediction, got %d", yolov5.predSize(), shape[2])
+	}
+	if len(output) < shape[0]*shape[1]*shape[2] {
+		return 0, 0, fmt.Errorf("output has %d values, shape %v needs %d", len(output), shape, shape[0]*shape[1]*shape[2])
+	}
+	return shape[0], shape[1], nil
+}
+
 func (yolov5 *YoloV5) preProcess(inputTensorRaw *Tensor) (*Tensor, int, int, float64, error) {
 	inputTensorSquare, extraX, extraY, err := inputTensorRaw.ToSquareShape()
 	if err != nil {
@@ -118,12 +163,14 @@ func (yolov5 *YoloV5) preProcess(inputTensorRaw *Tensor) (*Tensor, int, int, flo
 	return inputTensor, extraX, extraY, scaleRatio, nil
 }
 
-func (yolov5 *YoloV5) postConfidence(tensor []float32, nclasses, npreds int, confidenceThreshold float32) ([][]Bbox, error) {
+func (yolov5 *YoloV5) postConfidence(tensor []float32, nclasses, nkeypoints, npreds int, confidenceThreshold float32) ([][]Bbox, error) {
 	var bboxes [][]Bbox = make([][]Bbox, int(nclasses))
 
+	predSize := nclasses + ClassesOffset + 3*nkeypoints
+
 	for index := 0; index < int(npreds); index++ {
 
-		predVals := tensor[index*(nclasses+ClassesOffset) : (index+1)*(nclasses+ClassesOffset)]
+		predVals := tensor[index*predSize : (index+1)*predSize]
 
 		if predVals[4] > confidenceThreshold {
 			classIndex := 0
@@ -143,6 +190,9 @@ func (yolov5 *YoloV5) postConfidence(tensor []float32, nclasses, npreds int, con
 					classIndex:      uint(classIndex),
 					classConfidence: float64(predVals[5+classIndex]),
 				}
+				if nkeypoints > 0 {
+					bbox.keypoints = predVals[ClassesOffset+nclasses:]
+				}
 
 				bboxes[classIndex] = append(bboxes[classIndex], bbox)
 			}
@@ -156,8 +206,8 @@ func (yolov5 *YoloV5) postNMS(bboxes [][]Bbox, nmsThreshold float64) ([][]Bbox,
 	// Perform non-maximum suppression.
 	var bboxesRes [][]Bbox
 	for _, bboxesForClass := range bboxes {
-		// 1. Sort by confidence
-		sort.Sort(ByConfBbox(bboxesForClass))
+		// 1. Sort by confidence, highest first
+		sort.Sort(sort.Reverse(ByConfBbox(bboxesForClass)))
 
 		// 2.
 		var currentIndex = 0
@@ -179,7 +229,7 @@ func (yolov5 *YoloV5) postNMS(bboxes [][]Bbox, nmsThreshold float64) ([][]Bbox,
 		}
 		// 3. Truncate at currentIndex (exclusive)
 		if currentIndex < len(bboxesForClass) {
-			bboxesForClass = append(bboxesForClass[:currentIndex])
+			bboxesForClass = bboxesForClass[:currentIndex]
 		}
 
 		bboxesRes = append(bboxesRes, bboxesForClass)
@@ -188,30 +238,125 @@ func (yolov5 *YoloV5) postNMS(bboxes [][]Bbox, nmsThreshold float64) ([][]Bbox,
 	return bboxesRes, nil
 }
 
-func (yolov5 *YoloV5) Infer(inputTensorRaw *Tensor, confidenceThreshold float32, nmsThreshold float64, annotateTensor *Tensor) ([][]Prediction, error) {
-
-	// Preprocess
-	inputTensor, _, _, scaleRatio, err := yolov5.preProcess(inputTensorRaw)
+// postProcess decodes a raw backend output into per-image, per-class boxes
+// above confidenceThreshold, in model input coordinates.
+func (yolov5 *YoloV5) postProcess(rawOutput []float32, shape []int, confidenceThreshold float32) ([][][]Bbox, error) {
+	batchSize, npreds, err := yolov5.outputShape(rawOutput, shape)
 	if err != nil {
 		return nil, err
 	}
 
-	// Infer
-	rawOutput, batchSize, err := yolov5.atRunInfer(inputTensor)
+	predSize := yolov5.predSize()
+	batchBboxes := make([][][]Bbox, batchSize)
+
+	for batch := 0; batch < batchSize; batch++ {
+		tensorBatch := rawOutput[batch*npreds*predSize : (batch+1)*npreds*predSize]
+
+		bboxes, err := yolov5.postConfidence(tensorBatch, yolov5.nClasses, yolov5.nKeypoints, npreds, confidenceThreshold)
+		if err != nil {
+			return nil, err
+		}
+		batchBboxes[batch] = bboxes
+	}
+
+	return batchBboxes, nil
+}
+
+// transform returns a copy of the box, keypoints included, scaled by scale and
+// then shifted by (dx, dy).
+func (b Bbox) transform(scale, dx, dy float64) Bbox {
+	b.xmin = b.xmin*scale + dx
+	b.ymin = b.ymin*scale + dy
+	b.xmax = b.xmax*scale + dx
+	b.ymax = b.ymax*scale + dy
+
+	if len(b.keypoints) > 0 {
+		keypoints := make([]float32, len(b.keypoints))
+		for i := 0; i+2 < len(keypoints); i += 3 {
+			keypoints[i] = float32(float64(b.keypoints[i])*scale + dx)
+			keypoints[i+1] = float32(float64(b.keypoints[i+1])*scale + dy)
+			keypoints[i+2] = b.keypoints[i+2]
+		}
+		b.keypoints = keypoints
+	}
+	return b
+}
+
+// toPrediction converts a box to a Prediction, scaling it by scaleRatio and
+// shifting it to origin, the top left of the input image.
+func (b Bbox) toPrediction(scaleRatio float64, origin image.Point) Prediction {
+	keypoints := scaleKeypoints(b.keypoints, scaleRatio)
+	for i := range keypoints {
+		keypoints[i].Point = keypoints[i].Point.Add(origin)
+	}
+	return Prediction{
+		Confidence:      b.confidence,
+		ClassIndex:      b.classIndex,
+		ClassConfidence: b.classConfidence,
+		Rect:            image.Rect(int(b.xmin*scaleRatio), int(b.ymin*scaleRatio), int(b.xmax*scaleRatio), int(b.ymax*scaleRatio)).Add(origin),
+		Keypoints:       keypoints,
+	}
+}
+
+func (yolov5 *YoloV5) Infer(inputTensorRaw *Tensor, confidenceThreshold float32, nmsThreshold float64, annotateTensor *Tensor, opts ...InferOption) ([][]Prediction, error) {
+	options := newInferOptions(opts)
+	timings := options.timings
+	if timings == nil {
+		timings = &Timings{}
+	}
+
+	// Preprocess
+	start := time.Now()
+	inputTensor, _, _, scaleRatio, err := yolov5.preProcess(inputTensorRaw)
 	if err != nil {
 		return nil, err
 	}
+	timings.Preprocess = time.Since(start)
 
-	var outputPredictions [][]Prediction = make([][]Prediction, batchSize)
-
-	for batch := 0; batch < batchSize; batch++ {
+	return yolov5.inferLetterboxed(inputTensor, scaleRatio, inputTensorRaw.Rect.Min, confidenceThreshold, nmsThreshold, annotateTensor, options, timings)
+}
 
-		tensorBatch := rawOutput[batch*NPreds : (batch+1)*NPreds]
+// inferLetterboxed runs the forward pass and post-processing of Infer on a
+// letterboxed model input, scaling predictions by scaleRatio and shifting
+// them to origin.
+func (yolov5 *YoloV5) inferLetterboxed(inputTensor *Tensor, scaleRatio float64, origin image.Point, confidenceThreshold float32, nmsThreshold float64, annotateTensor *Tensor, options inferOptions, timings *Timings) ([][]Prediction, error) {
+	// Infer
+	start := time.Now()
+	var batchBboxes [][][]Bbox
+	if len(options.augmentations) > 0 {
+		bboxes, err := yolov5.augmentedForward(options.ctx, inputTensor, options.augmentations, confidenceThreshold)
+		if err != nil {
+			return nil, err
+		}
+		batchBboxes = [][][]Bbox{bboxes}
+		timings.Forward = time.Since(start)
+		start = time.Now()
+	} else {
+		if err := options.ctx.Err(); err != nil {
+			return nil, err
+		}
+		rawOutput, shape, err := yolov5.backend.Forward(inputTensor)
+		if err != nil {
+			return nil, err
+		}
+		timings.Forward = time.Since(start)
 
-		bboxes, err := yolov5.postConfidence(tensorBatch, NClasses, NPreds, confidenceThreshold)
+		start = time.Now()
+		batchBboxes, err = yolov5.postProcess(rawOutput, shape, confidenceThreshold)
 		if err != nil {
 			return nil, err
 		}
+	}
+
+	if err := options.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	batchSize := len(batchBboxes)
+	var outputPredictions [][]Prediction = make([][]Prediction, batchSize)
+
+	for batch, bboxes := range batchBboxes {
+		options.filterClasses(bboxes)
 
 		bboxesRes, err := yolov5.postNMS(bboxes, nmsThreshold)
 		if err != nil {
@@ -219,27 +364,31 @@ func (yolov5 *YoloV5) Infer(inputTensorRaw *Tensor, confidenceThreshold float32,
 		}
 
 		// Add to output
-		// TODO Simplify this
 		for _, c := range bboxesRes {
 			for _, pred := range c {
 
-				newPred := Prediction{
-					Confidence:      pred.confidence,
-					ClassIndex:      pred.classIndex,
-					ClassConfidence: pred.classConfidence,
-					Rect:            image.Rect(int(pred.xmin*scaleRatio), int(pred.ymin*scaleRatio), int(pred.xmax*scaleRatio), int(pred.ymax*scaleRatio)),
-				}
-				outputPredictions[batch] = append(outputPredictions[batch], newPred)
-
-				// Draw on it
-				if annotateTensor != nil && batchSize == 1 {
-					annotateTensor.DrawRect(newPred.Rect, color.RGBA{0, 255, 0, 255})
-
-				}
+				outputPredictions[batch] = append(outputPredictions[batch], pred.toPrediction(scaleRatio, origin))
 			}
 		}
 
 	}
 
+	// Draw on it. The input is a single image, so further batch entries of
+	// models exported with a fixed batch size are padding.
+	if annotateTensor != nil && batchSize > 0 {
+		options.annotate(annotateTensor, outputPredictions[0])
+	}
+
+	timings.Postprocess = time.Since(start)
+
 	return outputPredictions, nil
 }
+
+// annotatePrediction draws a prediction's box, and its skeleton for COCO
+// keypoint models.
+func annotatePrediction(annotateTensor *Tensor, pred Prediction) {
+	annotateTensor.DrawRect(pred.Rect, color.RGBA{0, 255, 0, 255})
+	if len(pred.Keypoints) == NKeypointsCOCO {
+		annotateTensor.DrawSkeleton(pred.Keypoints, COCOSkeleton, 0.5, color.RGBA{255, 0, 0, 255})
+	}
+}
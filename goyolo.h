@@ -25,14 +25,15 @@ typedef void *module;
 
     char *get_and_reset_last_err();
     tensor at_new_tensor();
-    module atm_load_on_device(char *, int device);
+    module atm_load_on_device(char *, int device, char **config);
     void init_module(module m);
     void init_module_half(module m);
-    void infer(module m, int device, void *data, int size, int half, void *dst);
+    tensor infer(module m, int device, void *data, int batch, int size, int half, float *mean, float *std);
     int cudaDeviceCount();
     size_t at_dim(tensor t);
     void at_shape(tensor t, int64_t *dims);
     tensor at_get(tensor t, int index);
+    void at_copy_data(tensor t, void *dst, size_t numel);
     int getBatchSize(tensor detections);
     int getNumClasses(tensor detections);
     void detections_info(tensor t);
@@ -1,3 +1,5 @@
+//go:build cgo && !nolibtorch
+
 package goyolov5
 
 //#include "stdlib.h"
@@ -11,26 +13,108 @@ import (
 type Ctensor = C.tensor
 type Cmodule = C.module
 
-func (yolov5 *YoloV5) atRunInfer(inputTensor *Tensor) ([]float32, int, error) {
-	cdevice := *(*C.int)(unsafe.Pointer(&yolov5.device))
-	csize := *(*C.int)(unsafe.Pointer(&yolov5.size))
+// torchBackend runs a TorchScript export through libtorch.
+type torchBackend struct {
+	model  Cmodule
+	device DeviceType
+	half   bool
+	// mean and std normalise inputs after scaling to [0, 1], if set.
+	mean, std *[3]float32
+}
+
+var _ BatchBackend = new(torchBackend)
+
+// newTorchBackend loads a TorchScript export, returning with it the contents of
+// its config.txt extra file, if any.
+func newTorchBackend(path string, device DeviceType, half bool) (*torchBackend, string, error) {
+	module, config := atmLoadOnDevice(path, device)
+	if err := TorchErr(); err != nil {
+		return nil, "", err
+	}
+
+	atmInitModule(module, half)
+	if err := TorchErr(); err != nil {
+		return nil, "", err
+	}
+
+	return &torchBackend{
+		model:  module,
+		device: device,
+		half:   half,
+	}, config, nil
+}
+
+// newTorchClassifierBackend loads a TorchScript classification export, whose
+// inputs are normalised with the ImageNet mean and standard deviation.
+func newTorchClassifierBackend(path string, device DeviceType, half bool) (*torchBackend, string, error) {
+	b, config, err := newTorchBackend(path, device, half)
+	if err != nil {
+		return nil, "", err
+	}
+	b.mean, b.std = &ImageNetMean, &ImageNetStd
+	return b, config, nil
+}
+
+// Forward implements InferenceBackend.
+func (b *torchBackend) Forward(inputTensor *Tensor) ([]float32, []int, error) {
+	return b.ForwardBatch([]*Tensor{inputTensor})
+}
+
+// ForwardBatch implements BatchBackend. The model must have been exported with
+// a dynamic or matching batch dimension.
+func (b *torchBackend) ForwardBatch(inputTensors []*Tensor) ([]float32, []int, error) {
+	size := inputTensors[0].Rect.Dx()
+
+	data := inputTensors[0].Pix
+	if len(inputTensors) > 1 {
+		data = make([]uint8, 0, len(inputTensors)*len(inputTensors[0].Pix))
+		for _, t := range inputTensors {
+			if t.Rect.Dx() != size || t.Rect.Dy() != size {
+				return nil, nil, fmt.Errorf("batch inputs must all be %dx%d, got %v", size, size, t.Rect)
+			}
+			data = append(data, t.Pix...)
+		}
+	}
+
+	cdevice := *(*C.int)(unsafe.Pointer(&b.device))
+	csize := *(*C.int)(unsafe.Pointer(&size))
+	batch := len(inputTensors)
+	cbatch := *(*C.int)(unsafe.Pointer(&batch))
 	hlf := 0
-	if yolov5.half {
+	if b.half {
 		hlf = 1
 	}
 	chalf := *(*C.int)(unsafe.Pointer(&hlf))
-	cdata := unsafe.Pointer(&inputTensor.Pix[0])
+	cdata := unsafe.Pointer(&data[0])
+	var cmean, cstd *C.float
+	if b.mean != nil && b.std != nil {
+		cmean = (*C.float)(unsafe.Pointer(&b.mean[0]))
+		cstd = (*C.float)(unsafe.Pointer(&b.std[0]))
+	}
 
-	out := make([]float32, NPreds*PredSize)
+	output := &Tensor{ctensor: C.infer(b.model, cdevice, cdata, cbatch, csize, chalf, cmean, cstd)}
+	if err := TorchErr(); err != nil {
+		return nil, nil, err
+	}
+	defer output.Drop()
+
+	dims, err := output.Size()
+	if err != nil {
+		return nil, nil, err
+	}
+	shape := make([]int, len(dims))
+	for i, d := range dims {
+		shape[i] = int(d)
+	}
 
-	cout := unsafe.Pointer(&out[0])
+	out := make([]float32, FlattenDim(dims))
 
-	C.infer(yolov5.model, cdevice, cdata, csize, chalf, cout)
+	atCopyData(output.ctensor, unsafe.Pointer(&out[0]), len(out))
 	if err := TorchErr(); err != nil {
-		return nil, 0, err
+		return nil, nil, err
 	}
 
-	return out, 1, nil
+	return out, shape, nil
 }
 
 func atGetCUDADeviceCount() (int, error) {
@@ -59,16 +143,24 @@ func atGet(ts Ctensor, index int) Ctensor {
 	return C.at_get(ts, cindex)
 }
 
+// void at_copy_data(tensor, void *, size_t);
+func atCopyData(t Ctensor, dst unsafe.Pointer, numel int) {
+	C.at_copy_data(t, dst, C.size_t(numel))
+}
+
 // void at_free(tensor);
 func atFree(ts Ctensor) {
 	C.at_free(ts)
 }
 
-func atmLoadOnDevice(path string, device int32) Cmodule {
+// module atm_load_on_device(char *, int device, char **config);
+func atmLoadOnDevice(path string, device int32) (Cmodule, string) {
 	ptr := C.CString(path)
 	defer C.free(unsafe.Pointer(ptr))
 	cdevice := *(*C.int)(unsafe.Pointer(&device))
-	return C.atm_load_on_device(ptr, cdevice)
+	var cconfig *C.char
+	module := C.atm_load_on_device(ptr, cdevice, &cconfig)
+	return module, ptrToString(cconfig)
 }
 
 func atmInitModule(m Cmodule, half bool) {
@@ -1,11 +1,10 @@
-//go:build !cuda
+//go:build !cuda && cgo && !nolibtorch
 
 package goyolov5
 
 import (
 	"image"
 	"os"
-	"runtime"
 	"testing"
 )
 
@@ -0,0 +1,30 @@
+//go:build !cgo || nolibtorch
+
+package goyolov5
+
+// Built without libtorch: the pure-Go parts of the package (Tensor, NMS,
+// annotation, FakeBackend) work, but TorchScript models cannot be loaded.
+
+type Ctensor = uintptr
+
+func newTorchBackend(path string, device DeviceType, half bool) (InferenceBackend, string, error) {
+	return nil, "", ErrBackendUnavailable
+}
+
+func newTorchClassifierBackend(path string, device DeviceType, half bool) (InferenceBackend, string, error) {
+	return nil, "", ErrBackendUnavailable
+}
+
+func atGetCUDADeviceCount() (int, error) {
+	return 0, ErrBackendUnavailable
+}
+
+func atFree(ts Ctensor) {}
+
+func TorchErr() error {
+	return nil
+}
+
+func (ts *Tensor) Size() ([]int64, error) {
+	return nil, ErrBackendUnavailable
+}
@@ -0,0 +1,165 @@
+//go:build cgo && onnxruntime
+
+#include <stdlib.h>
+#include <string.h>
+#include <onnxruntime_c_api.h>
+#include "goyolo_onnx.h"
+
+struct onnx_session
+{
+    OrtEnv *env;
+    OrtSession *session;
+    OrtMemoryInfo *memory_info;
+    char *input_name;
+    char *output_name;
+};
+
+static const OrtApi *ort_api()
+{
+    static const OrtApi *api = NULL;
+    if (api == NULL)
+        api = OrtGetApiBase()->GetApi(ORT_API_VERSION);
+    return api;
+}
+
+// status_to_err converts an OrtStatus to an error message and releases it.
+static char *status_to_err(OrtStatus *status)
+{
+    if (status == NULL)
+        return NULL;
+    char *err = strdup(ort_api()->GetErrorMessage(status));
+    ort_api()->ReleaseStatus(status);
+    return err;
+}
+
+#define ORT_CHECK(x)                         \
+    do                                       \
+    {                                        \
+        char *err = status_to_err(x);        \
+        if (err != NULL)                     \
+        {                                    \
+            return err;                      \
+        }                                    \
+    } while (0)
+
+char *onnx_load(const char *path, int device, onnx_session **out)
+{
+    const OrtApi *api = ort_api();
+    if (api == NULL)
+        return strdup("onnxruntime: unsupported API version");
+
+    onnx_session *s = calloc(1, sizeof(onnx_session));
+    *out = s;
+
+    ORT_CHECK(api->CreateEnv(ORT_LOGGING_LEVEL_WARNING, "goyolov5", &s->env));
+
+    // Every path past CreateSessionOptions releases the options at cleanup
+    OrtSessionOptions *options = NULL;
+    char *err = status_to_err(api->CreateSessionOptions(&options));
+    if (err != NULL)
+        goto cleanup;
+    err = status_to_err(api->SetSessionGraphOptimizationLevel(options, ORT_ENABLE_ALL));
+    if (err != NULL)
+        goto cleanup;
+
+    if (device >= 0)
+    {
+        OrtCUDAProviderOptions cuda_options;
+        memset(&cuda_options, 0, sizeof(cuda_options));
+        cuda_options.device_id = device;
+        err = status_to_err(api->SessionOptionsAppendExecutionProvider_CUDA(options, &cuda_options));
+        if (err != NULL)
+            goto cleanup;
+    }
+
+    err = status_to_err(api->CreateSession(s->env, path, options, &s->session));
+
+cleanup:
+    if (options != NULL)
+        api->ReleaseSessionOptions(options);
+    if (err != NULL)
+        return err;
+
+    ORT_CHECK(api->CreateCpuMemoryInfo(OrtArenaAllocator, OrtMemTypeDefault, &s->memory_info));
+
+    OrtAllocator *allocator;
+    ORT_CHECK(api->GetAllocatorWithDefaultOptions(&allocator));
+
+    char *name;
+    ORT_CHECK(api->SessionGetInputName(s->session, 0, allocator, &name));
+    s->input_name = strdup(name);
+    allocator->Free(allocator, name);
+
+    ORT_CHECK(api->SessionGetOutputName(s->session, 0, allocator, &name));
+    s->output_name = strdup(name);
+    allocator->Free(allocator, name);
+
+    return NULL;
+}
+
+char *onnx_run(onnx_session *s, float *data, int64_t *shape, size_t dims, onnx_value *output)
+{
+    const OrtApi *api = ort_api();
+
+    size_t numel = 1;
+    for (size_t i = 0; i < dims; i++)
+        numel *= shape[i];
+
+    OrtValue *input = NULL;
+    ORT_CHECK(api->CreateTensorWithDataAsOrtValue(s->memory_info, data, numel * sizeof(float), shape, dims, ONNX_TENSOR_ELEMENT_DATA_TYPE_FLOAT, &input));
+
+    const char *input_names[] = {s->input_name};
+    const char *output_names[] = {s->output_name};
+    OrtValue *result = NULL;
+    char *err = status_to_err(api->Run(s->session, NULL, input_names, (const OrtValue *const *)&input, 1, output_names, 1, &result));
+    api->ReleaseValue(input);
+    if (err != NULL)
+        return err;
+
+    *output = result;
+    return NULL;
+}
+
+char *onnx_shape(onnx_value value, int64_t *dims, size_t max_dims, size_t *n_dims)
+{
+    const OrtApi *api = ort_api();
+
+    OrtTensorTypeAndShapeInfo *info;
+    ORT_CHECK(api->GetTensorTypeAndShape((OrtValue *)value, &info));
+
+    char *err = status_to_err(api->GetDimensionsCount(info, n_dims));
+    if (err == NULL && *n_dims > max_dims)
+        err = strdup("onnxruntime: too many output dimensions");
+    if (err == NULL)
+        err = status_to_err(api->GetDimensions(info, dims, *n_dims));
+
+    api->ReleaseTensorTypeAndShapeInfo(info);
+    return err;
+}
+
+char *onnx_data(onnx_value value, float **data)
+{
+    ORT_CHECK(ort_api()->GetTensorMutableData((OrtValue *)value, (void **)data));
+    return NULL;
+}
+
+void onnx_free_value(onnx_value value)
+{
+    ort_api()->ReleaseValue((OrtValue *)value);
+}
+
+void onnx_free(onnx_session *s)
+{
+    const OrtApi *api = ort_api();
+    if (s == NULL)
+        return;
+    if (s->session != NULL)
+        api->ReleaseSession(s->session);
+    if (s->memory_info != NULL)
+        api->ReleaseMemoryInfo(s->memory_info);
+    if (s->env != NULL)
+        api->ReleaseEnv(s->env);
+    free(s->input_name);
+    free(s->output_name);
+    free(s);
+}
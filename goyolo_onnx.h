@@ -0,0 +1,27 @@
+#ifndef __GOYOLO_ONNX_API_H__
+#define __GOYOLO_ONNX_API_H__
+#include <stdint.h>
+#include <stddef.h>
+
+#ifdef __cplusplus
+extern "C"
+{
+#endif
+
+    typedef struct onnx_session onnx_session;
+    typedef void *onnx_value;
+
+    // Every function returning char * returns NULL on success or a malloc'd
+    // error message the caller must free.
+    char *onnx_load(const char *path, int device, onnx_session **session);
+    char *onnx_run(onnx_session *session, float *data, int64_t *shape, size_t dims, onnx_value *output);
+    char *onnx_shape(onnx_value value, int64_t *dims, size_t max_dims, size_t *n_dims);
+    char *onnx_data(onnx_value value, float **data);
+    void onnx_free_value(onnx_value value);
+    void onnx_free(onnx_session *session);
+
+#ifdef __cplusplus
+}; // extern "C"
+#endif
+
+#endif
@@ -1,3 +1,5 @@
+//go:build cgo && !nolibtorch
+
 package goyolov5
 
 import (
@@ -56,84 +58,6 @@ func testInfer(t *testing.T, path string, yolov5 *YoloV5, expectedCount int) {
 	}
 }
 
-func TestLetterboxingResize(t *testing.T) {
-	f, err := os.Open("tests/inputs/640x480.png")
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer f.Close()
-	input, _, err := image.Decode(f)
-	if err != nil {
-		t.Fatal(err)
-	}
-	tensor := NewTensorFromImage(input)
-
-	f2, err := os.Create("tests/outputs/640x480_load.png")
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer f2.Close()
-	if err = png.Encode(f2, tensor); err != nil {
-		t.Fatal(err)
-	}
-
-	lb, extraX, extraY, err := tensor.ToSquareShape()
-	if err != nil {
-		t.Fatal(err)
-	}
-
-	if extraX != 0 {
-		t.Fatalf("expected 0, got %d", extraX)
-	}
-	if extraY != 160 {
-		t.Fatalf("expected 160, got %d", extraX)
-	}
-
-	f3, err := os.Create("tests/outputs/640x480_lb.png")
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer f3.Close()
-	if err = png.Encode(f3, lb); err != nil {
-		t.Fatal(err)
-	}
-
-	rs, _, err := lb.Resize(400)
-	if err != nil {
-		t.Fatal(err)
-	}
-
-	f4, err := os.Create("tests/outputs/640x480_rs.png")
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer f4.Close()
-	if err = png.Encode(f4, rs); err != nil {
-		t.Fatal(err)
-	}
-
-}
-
-func BenchmarkToSquare(b *testing.B) {
-
-	f, err := os.Open("tests/inputs/640x480.png")
-	if err != nil {
-		b.Fatal(err)
-	}
-	defer f.Close()
-	input, _, err := image.Decode(f)
-	if err != nil {
-		b.Fatal(err)
-	}
-	tensor := NewTensorFromImage(input)
-
-	b.ResetTimer()
-
-	for i := 0; i < b.N; i++ {
-		tensor.ToSquareShape()
-	}
-}
-
 func BenchmarkPreProcess(b *testing.B) {
 
 	yolov5 := loadYoloForTesting(b)
@@ -179,7 +103,7 @@ func BenchmarkRunInfer(b *testing.B) {
 	}
 
 	for i := 0; i < 10; i++ {
-		_, _, err := yolov5.atRunInfer(inputTensor)
+		_, _, err := yolov5.backend.Forward(inputTensor)
 		if err != nil {
 			b.Fatal(err)
 		}
@@ -188,7 +112,7 @@ func BenchmarkRunInfer(b *testing.B) {
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		_, _, err := yolov5.atRunInfer(inputTensor)
+		_, _, err := yolov5.backend.Forward(inputTensor)
 		if err != nil {
 			b.Fatal(err)
 		}
@@ -209,7 +133,7 @@ func BenchmarkPostConfidence(b *testing.B) {
 	}
 	inputTensor := NewTensorFromImage(input)
 
-	tensorBatch, _, err := yolov5.atRunInfer(inputTensor)
+	tensorBatch, _, err := yolov5.backend.Forward(inputTensor)
 	if err != nil {
 		b.Fatal(err)
 	}
@@ -219,7 +143,7 @@ func BenchmarkPostConfidence(b *testing.B) {
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		_, err := yolov5.postConfidence(tensorBatch, int(NClasses), int(NPreds), confidenceThreshold)
+		_, err := yolov5.postConfidence(tensorBatch, int(NClasses), 0, int(NPreds), confidenceThreshold)
 		if err != nil {
 			b.Fatal(err)
 		}
@@ -240,14 +164,14 @@ func BenchmarkNMS(b *testing.B) {
 	}
 	inputTensor := NewTensorFromImage(input)
 
-	tensorBatch, _, err := yolov5.atRunInfer(inputTensor)
+	tensorBatch, _, err := yolov5.backend.Forward(inputTensor)
 	if err != nil {
 		b.Fatal(err)
 	}
 
 	confidenceThreshold := float32(0.5)
 
-	bboxes, err := yolov5.postConfidence(tensorBatch, int(NClasses), int(NPreds), confidenceThreshold)
+	bboxes, err := yolov5.postConfidence(tensorBatch, int(NClasses), 0, int(NPreds), confidenceThreshold)
 	if err != nil {
 		b.Fatal(err)
 	}
@@ -0,0 +1,360 @@
+// Package harvest saves the frames a detector is unsure about, with its
+// predictions as YOLO txt pre-labels, for labelling and retraining. Frames
+// qualify by confidences in an uncertain band, by flickering tracks or by
+// disagreement with a second model; near-duplicates are skipped and the
+// harvest stays under a disk quota.
+package harvest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danhilltech/goyolov5"
+	"github.com/danhilltech/goyolov5/tracker"
+)
+
+// Reason is a set of reasons a frame is harvested.
+type Reason int
+
+const (
+	// Uncertain frames have a prediction with a confidence in the band.
+	Uncertain Reason = 1 << iota
+	// Flicker frames have a track switching between tracked and lost.
+	Flicker
+	// Disagreement frames have predictions the reference model does not
+	// match, or the other way round.
+	Disagreement
+)
+
+func (r Reason) String() string {
+	var names []string
+	for _, reason := range []struct {
+		r    Reason
+		name string
+	}{{Uncertain, "uncertain"}, {Flicker, "flicker"}, {Disagreement, "disagreement"}} {
+		if r&reason.r != 0 {
+			names = append(names, reason.name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, "+")
+}
+
+// Config configures a Harvester. Each criterion is off at its zero value.
+type Config struct {
+	// Dir is where frames and labels are saved.
+	Dir string
+	// Names are written to classes.txt for labelling tools, if set.
+	Names []string
+
+	// ConfidenceLow and ConfidenceHigh are the uncertain band: a prediction
+	// with Confidence in [ConfidenceLow, ConfidenceHigh) makes the frame
+	// uncertain.
+	ConfidenceLow  float64
+	ConfidenceHigh float64
+	// FlickerTransitions is how many times a track must switch between
+	// tracked and lost within FlickerWindow frames to count as flicker.
+	FlickerTransitions int
+	FlickerWindow      int
+	// DisagreementIoU is the IoU at which a prediction and a reference
+	// prediction of the same class agree.
+	DisagreementIoU float64
+
+	// MaxBytes is the disk quota for saved frames and labels, including those
+	// already in Dir. Frames are dropped once it is reached.
+	MaxBytes int64
+	// DedupDistance is the number of bits within which the perceptual hashes
+	// of two frames make them duplicates; 0 only skips identical hashes and
+	// a negative value disables deduplication.
+	DedupDistance int
+	// MinInterval is the least time between saved frames, by Frame.Time.
+	MinInterval time.Duration
+	// JPEGQuality is the quality of saved frames, 90 by default.
+	JPEGQuality int
+	// Redactor, if set, hides the regions of its classes' predictions in a
+	// copy of each frame before it is saved.
+	Redactor *goyolov5.Redactor
+}
+
+// Frame is one frame offered to a Harvester.
+type Frame struct {
+	Image       *goyolov5.Tensor
+	Predictions []goyolov5.Prediction
+	// Tracks are the tracker's output for the frame, for flicker.
+	Tracks []tracker.Track
+	// Reference are a second model's predictions of the frame, compared when
+	// DisagreementIoU is set.
+	Reference []goyolov5.Prediction
+	// Time is the capture time, the current time if zero.
+	Time time.Time
+}
+
+// Stats counts what happened to offered frames.
+type Stats struct {
+	Offered uint64
+	Saved   uint64
+	// Duplicate, Throttled and OverQuota count qualifying frames that were
+	// not saved.
+	Duplicate uint64
+	Throttled uint64
+	OverQuota uint64
+	// Bytes is the disk space in use, including files from earlier runs.
+	Bytes int64
+}
+
+// Harvester saves qualifying frames. It is safe for concurrent use, but
+// flicker detection assumes frames are offered in order.
+type Harvester struct {
+	config Config
+
+	mu        sync.Mutex
+	frame     int
+	tracks    map[int]*trackHistory
+	hashes    []uint64
+	lastSaved time.Time
+	seq       int
+	stats     Stats
+}
+
+// trackHistory is the frames a track switched between tracked and lost in.
+type trackHistory struct {
+	state       tracker.TrackState
+	transitions []int
+}
+
+// NewHarvester creates Dir if needed and counts the frames already saved in
+// it towards the quota and deduplication.
+func NewHarvester(config Config) (*Harvester, error) {
+	if config.Dir == "" {
+		return nil, errors.New("harvest directory is required")
+	}
+	if config.MaxBytes <= 0 {
+		return nil, fmt.Errorf("disk quota must be positive, got %d", config.MaxBytes)
+	}
+	if config.ConfidenceLow > config.ConfidenceHigh {
+		return nil, fmt.Errorf("confidence band [%v, %v) is empty", config.ConfidenceLow, config.ConfidenceHigh)
+	}
+	if config.FlickerTransitions > 0 && config.FlickerWindow < config.FlickerTransitions {
+		return nil, fmt.Errorf("flicker window of %d frames cannot hold %d transitions", config.FlickerWindow, config.FlickerTransitions)
+	}
+	if config.JPEGQuality == 0 {
+		config.JPEGQuality = 90
+	}
+	if err := os.MkdirAll(config.Dir, 0755); err != nil {
+		return nil, err
+	}
+
+	h := &Harvester{config: config, tracks: map[int]*trackHistory{}}
+	if err := h.scan(); err != nil {
+		return nil, err
+	}
+	if len(config.Names) > 0 {
+		if err := os.WriteFile(filepath.Join(config.Dir, "classes.txt"), []byte(strings.Join(config.Names, "\n")+"\n"), 0644); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+// scan counts earlier frames, named time_seq_reason_hash.
+func (h *Harvester) scan() error {
+	entries, err := os.ReadDir(h.config.Dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if e.IsDir() || (ext != ".jpg" && ext != ".txt") || e.Name() == "classes.txt" {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return err
+		}
+		h.stats.Bytes += info.Size()
+
+		fields := strings.Split(strings.TrimSuffix(e.Name(), ext), "_")
+		if ext == ".jpg" && len(fields) == 4 {
+			if hash, err := strconv.ParseUint(fields[3], 16, 64); err == nil {
+				h.hashes = append(h.hashes, hash)
+			}
+		}
+	}
+	return nil
+}
+
+// Offer checks a frame against the criteria and saves it if it qualifies and
+// is neither a duplicate, too soon after the last saved frame nor over the
+// quota. It returns why the frame was saved, or 0 if it was not.
+func (h *Harvester) Offer(frame Frame) (Reason, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.stats.Offered++
+	h.frame++
+	if frame.Time.IsZero() {
+		frame.Time = time.Now()
+	}
+
+	var reason Reason
+	if h.uncertain(frame.Predictions) {
+		reason |= Uncertain
+	}
+	if h.flicker(frame.Tracks) {
+		reason |= Flicker
+	}
+	if h.config.DisagreementIoU > 0 && disagree(frame.Predictions, frame.Reference, h.config.DisagreementIoU) {
+		reason |= Disagreement
+	}
+	if reason == 0 {
+		return 0, nil
+	}
+
+	if !h.lastSaved.IsZero() && frame.Time.Sub(h.lastSaved) < h.config.MinInterval {
+		h.stats.Throttled++
+		return 0, nil
+	}
+
+	img := frame.Image
+	if h.config.Redactor != nil {
+		img = goyolov5.NewTensorFromImage(frame.Image)
+		h.config.Redactor.Redact(img, frame.Predictions)
+	}
+
+	hash := differenceHash(img)
+	if h.config.DedupDistance >= 0 {
+		for _, saved := range h.hashes {
+			if hashDistance(hash, saved) <= h.config.DedupDistance {
+				h.stats.Duplicate++
+				return 0, nil
+			}
+		}
+	}
+
+	var encoded, labels bytes.Buffer
+	if err := jpeg.Encode(&encoded, img, &jpeg.Options{Quality: h.config.JPEGQuality}); err != nil {
+		return 0, err
+	}
+	if err := goyolov5.WriteYOLOLabels(&labels, frame.Predictions, frame.Image.Rect.Dx(), frame.Image.Rect.Dy(), false); err != nil {
+		return 0, err
+	}
+	size := int64(encoded.Len() + labels.Len())
+	if h.stats.Bytes+size > h.config.MaxBytes {
+		h.stats.OverQuota++
+		return 0, nil
+	}
+
+	h.seq++
+	name := fmt.Sprintf("%s_%06d_%s_%016x", frame.Time.UTC().Format("20060102T150405.000000000Z"), h.seq, reason, hash)
+	stem := filepath.Join(h.config.Dir, name)
+	if err := os.WriteFile(stem+".txt", labels.Bytes(), 0644); err != nil {
+		return 0, err
+	}
+	if err := os.WriteFile(stem+".jpg", encoded.Bytes(), 0644); err != nil {
+		os.Remove(stem + ".txt")
+		return 0, err
+	}
+
+	h.hashes = append(h.hashes, hash)
+	h.lastSaved = frame.Time
+	h.stats.Saved++
+	h.stats.Bytes += size
+	return reason, nil
+}
+
+// Stats returns the counters so far.
+func (h *Harvester) Stats() Stats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.stats
+}
+
+func (h *Harvester) uncertain(predictions []goyolov5.Prediction) bool {
+	for _, p := range predictions {
+		if p.Confidence >= h.config.ConfidenceLow && p.Confidence < h.config.ConfidenceHigh {
+			return true
+		}
+	}
+	return false
+}
+
+// flicker records the tracks' state changes and reports whether any track
+// switched between tracked and lost often enough within the window.
+func (h *Harvester) flicker(tracks []tracker.Track) bool {
+	if h.config.FlickerTransitions <= 0 {
+		return false
+	}
+
+	var flickering bool
+	for _, t := range tracks {
+		history, ok := h.tracks[t.ID]
+		if !ok {
+			history = &trackHistory{state: t.State}
+			h.tracks[t.ID] = history
+		}
+		if t.State == tracker.Removed {
+			delete(h.tracks, t.ID)
+			continue
+		}
+		if (history.state == tracker.Tracked && t.State == tracker.Lost) || (history.state == tracker.Lost && t.State == tracker.Tracked) {
+			history.transitions = append(history.transitions, h.frame)
+		}
+		history.state = t.State
+
+		// Forget transitions that left the window
+		i := sort.SearchInts(history.transitions, h.frame-h.config.FlickerWindow+1)
+		history.transitions = history.transitions[i:]
+		if len(history.transitions) >= h.config.FlickerTransitions {
+			flickering = true
+		}
+	}
+	return flickering
+}
+
+// disagree reports whether either set of predictions has one the other
+// lacks, pairing predictions of the same class greedily by IoU.
+func disagree(predictions, reference []goyolov5.Prediction, threshold float64) bool {
+	if len(predictions) != len(reference) {
+		return true
+	}
+
+	type pair struct {
+		i, j int
+		iou  float64
+	}
+	var pairs []pair
+	for i, p := range predictions {
+		for j, r := range reference {
+			if p.ClassIndex != r.ClassIndex {
+				continue
+			}
+			if iou := tracker.IoU(p.Rect, r.Rect); iou >= threshold {
+				pairs = append(pairs, pair{i, j, iou})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(a, b int) bool { return pairs[a].iou > pairs[b].iou })
+
+	matchedP := make([]bool, len(predictions))
+	matchedR := make([]bool, len(reference))
+	var matches int
+	for _, p := range pairs {
+		if matchedP[p.i] || matchedR[p.j] {
+			continue
+		}
+		matchedP[p.i], matchedR[p.j] = true, true
+		matches++
+	}
+	return matches < len(predictions)
+}
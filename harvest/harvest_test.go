@@ -0,0 +1,213 @@
+package harvest
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danhilltech/goyolov5"
+	"github.com/danhilltech/goyolov5/tracker"
+)
+
+var start = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+func prediction(class uint, confidence float64, x int) goyolov5.Prediction {
+	return goyolov5.Prediction{Rect: image.Rect(x, 40, x+40, 120), Confidence: confidence, ClassIndex: class, ClassConfidence: 0.9}
+}
+
+func newTestHarvester(t *testing.T, config Config) *Harvester {
+	if config.Dir == "" {
+		config.Dir = t.TempDir()
+	}
+	if config.MaxBytes == 0 {
+		config.MaxBytes = 1 << 20
+	}
+	h, err := NewHarvester(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func files(t *testing.T, dir, ext string) []string {
+	matches, err := filepath.Glob(filepath.Join(dir, "*"+ext))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return matches
+}
+
+func TestHarvestUncertain(t *testing.T) {
+	dir := t.TempDir()
+	h := newTestHarvester(t, Config{Dir: dir, Names: []string{"person", "car"}, ConfidenceLow: 0.3, ConfidenceHigh: 0.6})
+
+	reason, err := h.Offer(Frame{Image: gradientFrame(0), Predictions: []goyolov5.Prediction{prediction(0, 0.9, 10)}, Time: start})
+	if err != nil || reason != 0 {
+		t.Fatalf("expected a confident frame to be skipped, got %v, %v", reason, err)
+	}
+	reason, err = h.Offer(Frame{Image: gradientFrame(0), Predictions: []goyolov5.Prediction{prediction(0, 0.9, 10), prediction(1, 0.4, 100)}, Time: start})
+	if err != nil || reason != Uncertain {
+		t.Fatalf("expected an uncertain frame to be saved, got %v, %v", reason, err)
+	}
+
+	images, labels := files(t, dir, ".jpg"), files(t, dir, ".txt")
+	if len(images) != 1 || len(labels) != 2 {
+		t.Fatalf("expected an image, its labels and classes.txt, got %v %v", images, labels)
+	}
+	if name := filepath.Base(images[0]); !strings.HasPrefix(name, "20261019T120000.000000000Z_000001_uncertain_") {
+		t.Errorf("unexpected name %s", name)
+	}
+	data, err := os.ReadFile(strings.TrimSuffix(images[0], ".jpg") + ".txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 2 || !strings.HasPrefix(lines[1], "1 0.375000 ") {
+		t.Errorf("unexpected labels %q", data)
+	}
+
+	stats := h.Stats()
+	if stats.Offered != 2 || stats.Saved != 1 || stats.Bytes == 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestHarvestLimits(t *testing.T) {
+	dir := t.TempDir()
+	config := Config{Dir: dir, ConfidenceLow: 0.3, ConfidenceHigh: 0.6, DedupDistance: 4, MinInterval: time.Second}
+	h := newTestHarvester(t, config)
+	uncertain := []goyolov5.Prediction{prediction(0, 0.4, 10)}
+
+	offer := func(offset int, at time.Duration) Reason {
+		reason, err := h.Offer(Frame{Image: gradientFrame(offset), Predictions: uncertain, Time: start.Add(at)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return reason
+	}
+	if offer(0, 0) != Uncertain {
+		t.Fatal("expected the first frame to be saved")
+	}
+	if offer(128, 500*time.Millisecond) != 0 {
+		t.Error("expected a frame within MinInterval to be throttled")
+	}
+	if offer(0, 2*time.Second) != 0 {
+		t.Error("expected a duplicate frame to be skipped")
+	}
+	if offer(128, 3*time.Second) != Uncertain {
+		t.Error("expected a different frame to be saved")
+	}
+	stats := h.Stats()
+	if stats.Throttled != 1 || stats.Duplicate != 1 || stats.Saved != 2 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	// A restarted harvester counts earlier frames towards its quota and
+	// deduplication
+	config.MaxBytes = stats.Bytes + 100
+	h = newTestHarvester(t, config)
+	if got := h.Stats().Bytes; got != stats.Bytes {
+		t.Errorf("expected %d bytes in use after restart, got %d", stats.Bytes, got)
+	}
+	if offer(128, 10*time.Second) != 0 || h.Stats().Duplicate != 1 {
+		t.Error("expected a frame saved before the restart to be a duplicate")
+	}
+	if offer(64, 20*time.Second) != 0 || h.Stats().OverQuota != 1 {
+		t.Error("expected a frame over the quota to be dropped")
+	}
+	if n := len(files(t, dir, ".jpg")); n != 2 {
+		t.Errorf("expected 2 saved frames, got %d", n)
+	}
+}
+
+func TestHarvestFlicker(t *testing.T) {
+	h := newTestHarvester(t, Config{FlickerTransitions: 3, FlickerWindow: 5, DedupDistance: -1})
+
+	states := []tracker.TrackState{tracker.New, tracker.Tracked, tracker.Lost, tracker.Tracked, tracker.Tracked, tracker.Lost, tracker.Lost, tracker.Lost, tracker.Tracked}
+	var flickered []int
+	for i, state := range states {
+		reason, err := h.Offer(Frame{Image: gradientFrame(i), Tracks: []tracker.Track{{ID: 1, State: state}}, Time: start.Add(time.Duration(i) * time.Second)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reason == Flicker {
+			flickered = append(flickered, i)
+		}
+	}
+	// The track switches at indices 2, 3, 5 and 8; the first three are within
+	// 5 frames until index 7
+	if len(flickered) != 2 || flickered[0] != 5 || flickered[1] != 6 {
+		t.Errorf("expected flicker at indices 5 and 6, got %v", flickered)
+	}
+}
+
+func TestHarvestDisagreement(t *testing.T) {
+	h := newTestHarvester(t, Config{DisagreementIoU: 0.5, DedupDistance: -1})
+
+	for i, c := range []struct {
+		predictions, reference []goyolov5.Prediction
+		disagree               bool
+	}{
+		{[]goyolov5.Prediction{prediction(0, 0.9, 10)}, []goyolov5.Prediction{prediction(0, 0.8, 14)}, false},
+		{[]goyolov5.Prediction{prediction(0, 0.9, 10)}, []goyolov5.Prediction{prediction(1, 0.8, 10)}, true},
+		{[]goyolov5.Prediction{prediction(0, 0.9, 10)}, []goyolov5.Prediction{prediction(0, 0.8, 40)}, true},
+		{[]goyolov5.Prediction{prediction(0, 0.9, 10)}, nil, true},
+		{nil, nil, false},
+	} {
+		reason, err := h.Offer(Frame{Image: gradientFrame(i), Predictions: c.predictions, Reference: c.reference, Time: start.Add(time.Duration(i) * time.Second)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (reason == Disagreement) != c.disagree {
+			t.Errorf("case %d: expected disagreement %v, got %v", i, c.disagree, reason)
+		}
+	}
+}
+
+func TestHarvestRedact(t *testing.T) {
+	dir := t.TempDir()
+	redactor := &goyolov5.Redactor{Style: goyolov5.RedactFill, Classes: []uint{0}}
+	h := newTestHarvester(t, Config{Dir: dir, ConfidenceLow: 0.3, ConfidenceHigh: 0.6, Redactor: redactor})
+
+	frame := gradientFrame(0)
+	original := append([]uint8(nil), frame.Pix...)
+	if _, err := h.Offer(Frame{Image: frame, Predictions: []goyolov5.Prediction{prediction(0, 0.4, 10), prediction(1, 0.9, 200)}, Time: start}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(frame.Pix, original) {
+		t.Error("expected the offered frame left unchanged")
+	}
+
+	images := files(t, dir, ".jpg")
+	if len(images) != 1 {
+		t.Fatalf("expected one image, got %v", images)
+	}
+	f, err := os.Open(images[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	saved, err := jpeg.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, g, b, _ := saved.At(30, 80).RGBA(); r>>8 > 8 || g>>8 > 8 || b>>8 > 8 {
+		t.Errorf("expected the person filled in the saved frame, got %d,%d,%d", r>>8, g>>8, b>>8)
+	}
+	if _, g, _, _ := saved.At(220, 80).RGBA(); g>>8 < 8 {
+		t.Errorf("expected the car left alone in the saved frame")
+	}
+}
+
+func TestReasonString(t *testing.T) {
+	if s := (Uncertain | Disagreement).String(); s != "uncertain+disagreement" {
+		t.Errorf("unexpected %q", s)
+	}
+	if s := Reason(0).String(); s != "none" {
+		t.Errorf("unexpected %q", s)
+	}
+}
@@ -0,0 +1,70 @@
+package harvest
+
+import (
+	"math/bits"
+
+	"github.com/danhilltech/goyolov5"
+)
+
+// hashSamples is the most pixels sampled along each side of a hash cell.
+const hashSamples = 16
+
+// differenceHash is a 64 bit perceptual hash of an image: the sign of the
+// brightness gradient between horizontally adjacent cells of a 9x8 grid.
+// Near-identical frames, such as consecutive frames of a static scene, hash
+// within a few bits of each other.
+func differenceHash(t *goyolov5.Tensor) uint64 {
+	w, h := t.Rect.Dx(), t.Rect.Dy()
+	if w == 0 || h == 0 {
+		return 0
+	}
+
+	var cells [8][9]float64
+	for cy := range cells {
+		y0, y1 := cy*h/8, (cy+1)*h/8
+		if y1 == y0 {
+			y1 = y0 + 1
+		}
+		for cx := range cells[cy] {
+			x0, x1 := cx*w/9, (cx+1)*w/9
+			if x1 == x0 {
+				x1 = x0 + 1
+			}
+			stepX, stepY := step(x1-x0), step(y1-y0)
+
+			var sum float64
+			var n int
+			for y := y0; y < y1 && y < h; y += stepY {
+				for x := x0; x < x1 && x < w; x += stepX {
+					i := y*t.Stride + x*3
+					sum += 0.299*float64(t.Pix[i]) + 0.587*float64(t.Pix[i+1]) + 0.114*float64(t.Pix[i+2])
+					n++
+				}
+			}
+			cells[cy][cx] = sum / float64(n)
+		}
+	}
+
+	var hash uint64
+	for cy := range cells {
+		for cx := 0; cx < 8; cx++ {
+			hash <<= 1
+			if cells[cy][cx] < cells[cy][cx+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+func step(n int) int {
+	if n <= hashSamples {
+		return 1
+	}
+	return n / hashSamples
+}
+
+// hashDistance is the number of bits two hashes differ in.
+func hashDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
@@ -0,0 +1,45 @@
+package harvest
+
+import (
+	"image"
+	"math/rand"
+	"testing"
+
+	"github.com/danhilltech/goyolov5"
+)
+
+// gradientFrame is a frame with a diagonal gradient, shifted by offset.
+func gradientFrame(offset int) *goyolov5.Tensor {
+	t := goyolov5.NewTensor(image.Rect(0, 0, 320, 240))
+	for y := 0; y < 240; y++ {
+		for x := 0; x < 320; x++ {
+			i := t.PixOffset(x, y)
+			v := uint8((x*3 + y + offset) % 256)
+			t.Pix[i], t.Pix[i+1], t.Pix[i+2] = v, 255-v, v/2
+		}
+	}
+	return t
+}
+
+func TestDifferenceHash(t *testing.T) {
+	a := gradientFrame(0)
+	if differenceHash(a) != differenceHash(gradientFrame(0)) {
+		t.Error("expected identical frames to hash the same")
+	}
+
+	noisy := gradientFrame(0)
+	rng := rand.New(rand.NewSource(1))
+	for i := range noisy.Pix {
+		noisy.Pix[i] = uint8(int(noisy.Pix[i]) + rng.Intn(5) - 2)
+	}
+	if d := hashDistance(differenceHash(a), differenceHash(noisy)); d > 4 {
+		t.Errorf("expected a noisy copy within 4 bits, got %d", d)
+	}
+
+	if d := hashDistance(differenceHash(a), differenceHash(gradientFrame(128))); d < 10 {
+		t.Errorf("expected a different frame over 10 bits away, got %d", d)
+	}
+
+	// Frames smaller than the grid still hash
+	differenceHash(goyolov5.NewTensor(image.Rect(0, 0, 3, 2)))
+}
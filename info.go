@@ -0,0 +1,114 @@
+package goyolov5
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"sort"
+	"strconv"
+)
+
+// ModelInfo describes a loaded model.
+type ModelInfo struct {
+	Name string
+	// Size is the square model input size in pixels.
+	Size       int
+	Classes    int
+	Keypoints  int
+	ClassNames []string
+	// Stride is the largest stride of the detection heads, or 0 if unknown.
+	Stride int
+}
+
+// torchConfig is the config.txt extra file YOLOv5's export.py adds to
+// TorchScript exports. names is a list in older exports and an index keyed
+// object in newer ones.
+type torchConfig struct {
+	Shape  []int           `json:"shape"`
+	Stride int             `json:"stride"`
+	Names  json.RawMessage `json:"names"`
+}
+
+// applyConfig reads class names and stride from a TorchScript config.txt.
+func (yolov5 *YoloV5) applyConfig(config string) error {
+	var c torchConfig
+	if err := json.Unmarshal([]byte(config), &c); err != nil {
+		return err
+	}
+	yolov5.stride = c.Stride
+
+	if len(c.Names) == 0 {
+		return nil
+	}
+	names, err := parseConfigNames(c.Names)
+	if err != nil {
+		return err
+	}
+	if len(names) != yolov5.nClasses {
+		return fmt.Errorf("model has %d classes but %d names", yolov5.nClasses, len(names))
+	}
+	yolov5.names = names
+	return nil
+}
+
+// parseConfigNames reads the names of a config.txt, a list or an index keyed
+// object.
+func parseConfigNames(raw json.RawMessage) ([]string, error) {
+	var names []string
+	if err := json.Unmarshal(raw, &names); err == nil {
+		return names, nil
+	}
+	var byIndex map[string]string
+	if err := json.Unmarshal(raw, &byIndex); err != nil {
+		return nil, fmt.Errorf("unexpected names %s", raw)
+	}
+	indices := make([]int, 0, len(byIndex))
+	for k := range byIndex {
+		i, err := strconv.Atoi(k)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected class index %q", k)
+		}
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+	names = make([]string, len(indices))
+	for i, index := range indices {
+		names[i] = byIndex[strconv.Itoa(index)]
+	}
+	return names, nil
+}
+
+// SetClassNames names the model's classes, by class index.
+func (yolov5 *YoloV5) SetClassNames(names []string) {
+	yolov5.names = names
+}
+
+// Info describes the model. Class names come from the export's metadata or
+// SetClassNames, falling back to COCONames for 80 class models.
+func (yolov5 *YoloV5) Info() ModelInfo {
+	names := yolov5.names
+	if names == nil && yolov5.nClasses == NClasses {
+		names = COCONames
+	}
+	return ModelInfo{
+		Name:       yolov5.modelName,
+		Size:       yolov5.size,
+		Classes:    yolov5.nClasses,
+		Keypoints:  yolov5.nKeypoints,
+		ClassNames: names,
+		Stride:     yolov5.stride,
+	}
+}
+
+// OutputShape runs the model once over a blank input and returns the shape of
+// its raw output, [batch, predictions, values per prediction].
+func (yolov5 *YoloV5) OutputShape() ([]int, error) {
+	output, shape, err := yolov5.backend.Forward(NewTensor(image.Rect(0, 0, yolov5.size, yolov5.size)))
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := yolov5.outputShape(output, shape); err != nil {
+		return nil, err
+	}
+	return shape, nil
+}
@@ -0,0 +1,65 @@
+package goyolov5
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyConfig(t *testing.T) {
+	yolov5 := NewYoloV5WithBackend(NewFakeBackend(2, 0, 10), 640, 2, 0)
+
+	if err := yolov5.applyConfig(`{"shape": [1, 3, 640, 640], "stride": 32, "names": ["cat", "dog"]}`); err != nil {
+		t.Fatal(err)
+	}
+	if info := yolov5.Info(); info.Stride != 32 || !reflect.DeepEqual(info.ClassNames, []string{"cat", "dog"}) {
+		t.Fatalf("unexpected info %+v", info)
+	}
+
+	// Newer exports key names by class index
+	if err := yolov5.applyConfig(`{"stride": 64, "names": {"1": "dog", "0": "cat"}}`); err != nil {
+		t.Fatal(err)
+	}
+	if info := yolov5.Info(); info.Stride != 64 || !reflect.DeepEqual(info.ClassNames, []string{"cat", "dog"}) {
+		t.Fatalf("unexpected info %+v", info)
+	}
+
+	if err := yolov5.applyConfig(`{"stride": 32, "names": ["cat"]}`); err == nil {
+		t.Fatal("expected an error for a class count mismatch")
+	}
+	if err := yolov5.applyConfig(`not json`); err == nil {
+		t.Fatal("expected an error for a malformed config")
+	}
+}
+
+func TestInfo(t *testing.T) {
+	yolov5 := NewYoloV5WithBackend(NewFakeBackend(NClasses, 0, 10), 320, NClasses, 0)
+
+	info := yolov5.Info()
+	if info.Size != 320 || info.Classes != NClasses || info.Stride != 0 {
+		t.Fatalf("unexpected info %+v", info)
+	}
+	if len(info.ClassNames) != NClasses || info.ClassNames[0] != "person" {
+		t.Fatal("expected COCO names for an 80 class model")
+	}
+
+	shape, err := yolov5.OutputShape()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(shape, []int{1, 10, PredSize}) {
+		t.Fatalf("unexpected output shape %v", shape)
+	}
+
+	pose := NewYoloV5WithBackend(NewFakeBackend(1, NKeypointsCOCO, 10), 320, 1, NKeypointsCOCO)
+	if pose.Info().ClassNames != nil {
+		t.Fatal("expected no class names for an unnamed 1 class model")
+	}
+	pose.SetClassNames([]string{"person"})
+	if pose.Info().ClassNames[0] != "person" {
+		t.Fatal("expected names set with SetClassNames")
+	}
+
+	if _, err := NewYoloV5WithBackend(NewFakeBackend(3, 0, 10), 320, NClasses, 0).OutputShape(); err == nil {
+		t.Fatal("expected an error for a mismatched output layout")
+	}
+}
@@ -0,0 +1,62 @@
+package goyolov5
+
+import (
+	"image"
+	"image/color"
+)
+
+// NKeypointsCOCO is the number of keypoints in the COCO person pose layout.
+const NKeypointsCOCO = 17
+
+// Keypoint is a single predicted keypoint in image coordinates.
+type Keypoint struct {
+	Point      image.Point
+	Visibility float64
+}
+
+// COCOSkeleton lists the keypoint index pairs joined when drawing a COCO
+// person pose.
+var COCOSkeleton = [][2]int{
+	{15, 13}, {13, 11}, {16, 14}, {14, 12}, {11, 12},
+	{5, 11}, {6, 12}, {5, 6}, {5, 7}, {6, 8},
+	{7, 9}, {8, 10}, {1, 2}, {0, 1}, {0, 2},
+	{1, 3}, {2, 4}, {3, 5}, {4, 6},
+}
+
+// scaleKeypoints converts raw x, y, visibility triplets from the model output
+// into keypoints, applying the same letterbox ratio used for boxes.
+func scaleKeypoints(raw []float32, scaleRatio float64) []Keypoint {
+	if len(raw) == 0 {
+		return nil
+	}
+	keypoints := make([]Keypoint, len(raw)/3)
+	for i := range keypoints {
+		keypoints[i] = Keypoint{
+			Point:      image.Pt(int(float64(raw[i*3])*scaleRatio), int(float64(raw[i*3+1])*scaleRatio)),
+			Visibility: float64(raw[i*3+2]),
+		}
+	}
+	return keypoints
+}
+
+// DrawSkeleton draws each keypoint with a visibility above minVisibility and
+// joins the pairs listed in skeleton when both ends are visible.
+func (img *Tensor) DrawSkeleton(keypoints []Keypoint, skeleton [][2]int, minVisibility float64, col color.Color) {
+	for _, limb := range skeleton {
+		if limb[0] >= len(keypoints) || limb[1] >= len(keypoints) {
+			continue
+		}
+		k1, k2 := keypoints[limb[0]], keypoints[limb[1]]
+		if k1.Visibility < minVisibility || k2.Visibility < minVisibility {
+			continue
+		}
+		img.DrawLine(k1.Point, k2.Point, col)
+	}
+
+	for _, k := range keypoints {
+		if k.Visibility < minVisibility {
+			continue
+		}
+		img.DrawRect(image.Rect(k.Point.X-1, k.Point.Y-1, k.Point.X+1, k.Point.Y+1), col)
+	}
+}
@@ -0,0 +1,59 @@
+package goyolov5
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestPostConfidenceKeypoints(t *testing.T) {
+	yolov5 := &YoloV5{nClasses: 1, nKeypoints: NKeypointsCOCO}
+	predSize := yolov5.predSize()
+
+	tensor := make([]float32, 2*predSize)
+	pred := tensor[predSize:]
+	pred[0], pred[1], pred[2], pred[3], pred[4], pred[5] = 100, 100, 40, 80, 0.9, 0.8
+	for k := 0; k < NKeypointsCOCO; k++ {
+		pred[ClassesOffset+1+k*3] = float32(80 + k)
+		pred[ClassesOffset+1+k*3+1] = float32(60 + 2*k)
+		pred[ClassesOffset+1+k*3+2] = 0.75
+	}
+
+	bboxes, err := yolov5.postConfidence(tensor, 1, NKeypointsCOCO, 2, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bboxes[0]) != 1 {
+		t.Fatalf("expected 1 box, got %d", len(bboxes[0]))
+	}
+
+	keypoints := scaleKeypoints(bboxes[0][0].keypoints, 2.0)
+	if len(keypoints) != NKeypointsCOCO {
+		t.Fatalf("expected %d keypoints, got %d", NKeypointsCOCO, len(keypoints))
+	}
+	if keypoints[3].Point != image.Pt(166, 132) {
+		t.Fatalf("expected (166,132), got %v", keypoints[3].Point)
+	}
+	if keypoints[3].Visibility != 0.75 {
+		t.Fatalf("expected visibility 0.75, got %f", keypoints[3].Visibility)
+	}
+}
+
+func TestDrawSkeleton(t *testing.T) {
+	tensor := NewTensor(image.Rect(0, 0, 20, 20))
+	keypoints := []Keypoint{
+		{Point: image.Pt(2, 2), Visibility: 0.9},
+		{Point: image.Pt(12, 2), Visibility: 0.9},
+		{Point: image.Pt(12, 12), Visibility: 0.1},
+	}
+	red := color.RGBA{255, 0, 0, 255}
+
+	tensor.DrawSkeleton(keypoints, [][2]int{{0, 1}, {1, 2}}, 0.5, red)
+
+	if tensor.RGBAAt(7, 2) != red {
+		t.Fatalf("expected limb between visible keypoints")
+	}
+	if tensor.RGBAAt(12, 7) == red {
+		t.Fatalf("expected no limb to an invisible keypoint")
+	}
+}
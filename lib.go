@@ -1,3 +1,5 @@
+//go:build cgo && !nolibtorch
+
 package goyolov5
 
 // #cgo !cuda LDFLAGS: -lstdc++ -ltorch -ltorch_cpu -lc10
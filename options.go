@@ -0,0 +1,75 @@
+package goyolov5
+
+import (
+	"context"
+	"time"
+)
+
+// InferOption configures a single call to Infer.
+type InferOption func(*inferOptions)
+
+type inferOptions struct {
+	augmentations []Augmentation
+	ctx           context.Context
+	classes       map[uint]bool
+	timings       *Timings
+	annotator     *Annotator
+}
+
+func newInferOptions(opts []InferOption) inferOptions {
+	options := inferOptions{ctx: context.Background()}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// WithContext bounds Infer by ctx. The forward pass itself cannot be
+// interrupted, so ctx is checked before each forward pass and again before
+// NMS, and Infer returns ctx.Err() if it is done by then.
+func WithContext(ctx context.Context) InferOption {
+	return func(o *inferOptions) {
+		o.ctx = ctx
+	}
+}
+
+// WithClasses keeps only predictions of the given class indices. Other classes
+// are dropped before NMS.
+func WithClasses(classes ...uint) InferOption {
+	return func(o *inferOptions) {
+		o.classes = make(map[uint]bool, len(classes))
+		for _, c := range classes {
+			o.classes[c] = true
+		}
+	}
+}
+
+// Timings is the time spent in each stage of an Infer call.
+type Timings struct {
+	// Preprocess is letterboxing and resizing.
+	Preprocess time.Duration
+	// Forward is the model, including every pass and its decoding with
+	// WithAugment.
+	Forward time.Duration
+	// Postprocess is decoding, NMS and scaling back to the input.
+	Postprocess time.Duration
+}
+
+// WithTimings records the time Infer spends in each stage into t.
+func WithTimings(t *Timings) InferOption {
+	return func(o *inferOptions) {
+		o.timings = t
+	}
+}
+
+// filterClasses empties the per-class box lists excluded by WithClasses.
+func (o inferOptions) filterClasses(bboxes [][]Bbox) {
+	if o.classes == nil {
+		return
+	}
+	for c := range bboxes {
+		if !o.classes[uint(c)] {
+			bboxes[c] = nil
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package goyolov5
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Frame is a single input to a Pipeline.
+type Frame struct {
+	Seq       uint64
+	Timestamp time.Time
+	Tensor    *Tensor
+}
+
+// Result is the output of a Pipeline for one Frame.
+type Result struct {
+	Seq         uint64
+	Timestamp   time.Time
+	Predictions []Prediction
+	Err         error
+}
+
+// DropMode selects how a Pipeline sheds frames it cannot keep up with.
+type DropMode int
+
+const (
+	// DropNone processes every frame, blocking the producer when full.
+	DropNone DropMode = iota
+	// DropLatest keeps only the newest waiting frame, so the next frame
+	// processed is always the most recent one.
+	DropLatest
+	// DropStride processes one frame in every Stride.
+	DropStride
+	// DropLatency drops frames older than TargetLatency, by their Timestamp,
+	// when they reach the front of the queue, and the oldest waiting frame
+	// when the queue is full.
+	DropLatency
+)
+
+// DropPolicy configures frame dropping for live streams.
+type DropPolicy struct {
+	Mode          DropMode
+	Stride        int
+	TargetLatency time.Duration
+}
+
+// PipelineStats counts the frames a Pipeline has seen.
+type PipelineStats struct {
+	Received  uint64
+	Dropped   uint64
+	Processed uint64
+}
+
+// Pipeline runs letterboxing, the forward pass and post-processing as
+// concurrent stages, so preparing the next frame and decoding the previous one
+// overlap with the model. Results are emitted in frame order.
+type Pipeline struct {
+	// Accessed atomically, kept first for alignment
+	received  uint64
+	dropped   uint64
+	processed uint64
+
+	yolov5              *YoloV5
+	confidenceThreshold float32
+	nmsThreshold        float64
+	buffer              int
+	policy              DropPolicy
+	now                 func() time.Time
+}
+
+// pipelineItem carries a frame between pipeline stages.
+type pipelineItem struct {
+	frame      Frame
+	input      *Tensor
+	scaleRatio float64
+	rawOutput  []float32
+	shape      []int
+	err        error
+}
+
+// NewPipeline returns a Pipeline for yolov5. buffer bounds the number of frames
+// waiting between each pair of stages.
+func NewPipeline(yolov5 *YoloV5, confidenceThreshold float32, nmsThreshold float64, buffer int) *Pipeline {
+	if buffer < 0 {
+		buffer = 0
+	}
+	return &Pipeline{
+		yolov5:              yolov5,
+		confidenceThreshold: confidenceThreshold,
+		nmsThreshold:        nmsThreshold,
+		buffer:              buffer,
+		now:                 time.Now,
+	}
+}
+
+// SetDropPolicy makes the pipeline shed frames according to policy, keeping
+// live streams real-time when frames arrive faster than the model runs. It
+// must be called before Run. Dropped frames get no Result; see Stats. Small
+// buffers keep latency lowest.
+func (p *Pipeline) SetDropPolicy(policy DropPolicy) {
+	p.policy = policy
+}
+
+// Stats returns the number of frames received, dropped and processed so far.
+func (p *Pipeline) Stats() PipelineStats {
+	return PipelineStats{
+		Received:  atomic.LoadUint64(&p.received),
+		Dropped:   atomic.LoadUint64(&p.dropped),
+		Processed: atomic.LoadUint64(&p.processed),
+	}
+}
+
+// Run processes frames until the channel is closed or ctx is done, and closes
+// the returned channel once every accepted frame has a result. A frame that
+// fails gets a Result with Err set; later frames are still processed.
+func (p *Pipeline) Run(ctx context.Context, frames <-chan Frame) <-chan Result {
+	preprocessed := make(chan pipelineItem, p.buffer)
+	forwarded := make(chan pipelineItem, p.buffer)
+	results := make(chan Result, p.buffer)
+
+	frames = p.ingest(ctx, frames)
+
+	go func() {
+		defer close(preprocessed)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case frame, ok := <-frames:
+				if !ok {
+					return
+				}
+				if p.policy.Mode == DropLatency && p.now().Sub(frame.Timestamp) > p.policy.TargetLatency {
+					atomic.AddUint64(&p.dropped, 1)
+					continue
+				}
+				item := pipelineItem{frame: frame}
+				item.input, _, _, item.scaleRatio, item.err = p.yolov5.preProcess(frame.Tensor)
+				if !sendItem(ctx, preprocessed, item) {
+					return
+				}
+			}
+		}
+	}()
+
+	go func() {
+		defer close(forwarded)
+		for item := range preprocessed {
+			if item.err == nil {
+				item.rawOutput, item.shape, item.err = p.yolov5.backend.Forward(item.input)
+			}
+			if !sendItem(ctx, forwarded, item) {
+				return
+			}
+		}
+	}()
+
+	go func() {
+		defer close(results)
+		for item := range forwarded {
+			result := Result{Seq: item.frame.Seq, Timestamp: item.frame.Timestamp, Err: item.err}
+			if item.err == nil {
+				result.Predictions, result.Err = p.postProcess(item)
+			}
+			atomic.AddUint64(&p.processed, 1)
+			select {
+			case <-ctx.Done():
+				return
+			case results <- result:
+			}
+		}
+	}()
+
+	return results
+}
+
+// ingest counts incoming frames and applies the drop policy, returning the
+// frames left to process.
+func (p *Pipeline) ingest(ctx context.Context, frames <-chan Frame) <-chan Frame {
+	capacity := 0
+	switch p.policy.Mode {
+	case DropLatest:
+		capacity = 1
+	case DropLatency:
+		capacity = p.buffer
+		if capacity < 1 {
+			capacity = 1
+		}
+	}
+	out := make(chan Frame, capacity)
+
+	go func() {
+		defer close(out)
+		var n int
+		for {
+			var frame Frame
+			select {
+			case <-ctx.Done():
+				return
+			case f, ok := <-frames:
+				if !ok {
+					return
+				}
+				frame = f
+			}
+			atomic.AddUint64(&p.received, 1)
+			n++
+
+			switch p.policy.Mode {
+			case DropStride:
+				if p.policy.Stride > 1 && (n-1)%p.policy.Stride != 0 {
+					atomic.AddUint64(&p.dropped, 1)
+					continue
+				}
+			case DropLatest, DropLatency:
+				// Never block the producer: make room by dropping the oldest
+				// waiting frame.
+				for !trySend(out, frame) {
+					select {
+					case <-out:
+						atomic.AddUint64(&p.dropped, 1)
+					default:
+					}
+				}
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case out <- frame:
+			}
+		}
+	}()
+
+	return out
+}
+
+func (p *Pipeline) postProcess(item pipelineItem) ([]Prediction, error) {
+	batchBboxes, err := p.yolov5.postProcess(item.rawOutput, item.shape, p.confidenceThreshold)
+	if err != nil {
+		return nil, err
+	}
+
+	bboxesRes, err := p.yolov5.postNMS(batchBboxes[0], p.nmsThreshold)
+	if err != nil {
+		return nil, err
+	}
+
+	var predictions []Prediction
+	for _, c := range bboxesRes {
+		for _, b := range c {
+			predictions = append(predictions, b.toPrediction(item.scaleRatio, item.frame.Tensor.Rect.Min))
+		}
+	}
+	return predictions, nil
+}
+
+func trySend(ch chan<- Frame, frame Frame) bool {
+	select {
+	case ch <- frame:
+		return true
+	default:
+		return false
+	}
+}
+
+func sendItem(ctx context.Context, ch chan<- pipelineItem, item pipelineItem) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case ch <- item:
+		return true
+	}
+}
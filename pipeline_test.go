@@ -0,0 +1,178 @@
+package goyolov5
+
+import (
+	"context"
+	"image"
+	"testing"
+	"time"
+)
+
+func TestPipeline(t *testing.T) {
+	backend := NewFakeBackend(NClasses, 0, 10,
+		FakeDetection{Rect: image.Rect(10, 10, 60, 110), Confidence: 0.9, ClassIndex: COCO_PERSON, ClassConfidence: 0.9},
+	)
+	yolov5 := NewYoloV5WithBackend(backend, 320, NClasses, 0)
+	pipeline := NewPipeline(yolov5, 0.5, 0.4, 2)
+
+	frames := make(chan Frame)
+	results := pipeline.Run(context.Background(), frames)
+
+	start := time.Unix(0, 0)
+	go func() {
+		defer close(frames)
+		for i := 0; i < 20; i++ {
+			tensor := NewTensor(image.Rect(0, 0, 640, 480))
+			if i == 5 {
+				// Too small to letterbox down to the model size
+				tensor = NewTensor(image.Rect(0, 0, 100, 100))
+			}
+			frames <- Frame{Seq: uint64(i), Timestamp: start.Add(time.Duration(i) * time.Millisecond), Tensor: tensor}
+		}
+	}()
+
+	var seq uint64
+	for result := range results {
+		if result.Seq != seq {
+			t.Fatalf("expected frame %d, got %d", seq, result.Seq)
+		}
+		if result.Timestamp != start.Add(time.Duration(seq)*time.Millisecond) {
+			t.Fatalf("unexpected timestamp %v for frame %d", result.Timestamp, seq)
+		}
+		if seq == 5 {
+			if result.Err == nil {
+				t.Fatal("expected an error for frame 5")
+			}
+		} else {
+			if result.Err != nil {
+				t.Fatal(result.Err)
+			}
+			if len(result.Predictions) != 1 || result.Predictions[0].Rect != image.Rect(20, 20, 120, 220) {
+				t.Fatalf("unexpected predictions %v", result.Predictions)
+			}
+		}
+		seq++
+	}
+	if seq != 20 {
+		t.Fatalf("expected 20 results, got %d", seq)
+	}
+}
+
+func TestPipelineCancel(t *testing.T) {
+	yolov5 := NewYoloV5WithBackend(NewFakeBackend(NClasses, 0, 10), 320, NClasses, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	frames := make(chan Frame)
+	results := NewPipeline(yolov5, 0.5, 0.4, 0).Run(ctx, frames)
+	cancel()
+
+	select {
+	case _, ok := <-results:
+		if ok {
+			// A result raced the cancellation; the channel must still close.
+			for range results {
+			}
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected results to close after cancel")
+	}
+}
+
+// gatedBackend blocks its first forward pass until released.
+type gatedBackend struct {
+	*FakeBackend
+	entered chan struct{}
+	release chan struct{}
+	calls   int
+}
+
+func (b *gatedBackend) Forward(input *Tensor) ([]float32, []int, error) {
+	b.calls++
+	if b.calls == 1 {
+		close(b.entered)
+		<-b.release
+	}
+	return b.FakeBackend.Forward(input)
+}
+
+func collectSeqs(results <-chan Result) []uint64 {
+	var seqs []uint64
+	for result := range results {
+		seqs = append(seqs, result.Seq)
+	}
+	return seqs
+}
+
+func TestPipelineDropLatest(t *testing.T) {
+	backend := &gatedBackend{FakeBackend: NewFakeBackend(NClasses, 0, 10), entered: make(chan struct{}), release: make(chan struct{})}
+	pipeline := NewPipeline(NewYoloV5WithBackend(backend, 320, NClasses, 0), 0.5, 0.4, 0)
+	pipeline.SetDropPolicy(DropPolicy{Mode: DropLatest})
+
+	frames := make(chan Frame)
+	results := pipeline.Run(context.Background(), frames)
+
+	go func() {
+		defer close(frames)
+		frames <- Frame{Seq: 0, Tensor: NewTensor(image.Rect(0, 0, 640, 480))}
+		<-backend.entered
+		// The model is busy: none of these may block the producer
+		for i := 1; i < 10; i++ {
+			frames <- Frame{Seq: uint64(i), Tensor: NewTensor(image.Rect(0, 0, 640, 480))}
+		}
+		close(backend.release)
+	}()
+
+	seqs := collectSeqs(results)
+	if seqs[0] != 0 || seqs[len(seqs)-1] != 9 {
+		t.Fatalf("expected the first and latest frames, got %v", seqs)
+	}
+	stats := pipeline.Stats()
+	if stats.Received != 10 || stats.Processed != uint64(len(seqs)) || stats.Processed+stats.Dropped != 10 {
+		t.Fatalf("unexpected stats %+v for results %v", stats, seqs)
+	}
+	if stats.Dropped < 7 {
+		t.Fatalf("expected at least 7 dropped frames, got %+v", stats)
+	}
+}
+
+func TestPipelineDropStride(t *testing.T) {
+	pipeline := NewPipeline(NewYoloV5WithBackend(NewFakeBackend(NClasses, 0, 10), 320, NClasses, 0), 0.5, 0.4, 1)
+	pipeline.SetDropPolicy(DropPolicy{Mode: DropStride, Stride: 3})
+
+	frames := make(chan Frame)
+	results := pipeline.Run(context.Background(), frames)
+	go func() {
+		defer close(frames)
+		for i := 0; i < 9; i++ {
+			frames <- Frame{Seq: uint64(i), Tensor: NewTensor(image.Rect(0, 0, 640, 480))}
+		}
+	}()
+
+	seqs := collectSeqs(results)
+	if len(seqs) != 3 || seqs[0] != 0 || seqs[1] != 3 || seqs[2] != 6 {
+		t.Fatalf("expected frames 0, 3 and 6, got %v", seqs)
+	}
+	if stats := pipeline.Stats(); stats.Dropped != 6 {
+		t.Fatalf("expected 6 dropped frames, got %+v", stats)
+	}
+}
+
+func TestPipelineDropLatency(t *testing.T) {
+	now := time.Unix(100, 0)
+	pipeline := NewPipeline(NewYoloV5WithBackend(NewFakeBackend(NClasses, 0, 10), 320, NClasses, 0), 0.5, 0.4, 4)
+	pipeline.SetDropPolicy(DropPolicy{Mode: DropLatency, TargetLatency: 200 * time.Millisecond})
+	pipeline.now = func() time.Time { return now }
+
+	frames := make(chan Frame, 4)
+	for i, age := range []time.Duration{time.Second, 100 * time.Millisecond, 300 * time.Millisecond, 0} {
+		frames <- Frame{Seq: uint64(i), Timestamp: now.Add(-age), Tensor: NewTensor(image.Rect(0, 0, 640, 480))}
+	}
+	close(frames)
+
+	seqs := collectSeqs(pipeline.Run(context.Background(), frames))
+	if len(seqs) != 2 || seqs[0] != 1 || seqs[1] != 3 {
+		t.Fatalf("expected frames 1 and 3, got %v", seqs)
+	}
+	if stats := pipeline.Stats(); stats.Dropped != 2 {
+		t.Fatalf("expected 2 dropped frames, got %+v", stats)
+	}
+}
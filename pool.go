@@ -0,0 +1,61 @@
+package goyolov5
+
+import (
+	"context"
+	"errors"
+)
+
+// Pool shares a fixed set of YoloV5 models, e.g. one per GPU or replicas of
+// the same weights, between concurrent callers. A YoloV5 is not safe for
+// concurrent use, so each caller takes a model with Get and returns it with
+// Put.
+type Pool struct {
+	models chan *YoloV5
+	all    []*YoloV5
+}
+
+// NewPool returns a pool of the given models.
+func NewPool(models ...*YoloV5) (*Pool, error) {
+	if len(models) == 0 {
+		return nil, errors.New("pool needs at least one model")
+	}
+	pool := &Pool{models: make(chan *YoloV5, len(models)), all: models}
+	for _, m := range models {
+		pool.models <- m
+	}
+	return pool, nil
+}
+
+// Size is the number of models in the pool.
+func (pool *Pool) Size() int {
+	return len(pool.all)
+}
+
+// Get takes a model from the pool, waiting until one is free or ctx is done.
+func (pool *Pool) Get(ctx context.Context) (*YoloV5, error) {
+	select {
+	case m := <-pool.models:
+		return m, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Put returns a model taken with Get.
+func (pool *Pool) Put(yolov5 *YoloV5) {
+	pool.models <- yolov5
+}
+
+// Infer runs Infer on a free model, bounded by ctx while waiting for a model
+// and as described in WithContext.
+func (pool *Pool) Infer(ctx context.Context, inputTensorRaw *Tensor, confidenceThreshold float32, nmsThreshold float64, annotateTensor *Tensor, opts ...InferOption) ([][]Prediction, error) {
+	yolov5, err := pool.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer pool.Put(yolov5)
+
+	// Copy so the caller's options are never appended to in place
+	opts = append(append([]InferOption(nil), opts...), WithContext(ctx))
+	return yolov5.Infer(inputTensorRaw, confidenceThreshold, nmsThreshold, annotateTensor, opts...)
+}
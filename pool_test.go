@@ -0,0 +1,58 @@
+package goyolov5
+
+import (
+	"context"
+	"image"
+	"testing"
+	"time"
+)
+
+func TestPool(t *testing.T) {
+	backend := NewFakeBackend(NClasses, 0, 10,
+		FakeDetection{Rect: image.Rect(10, 10, 60, 110), Confidence: 0.9, ClassIndex: COCO_PERSON, ClassConfidence: 0.9},
+	)
+	pool, err := NewPool(NewYoloV5WithBackend(backend, 320, NClasses, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	predictions, err := pool.Infer(context.Background(), NewTensor(image.Rect(0, 0, 640, 480)), 0.5, 0.4, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(predictions[0]) != 1 {
+		t.Fatalf("expected 1 prediction, got %d", len(predictions[0]))
+	}
+
+	// Options shared between callers are not appended to in place
+	opts := make([]InferOption, 1, 2)
+	opts[0] = WithClasses(COCO_PERSON)
+	if _, err := pool.Infer(context.Background(), NewTensor(image.Rect(0, 0, 640, 480)), 0.5, 0.4, nil, opts...); err != nil {
+		t.Fatal(err)
+	}
+	if opts[:2][1] != nil {
+		t.Fatal("expected the caller's options left unchanged")
+	}
+
+	// With the only model taken, Get waits until ctx is done
+	yolov5, err := pool.Get(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := pool.Infer(ctx, NewTensor(image.Rect(0, 0, 640, 480)), 0.5, 0.4, nil); err != context.DeadlineExceeded {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	pool.Put(yolov5)
+
+	if _, err := pool.Get(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPoolEmpty(t *testing.T) {
+	if _, err := NewPool(); err == nil {
+		t.Fatal("expected an error for an empty pool")
+	}
+}
@@ -0,0 +1,259 @@
+package goyolov5
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"time"
+)
+
+// RawFormat is the pixel layout of a RawFrame.
+type RawFormat int
+
+const (
+	// RawNV12 is 4:2:0 YUV with a Y plane and an interleaved UV plane.
+	RawNV12 RawFormat = iota
+	// RawI420 is 4:2:0 YUV with Y, U and V planes.
+	RawI420
+	// RawYUYV is packed 4:2:2 YUV, Y0 U Y1 V for each pair of pixels.
+	RawYUYV
+	// RawBGR is packed 8 bit B, G, R.
+	RawBGR
+)
+
+func (f RawFormat) String() string {
+	switch f {
+	case RawNV12:
+		return "NV12"
+	case RawI420:
+		return "I420"
+	case RawYUYV:
+		return "YUYV"
+	case RawBGR:
+		return "BGR"
+	}
+	return "unknown"
+}
+
+// rawPlanes is the number of planes of each format.
+var rawPlanes = map[RawFormat]int{RawNV12: 2, RawI420: 3, RawYUYV: 1, RawBGR: 1}
+
+// ColorSpace is the YUV to RGB conversion of a frame.
+type ColorSpace int
+
+const (
+	// BT601 is limited range BT.601, used by SD video and most webcams.
+	BT601 ColorSpace = iota
+	// BT709 is limited range BT.709, used by HD video.
+	BT709
+	// BT601Full is full range BT.601, used by JPEG.
+	BT601Full
+	// BT709Full is full range BT.709.
+	BT709Full
+)
+
+// yuvMatrix holds a YUV to RGB conversion in 16.16 fixed point.
+type yuvMatrix struct {
+	yOffset, y     int32
+	rv, gu, gv, bu int32
+}
+
+// newYUVMatrix derives a conversion from the luma weights of red and blue.
+// Limited range luma spans [16, 235] and chroma [16, 240].
+func newYUVMatrix(kr, kb float64, full bool) yuvMatrix {
+	kg := 1 - kr - kb
+	yScale, cScale, yOffset := 1.0, 1.0, int32(0)
+	if !full {
+		yScale, cScale, yOffset = 255.0/219, 255.0/224, 16
+	}
+	fixed := func(v float64) int32 { return int32(math.Round(v * (1 << 16))) }
+	return yuvMatrix{
+		yOffset: yOffset,
+		y:       fixed(yScale),
+		rv:      fixed(cScale * 2 * (1 - kr)),
+		gu:      fixed(cScale * 2 * kb * (1 - kb) / kg),
+		gv:      fixed(cScale * 2 * kr * (1 - kr) / kg),
+		bu:      fixed(cScale * 2 * (1 - kb)),
+	}
+}
+
+var yuvMatrices = map[ColorSpace]yuvMatrix{
+	BT601:     newYUVMatrix(0.299, 0.114, false),
+	BT709:     newYUVMatrix(0.2126, 0.0722, false),
+	BT601Full: newYUVMatrix(0.299, 0.114, true),
+	BT709Full: newYUVMatrix(0.2126, 0.0722, true),
+}
+
+// convert converts one YUV pixel into d.
+func (m *yuvMatrix) convert(d []uint8, y, u, v uint8) {
+	yy := (int32(y)-m.yOffset)*m.y + 1<<15
+	u1, v1 := int32(u)-128, int32(v)-128
+	d[0] = clampFixed(yy + m.rv*v1)
+	d[1] = clampFixed(yy - m.gu*u1 - m.gv*v1)
+	d[2] = clampFixed(yy + m.bu*u1)
+}
+
+// RawFrame is a camera frame in a raw pixel format, converted straight into
+// a Tensor or a letterboxed model input without an image.Image in between.
+type RawFrame struct {
+	Format        RawFormat
+	Width, Height int
+	// Planes are Y and UV for NV12; Y, U and V for I420; and the packed
+	// pixels for YUYV and BGR. Chroma planes of 4:2:0 formats are half the
+	// width and height, rounded up.
+	Planes [][]byte
+	// Strides are the bytes between the starts of rows of each plane.
+	Strides []int
+	// ColorSpace converts YUV formats to RGB.
+	ColorSpace ColorSpace
+}
+
+// validate checks the frame's planes hold every row.
+func (f *RawFrame) validate() error {
+	planes, ok := rawPlanes[f.Format]
+	if !ok {
+		return fmt.Errorf("unknown raw format %d", f.Format)
+	}
+	if f.Width <= 0 || f.Height <= 0 {
+		return fmt.Errorf("%v frame must have a positive size, got %dx%d", f.Format, f.Width, f.Height)
+	}
+	if len(f.Planes) != planes || len(f.Strides) != planes {
+		return fmt.Errorf("%v frame needs %d planes and strides, got %d and %d", f.Format, planes, len(f.Planes), len(f.Strides))
+	}
+	if _, ok := yuvMatrices[f.ColorSpace]; !ok && f.Format != RawBGR {
+		return fmt.Errorf("unknown colour space %d", f.ColorSpace)
+	}
+
+	cw, ch := (f.Width+1)/2, (f.Height+1)/2
+	var rows, rowBytes []int
+	switch f.Format {
+	case RawNV12:
+		rows, rowBytes = []int{f.Height, ch}, []int{f.Width, 2 * cw}
+	case RawI420:
+		rows, rowBytes = []int{f.Height, ch, ch}, []int{f.Width, cw, cw}
+	case RawYUYV:
+		rows, rowBytes = []int{f.Height}, []int{4 * cw}
+	case RawBGR:
+		rows, rowBytes = []int{f.Height}, []int{3 * f.Width}
+	}
+	for i := range rows {
+		if f.Strides[i] < rowBytes[i] {
+			return fmt.Errorf("%v plane %d stride %d is shorter than a row of %d bytes", f.Format, i, f.Strides[i], rowBytes[i])
+		}
+		if need := (rows[i]-1)*f.Strides[i] + rowBytes[i]; len(f.Planes[i]) < need {
+			return fmt.Errorf("%v plane %d has %d bytes, %dx%d needs %d", f.Format, i, len(f.Planes[i]), f.Width, f.Height, need)
+		}
+	}
+	return nil
+}
+
+// convertRow converts row y of the frame into d, taking column xs[i] of the
+// frame for pixel i and leaving pixels with a negative column black.
+func (f *RawFrame) convertRow(d []uint8, y int, xs []int) {
+	m := yuvMatrices[f.ColorSpace]
+	switch f.Format {
+	case RawNV12:
+		ys := f.Planes[0][y*f.Strides[0]:]
+		uvs := f.Planes[1][y/2*f.Strides[1]:]
+		for i, x := range xs {
+			if x >= 0 {
+				c := x / 2 * 2
+				m.convert(d[3*i:3*i+3], ys[x], uvs[c], uvs[c+1])
+			}
+		}
+	case RawI420:
+		ys := f.Planes[0][y*f.Strides[0]:]
+		us := f.Planes[1][y/2*f.Strides[1]:]
+		vs := f.Planes[2][y/2*f.Strides[2]:]
+		for i, x := range xs {
+			if x >= 0 {
+				m.convert(d[3*i:3*i+3], ys[x], us[x/2], vs[x/2])
+			}
+		}
+	case RawYUYV:
+		row := f.Planes[0][y*f.Strides[0]:]
+		for i, x := range xs {
+			if x >= 0 {
+				c := x / 2 * 4
+				m.convert(d[3*i:3*i+3], row[c+x%2*2], row[c+1], row[c+3])
+			}
+		}
+	case RawBGR:
+		row := f.Planes[0][y*f.Strides[0]:]
+		for i, x := range xs {
+			if x >= 0 {
+				d[3*i], d[3*i+1], d[3*i+2] = row[3*x+2], row[3*x+1], row[3*x]
+			}
+		}
+	}
+}
+
+// NewTensorFromRaw converts a raw frame into a Width x Height tensor.
+func NewTensorFromRaw(frame RawFrame) (*Tensor, error) {
+	if err := frame.validate(); err != nil {
+		return nil, err
+	}
+	t := NewTensor(image.Rect(0, 0, frame.Width, frame.Height))
+	xs := make([]int, frame.Width)
+	for i := range xs {
+		xs[i] = i
+	}
+	for y := 0; y < frame.Height; y++ {
+		frame.convertRow(t.Pix[y*t.Stride:], y, xs)
+	}
+	return t, nil
+}
+
+// NewLetterboxFromRaw converts a raw frame straight into a size x size model
+// input, sampling only the pixels it keeps. The result and the returned scale
+// ratio are those Infer's preprocessing gives for NewTensorFromRaw's tensor.
+func NewLetterboxFromRaw(frame RawFrame, size int) (*Tensor, float64, error) {
+	if err := frame.validate(); err != nil {
+		return nil, 0, err
+	}
+	side := frame.Width
+	if frame.Height > side {
+		side = frame.Height
+	}
+	if size > side {
+		return nil, 0, fmt.Errorf("cannot upscale a tensor")
+	}
+
+	// Sample like Resize on the frame padded square by ToSquareShape
+	sample := func(d int) int {
+		return int((2*uint64(d) + 1) * uint64(side) / (2 * uint64(size)))
+	}
+	xs := make([]int, size)
+	for i := range xs {
+		if xs[i] = sample(i); xs[i] >= frame.Width {
+			xs[i] = -1
+		}
+	}
+
+	t := NewTensor(image.Rect(0, 0, size, size))
+	for dy := 0; dy < size; dy++ {
+		if y := sample(dy); y < frame.Height {
+			frame.convertRow(t.Pix[dy*t.Stride:], y, xs)
+		}
+	}
+	return t, float64(side) / float64(size), nil
+}
+
+// InferRaw runs Infer on a raw frame, converting it straight into the
+// letterboxed model input. Predictions are in frame coordinates.
+func (yolov5 *YoloV5) InferRaw(frame RawFrame, confidenceThreshold float32, nmsThreshold float64, opts ...InferOption) ([][]Prediction, error) {
+	options := newInferOptions(opts)
+	timings := options.timings
+	if timings == nil {
+		timings = &Timings{}
+	}
+
+	start := time.Now()
+	inputTensor, scaleRatio, err := NewLetterboxFromRaw(frame, yolov5.size)
+	if err != nil {
+		return nil, err
+	}
+	timings.Preprocess = time.Since(start)
+
+	return yolov5.inferLetterboxed(inputTensor, scaleRatio, image.Point{}, confidenceThreshold, nmsThreshold, nil, options, timings)
+}
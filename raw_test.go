@@ -0,0 +1,254 @@
+package goyolov5
+
+import (
+	"bytes"
+	"image"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// referenceYUV converts one pixel in floating point.
+func referenceYUV(cs ColorSpace, y, u, v uint8) [3]float64 {
+	kr, kb := 0.299, 0.114
+	if cs == BT709 || cs == BT709Full {
+		kr, kb = 0.2126, 0.0722
+	}
+	kg := 1 - kr - kb
+	yf, uf, vf := float64(y), float64(u)-128, float64(v)-128
+	if cs == BT601 || cs == BT709 {
+		yf, uf, vf = (yf-16)*255/219, uf*255/224, vf*255/224
+	}
+	rgb := [3]float64{
+		yf + 2*(1-kr)*vf,
+		yf - 2*kb*(1-kb)/kg*uf - 2*kr*(1-kr)/kg*vf,
+		yf + 2*(1-kb)*uf,
+	}
+	for i := range rgb {
+		rgb[i] = math.Max(0, math.Min(255, rgb[i]))
+	}
+	return rgb
+}
+
+// randomNV12 returns a w x h NV12 frame whose rows are padded by pad bytes.
+func randomNV12(rng *rand.Rand, w, h, pad int, cs ColorSpace) RawFrame {
+	cw, ch := (w+1)/2, (h+1)/2
+	ys := make([]byte, h*(w+pad))
+	uvs := make([]byte, ch*(2*cw+pad))
+	randomize(rng, ys, uvs)
+	return RawFrame{Format: RawNV12, Width: w, Height: h, Planes: [][]byte{ys, uvs}, Strides: []int{w + pad, 2*cw + pad}, ColorSpace: cs}
+}
+
+// toI420 splits an NV12 frame's chroma into U and V planes.
+func toI420(f RawFrame) RawFrame {
+	cw, ch := (f.Width+1)/2, (f.Height+1)/2
+	us, vs := make([]byte, cw*ch), make([]byte, cw*ch)
+	for y := 0; y < ch; y++ {
+		for x := 0; x < cw; x++ {
+			us[y*cw+x] = f.Planes[1][y*f.Strides[1]+2*x]
+			vs[y*cw+x] = f.Planes[1][y*f.Strides[1]+2*x+1]
+		}
+	}
+	return RawFrame{Format: RawI420, Width: f.Width, Height: f.Height, Planes: [][]byte{f.Planes[0], us, vs}, Strides: []int{f.Strides[0], cw, cw}, ColorSpace: f.ColorSpace}
+}
+
+// toYUYV packs an NV12 frame of even width as YUYV, repeating its chroma rows.
+func toYUYV(f RawFrame) RawFrame {
+	stride := 2*f.Width + 6
+	pix := make([]byte, f.Height*stride)
+	for y := 0; y < f.Height; y++ {
+		for x := 0; x < f.Width; x += 2 {
+			i, c := y*stride+2*x, y/2*f.Strides[1]+x
+			pix[i+0] = f.Planes[0][y*f.Strides[0]+x]
+			pix[i+1] = f.Planes[1][c]
+			pix[i+2] = f.Planes[0][y*f.Strides[0]+x+1]
+			pix[i+3] = f.Planes[1][c+1]
+		}
+	}
+	return RawFrame{Format: RawYUYV, Width: f.Width, Height: f.Height, Planes: [][]byte{pix}, Strides: []int{stride}, ColorSpace: f.ColorSpace}
+}
+
+func TestRawColorSpaces(t *testing.T) {
+	for _, c := range []struct {
+		cs      ColorSpace
+		y, u, v uint8
+		want    [3]uint8
+	}{
+		{BT601, 16, 128, 128, [3]uint8{0, 0, 0}},
+		{BT601, 235, 128, 128, [3]uint8{255, 255, 255}},
+		{BT709, 126, 128, 128, [3]uint8{128, 128, 128}},
+		{BT601Full, 255, 128, 128, [3]uint8{255, 255, 255}},
+		{BT601Full, 76, 85, 255, [3]uint8{254, 0, 0}},
+		{BT709Full, 54, 99, 255, [3]uint8{254, 0, 0}},
+	} {
+		m := yuvMatrices[c.cs]
+		var got [3]uint8
+		m.convert(got[:], c.y, c.u, c.v)
+		if got != c.want {
+			t.Errorf("colour space %d YUV %d %d %d: got %v, want %v", c.cs, c.y, c.u, c.v, got, c.want)
+		}
+	}
+
+	// Every Y, U, V on a coarse grid agrees with the floating point reference
+	for _, cs := range []ColorSpace{BT601, BT709, BT601Full, BT709Full} {
+		m := yuvMatrices[cs]
+		for y := 0; y < 256; y += 5 {
+			for u := 0; u < 256; u += 3 {
+				for v := 0; v < 256; v += 3 {
+					var got [3]uint8
+					m.convert(got[:], uint8(y), uint8(u), uint8(v))
+					want := referenceYUV(cs, uint8(y), uint8(u), uint8(v))
+					for i := range got {
+						if math.Abs(float64(got[i])-want[i]) > 1 {
+							t.Fatalf("colour space %d YUV %d %d %d: got %v, want %v", cs, y, u, v, got, want)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestNewTensorFromRaw(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	nv12 := randomNV12(rng, 17, 11, 5, BT709)
+	got, err := NewTensorFromRaw(nv12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Rect != image.Rect(0, 0, 17, 11) {
+		t.Fatalf("unexpected bounds %v", got.Rect)
+	}
+	m := yuvMatrices[BT709]
+	for _, p := range []image.Point{{0, 0}, {16, 10}, {5, 3}, {16, 0}} {
+		var want [3]uint8
+		c := p.Y/2*nv12.Strides[1] + p.X/2*2
+		m.convert(want[:], nv12.Planes[0][p.Y*nv12.Strides[0]+p.X], nv12.Planes[1][c], nv12.Planes[1][c+1])
+		i := got.PixOffset(p.X, p.Y)
+		if !bytes.Equal(got.Pix[i:i+3], want[:]) {
+			t.Errorf("pixel %v: got %v, want %v", p, got.Pix[i:i+3], want)
+		}
+	}
+
+	i420, err := NewTensorFromRaw(toI420(nv12))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(i420.Pix, got.Pix) {
+		t.Error("expected I420 to match NV12 with the same samples")
+	}
+
+	even := randomNV12(rng, 16, 9, 0, BT601)
+	want, _ := NewTensorFromRaw(even)
+	yuyv, err := NewTensorFromRaw(toYUYV(even))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(yuyv.Pix, want.Pix) {
+		t.Error("expected YUYV to match NV12 with the same samples")
+	}
+
+	// BGR swaps channel order and skips row padding
+	bgr := RawFrame{Format: RawBGR, Width: 2, Height: 2, Strides: []int{8}, Planes: [][]byte{{
+		1, 2, 3, 4, 5, 6, 0xee, 0xee,
+		7, 8, 9, 10, 11, 12,
+	}}}
+	tensor, err := NewTensorFromRaw(bgr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(tensor.Pix, []byte{3, 2, 1, 6, 5, 4, 9, 8, 7, 12, 11, 10}) {
+		t.Errorf("unexpected BGR conversion %v", tensor.Pix)
+	}
+}
+
+func TestNewLetterboxFromRaw(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	yolov5 := &YoloV5{}
+	for _, c := range []struct{ w, h, size int }{{64, 48, 32}, {30, 50, 32}, {37, 23, 20}, {32, 32, 32}, {33, 17, 33}} {
+		frame := randomNV12(rng, c.w, c.h, 3, BT601)
+		tensor, err := NewTensorFromRaw(frame)
+		if err != nil {
+			t.Fatal(err)
+		}
+		yolov5.size = c.size
+		want, _, _, wantRatio, err := yolov5.preProcess(tensor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, ratio, err := NewLetterboxFromRaw(frame, c.size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Rect != want.Rect || !bytes.Equal(got.Pix, want.Pix) || ratio != wantRatio {
+			t.Errorf("%dx%d to %d: differs from converting then preprocessing", c.w, c.h, c.size)
+		}
+	}
+
+	if _, _, err := NewLetterboxFromRaw(randomNV12(rng, 20, 10, 0, BT601), 32); err == nil {
+		t.Error("expected an error upscaling")
+	}
+}
+
+func TestInferRaw(t *testing.T) {
+	yolov5 := NewYoloV5WithBackend(NewFakeBackend(NClasses, 0, 10,
+		FakeDetection{Rect: image.Rect(10, 10, 60, 110), Confidence: 0.9, ClassIndex: COCO_PERSON, ClassConfidence: 0.9},
+		FakeDetection{Rect: image.Rect(100, 50, 150, 100), Confidence: 0.8, ClassIndex: 2, ClassConfidence: 0.8},
+	), 160, NClasses, 0)
+
+	frame := randomNV12(rand.New(rand.NewSource(3)), 320, 240, 0, BT601)
+	tensor, err := NewTensorFromRaw(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := yolov5.Infer(tensor, 0.5, 0.4, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	timings := &Timings{}
+	got, err := yolov5.InferRaw(frame, 0.5, 0.4, WithTimings(timings))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got[0]) != 2 || !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the predictions of Infer, got %+v, want %+v", got, want)
+	}
+	if timings.Preprocess <= 0 {
+		t.Error("expected preprocessing timed")
+	}
+}
+
+func TestRawFrameValidate(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	valid := randomNV12(rng, 9, 7, 0, BT601)
+	for name, frame := range map[string]RawFrame{
+		"format":       {Format: RawFormat(9), Width: 9, Height: 7},
+		"size":         {Format: RawNV12, Width: 0, Height: 7, Planes: valid.Planes, Strides: valid.Strides},
+		"planes":       {Format: RawI420, Width: 9, Height: 7, Planes: valid.Planes, Strides: valid.Strides},
+		"colour space": {Format: RawNV12, Width: 9, Height: 7, Planes: valid.Planes, Strides: valid.Strides, ColorSpace: ColorSpace(9)},
+		"stride":       {Format: RawNV12, Width: 9, Height: 7, Planes: valid.Planes, Strides: []int{9, 9}},
+		"short plane":  {Format: RawNV12, Width: 9, Height: 7, Planes: [][]byte{valid.Planes[0][:62], valid.Planes[1]}, Strides: valid.Strides},
+	} {
+		if _, err := NewTensorFromRaw(frame); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if _, err := NewTensorFromRaw(valid); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func BenchmarkNewLetterboxFromRaw(b *testing.B) {
+	frame := randomNV12(rand.New(rand.NewSource(1)), 1920, 1080, 0, BT709)
+	b.Run("Letterbox", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			NewLetterboxFromRaw(frame, 640)
+		}
+	})
+	b.Run("Tensor", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			NewTensorFromRaw(frame)
+		}
+	})
+}
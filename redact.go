@@ -0,0 +1,382 @@
+package goyolov5
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// maxBlurSigma is the largest blur BlurRect runs at full resolution. Larger
+// blurs run on a copy shrunk by block averaging and are interpolated back up,
+// so their cost does not grow with sigma.
+const maxBlurSigma = 4.0
+
+// BlurRect Gaussian blurs the part of rect inside the image. Pixels outside
+// rect are not read, so nothing bleeds in from around it.
+func (img *Tensor) BlurRect(rect image.Rectangle, sigma float64) {
+	rect = rect.Intersect(img.Rect)
+	if rect.Empty() || sigma <= 0 {
+		return
+	}
+
+	w, h := rect.Dx(), rect.Dy()
+	buf := make([]float64, 3*w*h)
+	for y := 0; y < h; y++ {
+		row := img.Pix[img.PixOffset(rect.Min.X, rect.Min.Y+y):]
+		for i := 0; i < 3*w; i++ {
+			buf[3*w*y+i] = float64(row[i])
+		}
+	}
+
+	if factor := int(math.Ceil(sigma / maxBlurSigma)); factor > 1 {
+		small, sw, sh := shrink(buf, w, h, factor)
+		gaussianBlur(small, sw, sh, sigma/float64(factor))
+		enlarge(small, sw, sh, buf, w, h, factor)
+	} else {
+		gaussianBlur(buf, w, h, sigma)
+	}
+
+	for y := 0; y < h; y++ {
+		row := img.Pix[img.PixOffset(rect.Min.X, rect.Min.Y+y):]
+		for i := 0; i < 3*w; i++ {
+			row[i] = toUint8(buf[3*w*y+i])
+		}
+	}
+}
+
+// gaussianBlur blurs a w x h RGB buffer in place with separable passes,
+// clamping at its edges.
+func gaussianBlur(buf []float64, w, h int, sigma float64) {
+	radius := int(math.Ceil(3 * sigma))
+	kernel := make([]float64, 2*radius+1)
+	var sum float64
+	for i := range kernel {
+		d := float64(i - radius)
+		kernel[i] = math.Exp(-d * d / (2 * sigma * sigma))
+		sum += kernel[i]
+	}
+	for i := range kernel {
+		kernel[i] /= sum
+	}
+
+	tmp := make([]float64, len(buf))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var r, g, b float64
+			for k, weight := range kernel {
+				sx := clamp(x+k-radius, 0, w-1)
+				i := 3 * (w*y + sx)
+				r += weight * buf[i]
+				g += weight * buf[i+1]
+				b += weight * buf[i+2]
+			}
+			i := 3 * (w*y + x)
+			tmp[i], tmp[i+1], tmp[i+2] = r, g, b
+		}
+	}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var r, g, b float64
+			for k, weight := range kernel {
+				sy := clamp(y+k-radius, 0, h-1)
+				i := 3 * (w*sy + x)
+				r += weight * tmp[i]
+				g += weight * tmp[i+1]
+				b += weight * tmp[i+2]
+			}
+			i := 3 * (w*y + x)
+			buf[i], buf[i+1], buf[i+2] = r, g, b
+		}
+	}
+}
+
+// shrink averages factor x factor blocks of a w x h RGB buffer, cutting
+// blocks short at its right and bottom edges.
+func shrink(buf []float64, w, h, factor int) ([]float64, int, int) {
+	sw, sh := (w+factor-1)/factor, (h+factor-1)/factor
+	small := make([]float64, 3*sw*sh)
+	counts := make([]float64, sw*sh)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			j := sw*(y/factor) + x/factor
+			i := 3 * (w*y + x)
+			small[3*j] += buf[i]
+			small[3*j+1] += buf[i+1]
+			small[3*j+2] += buf[i+2]
+			counts[j]++
+		}
+	}
+	for j, n := range counts {
+		small[3*j] /= n
+		small[3*j+1] /= n
+		small[3*j+2] /= n
+	}
+	return small, sw, sh
+}
+
+// enlarge bilinearly interpolates a buffer shrunk by factor back into the
+// w x h buffer dst, between the centres of its blocks.
+func enlarge(small []float64, sw, sh int, dst []float64, w, h, factor int) {
+	// Neighbouring columns and the weight of the right one, for each x
+	x0s, x1s, txs := make([]int, w), make([]int, w), make([]float64, w)
+	for x := range x0s {
+		x0s[x], x1s[x], txs[x] = blockSample(x, factor, sw)
+	}
+	for y := 0; y < h; y++ {
+		y0, y1, ty := blockSample(y, factor, sh)
+		top, bottom := small[3*sw*y0:], small[3*sw*y1:]
+		for x := 0; x < w; x++ {
+			x0, x1, tx := 3*x0s[x], 3*x1s[x], txs[x]
+			i := 3 * (w*y + x)
+			for c := 0; c < 3; c++ {
+				t := top[x0+c] + (top[x1+c]-top[x0+c])*tx
+				b := bottom[x0+c] + (bottom[x1+c]-bottom[x0+c])*tx
+				dst[i+c] = t + (b-t)*ty
+			}
+		}
+	}
+}
+
+// blockSample returns the blocks either side of pixel p of a buffer shrunk by
+// factor to n blocks, and the weight of the second.
+func blockSample(p, factor, n int) (int, int, float64) {
+	v := (float64(p)+0.5)/float64(factor) - 0.5
+	if v <= 0 {
+		return 0, 0, 0
+	}
+	if v >= float64(n-1) {
+		return n - 1, n - 1, 0
+	}
+	i := int(v)
+	return i, i + 1, v - float64(i)
+}
+
+// PixelateRect replaces blocks of blockSize x blockSize pixels, aligned to the
+// top left of rect, with their mean colour. Blocks at the edges of rect are
+// cut short.
+func (img *Tensor) PixelateRect(rect image.Rectangle, blockSize int) {
+	rect = rect.Intersect(img.Rect)
+	if rect.Empty() || blockSize <= 1 {
+		return
+	}
+	for by := rect.Min.Y; by < rect.Max.Y; by += blockSize {
+		for bx := rect.Min.X; bx < rect.Max.X; bx += blockSize {
+			block := image.Rect(bx, by, bx+blockSize, by+blockSize).Intersect(rect)
+			var r, g, b int
+			for y := block.Min.Y; y < block.Max.Y; y++ {
+				row := img.Pix[img.PixOffset(block.Min.X, y):img.PixOffset(block.Max.X, y)]
+				for i := 0; i < len(row); i += 3 {
+					r += int(row[i])
+					g += int(row[i+1])
+					b += int(row[i+2])
+				}
+			}
+			n := block.Dx() * block.Dy()
+			img.FillRect(block, color.RGBA{uint8((r + n/2) / n), uint8((g + n/2) / n), uint8((b + n/2) / n), 255})
+		}
+	}
+}
+
+// BlurMask Gaussian blurs the pixels covered by mask, blending by the mask's
+// alpha. Partly transparent mask pixels keep some of the original, so use an
+// opaque mask where nothing may show through.
+func (img *Tensor) BlurMask(mask image.Image, sigma float64) {
+	img.applyMask(mask, func(t *Tensor) { t.BlurRect(t.Rect, sigma) })
+}
+
+// PixelateMask pixelates the pixels covered by mask, blending by the mask's
+// alpha. Blocks are aligned to the top left of the mask's bounds.
+func (img *Tensor) PixelateMask(mask image.Image, blockSize int) {
+	img.applyMask(mask, func(t *Tensor) { t.PixelateRect(t.Rect, blockSize) })
+}
+
+// FillMask fills the pixels covered by mask with col, blending by the mask's
+// alpha.
+func (img *Tensor) FillMask(mask image.Image, col color.Color) {
+	img.applyMask(mask, func(t *Tensor) { t.FillRect(t.Rect, col) })
+}
+
+// applyMask runs op on a copy of the part of the image under mask, then blends
+// the copy back in by the mask's alpha.
+func (img *Tensor) applyMask(mask image.Image, op func(*Tensor)) {
+	r := mask.Bounds().Intersect(img.Rect)
+	if r.Empty() {
+		return
+	}
+	redacted := NewTensor(r)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		i := img.PixOffset(r.Min.X, y)
+		copy(redacted.Pix[redacted.PixOffset(r.Min.X, y):], img.Pix[i:i+3*r.Dx()])
+	}
+	op(redacted)
+
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			_, _, _, a := mask.At(x, y).RGBA()
+			if a == 0 {
+				continue
+			}
+			i, j := img.PixOffset(x, y), redacted.PixOffset(x, y)
+			for c := 0; c < 3; c++ {
+				img.Pix[i+c] = uint8((uint32(redacted.Pix[j+c])*a + uint32(img.Pix[i+c])*(0xffff-a) + 0x7fff) / 0xffff)
+			}
+		}
+	}
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+func toUint8(v float64) uint8 {
+	if v <= 0 {
+		return 0
+	}
+	if v >= 255 {
+		return 255
+	}
+	return uint8(v + 0.5)
+}
+
+// RedactStyle is how a Redactor hides regions.
+type RedactStyle int
+
+const (
+	// RedactBlur Gaussian blurs regions.
+	RedactBlur RedactStyle = iota
+	// RedactPixelate replaces regions with coarse blocks.
+	RedactPixelate
+	// RedactFill paints regions a solid colour.
+	RedactFill
+)
+
+func (s RedactStyle) String() string {
+	switch s {
+	case RedactBlur:
+		return "blur"
+	case RedactPixelate:
+		return "pixelate"
+	case RedactFill:
+		return "fill"
+	}
+	return "unknown"
+}
+
+// Redactor hides the predicted regions of selected classes, such as people or
+// faces, before frames are stored. The zero value blurs every prediction with
+// a strength scaled to its box.
+type Redactor struct {
+	Style RedactStyle
+	// Classes are the class indices redacted, or every class if empty.
+	Classes []uint
+	// Margin grows boxes by a fraction of their width and height on each
+	// side, and MarginPixels by a fixed number of pixels, so the edges of
+	// loosely fitting boxes are covered too.
+	Margin       float64
+	MarginPixels int
+	// Sigma is the blur's standard deviation, or 0 for a sixth of the larger
+	// side of each box.
+	Sigma float64
+	// BlockSize is the pixelation block size, or 0 for an eighth of the
+	// larger side of each box.
+	BlockSize int
+	// Color is the fill colour, black if zero.
+	Color color.RGBA
+}
+
+// Region is the area a prediction's box redacts, with margins, within bounds.
+func (r *Redactor) Region(pred Prediction, bounds image.Rectangle) image.Rectangle {
+	return expandRect(pred.Rect, r.Margin, r.MarginPixels).Intersect(bounds)
+}
+
+// Redact hides the regions of the predictions of the selected classes, and
+// returns how many it hid.
+func (r *Redactor) Redact(img *Tensor, predictions []Prediction) int {
+	var n int
+	for _, pred := range predictions {
+		if !r.selected(pred.ClassIndex) {
+			continue
+		}
+		region := r.Region(pred, img.Rect)
+		if region.Empty() {
+			continue
+		}
+		r.RedactRect(img, region)
+		n++
+	}
+	return n
+}
+
+// RedactRect hides rect in the Redactor's style.
+func (r *Redactor) RedactRect(img *Tensor, rect image.Rectangle) {
+	switch r.Style {
+	case RedactBlur:
+		img.BlurRect(rect, r.sigma(rect))
+	case RedactPixelate:
+		img.PixelateRect(rect, r.blockSize(rect))
+	case RedactFill:
+		img.FillRect(rect, r.fill())
+	}
+}
+
+// RedactMask hides the pixels covered by mask in the Redactor's style, sized
+// to the mask's bounds.
+func (r *Redactor) RedactMask(img *Tensor, mask image.Image) {
+	bounds := mask.Bounds()
+	switch r.Style {
+	case RedactBlur:
+		img.BlurMask(mask, r.sigma(bounds))
+	case RedactPixelate:
+		img.PixelateMask(mask, r.blockSize(bounds))
+	case RedactFill:
+		img.FillMask(mask, r.fill())
+	}
+}
+
+func (r *Redactor) selected(classIndex uint) bool {
+	if len(r.Classes) == 0 {
+		return true
+	}
+	for _, c := range r.Classes {
+		if c == classIndex {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Redactor) sigma(rect image.Rectangle) float64 {
+	if r.Sigma > 0 {
+		return r.Sigma
+	}
+	return math.Max(float64(maxSide(rect))/6, 1)
+}
+
+func (r *Redactor) blockSize(rect image.Rectangle) int {
+	if r.BlockSize > 0 {
+		return r.BlockSize
+	}
+	if size := maxSide(rect) / 8; size > 2 {
+		return size
+	}
+	return 2
+}
+
+func (r *Redactor) fill() color.RGBA {
+	col := r.Color
+	col.A = 255
+	return col
+}
+
+func maxSide(rect image.Rectangle) int {
+	if rect.Dx() > rect.Dy() {
+		return rect.Dx()
+	}
+	return rect.Dy()
+}
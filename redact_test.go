@@ -0,0 +1,204 @@
+package goyolov5
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// checkerboard returns a tensor of 1 pixel black and white squares.
+func checkerboard(w, h int) *Tensor {
+	img := NewTensor(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if (x+y)%2 == 0 {
+				img.Set(x, y, color.White)
+			}
+		}
+	}
+	return img
+}
+
+func TestBlurRect(t *testing.T) {
+	img := checkerboard(40, 40)
+	img.BlurRect(image.Rect(10, 10, 30, 30), 2)
+
+	for y := 12; y < 28; y++ {
+		for x := 12; x < 28; x++ {
+			if c := img.RGBAAt(x, y); c.R < 120 || c.R > 135 || c.R != c.G || c.G != c.B {
+				t.Fatalf("expected mid grey inside the blur, got %v at %d,%d", c, x, y)
+			}
+		}
+	}
+	for _, p := range []image.Point{{9, 10}, {30, 30}, {0, 0}, {10, 9}} {
+		want := uint8(0)
+		if (p.X+p.Y)%2 == 0 {
+			want = 255
+		}
+		if c := img.RGBAAt(p.X, p.Y); c.R != want {
+			t.Errorf("expected %v untouched, got %v", p, c)
+		}
+	}
+
+	// A flat rect stays flat up to its edges
+	flat := NewTensor(image.Rect(0, 0, 10, 10))
+	flat.FillRect(flat.Rect, color.RGBA{200, 100, 50, 255})
+	flat.FillRect(image.Rect(0, 0, 10, 2), color.White)
+	flat.BlurRect(image.Rect(0, 2, 10, 10), 3)
+	if c := flat.RGBAAt(0, 2); c != (color.RGBA{200, 100, 50, 255}) {
+		t.Errorf("expected nothing to bleed in from outside the rect, got %v", c)
+	}
+}
+
+func TestBlurRectLarge(t *testing.T) {
+	// Sigmas above maxBlurSigma blur a shrunken copy
+	img := checkerboard(300, 200)
+	img.FillRect(image.Rect(150, 0, 300, 200), color.White)
+	img.BlurRect(image.Rect(20, 20, 280, 180), 30)
+
+	// The checkerboard averages to grey, and the step to white becomes a ramp
+	if c := img.RGBAAt(40, 100); c.R < 120 || c.R > 135 || c.R != c.G || c.G != c.B {
+		t.Errorf("expected mid grey, got %v", c)
+	}
+	prev := uint8(0)
+	for x := 40; x < 260; x++ {
+		c := img.RGBAAt(x, 100)
+		if c.R < prev {
+			t.Fatalf("expected a ramp from grey to white, got %d after %d at x %d", c.R, prev, x)
+		}
+		prev = c.R
+	}
+	if c := img.RGBAAt(150, 100); c.R < 160 || c.R > 230 {
+		t.Errorf("expected the step blurred, got %v", c)
+	}
+	if c := img.RGBAAt(19, 20); c.R != 0 && c.R != 255 {
+		t.Errorf("expected outside the rect untouched, got %v", c)
+	}
+
+	flat := NewTensor(image.Rect(0, 0, 101, 67))
+	flat.FillRect(flat.Rect, color.RGBA{200, 100, 50, 255})
+	flat.BlurRect(flat.Rect, 25)
+	for i := 0; i < len(flat.Pix); i += 3 {
+		if flat.Pix[i] != 200 || flat.Pix[i+1] != 100 || flat.Pix[i+2] != 50 {
+			t.Fatalf("expected a flat rect to stay flat, got %v", flat.Pix[i:i+3])
+		}
+	}
+}
+
+func TestPixelateRect(t *testing.T) {
+	img := NewTensor(image.Rect(0, 0, 10, 10))
+	img.Set(0, 0, color.RGBA{100, 200, 40, 255})
+	img.Set(1, 1, color.RGBA{100, 200, 40, 255})
+	img.Set(5, 5, color.White)
+	img.PixelateRect(image.Rect(0, 0, 5, 5), 4)
+
+	for _, p := range []image.Point{{0, 0}, {3, 3}, {1, 2}} {
+		if c := img.RGBAAt(p.X, p.Y); c != (color.RGBA{13, 25, 5, 255}) {
+			t.Errorf("expected the block mean at %v, got %v", p, c)
+		}
+	}
+	// The cut short block in the corner is its own mean
+	if c := img.RGBAAt(4, 4); c != (color.RGBA{0, 0, 0, 255}) {
+		t.Errorf("expected black at 4,4, got %v", c)
+	}
+	if c := img.RGBAAt(5, 5); c != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("expected 5,5 outside the rect untouched, got %v", c)
+	}
+}
+
+func TestFillMask(t *testing.T) {
+	img := NewTensor(image.Rect(0, 0, 10, 10))
+	mask := image.NewAlpha(image.Rect(2, 2, 6, 6))
+	mask.SetAlpha(3, 3, color.Alpha{255})
+	mask.SetAlpha(4, 4, color.Alpha{128})
+	img.FillMask(mask, color.RGBA{200, 0, 0, 255})
+
+	if c := img.RGBAAt(3, 3); c.R != 200 {
+		t.Errorf("expected the opaque mask pixel filled, got %v", c)
+	}
+	if c := img.RGBAAt(4, 4); c.R != 100 {
+		t.Errorf("expected the half transparent mask pixel blended, got %v", c)
+	}
+	if c := img.RGBAAt(2, 2); c.R != 0 {
+		t.Errorf("expected the transparent mask pixel untouched, got %v", c)
+	}
+}
+
+func TestBlurMask(t *testing.T) {
+	img := checkerboard(20, 20)
+	mask := image.NewAlpha(image.Rect(0, 0, 20, 20))
+	for y := 5; y < 15; y++ {
+		for x := 5; x < 10; x++ {
+			mask.SetAlpha(x, y, color.Alpha{255})
+		}
+	}
+	img.BlurMask(mask, 2)
+
+	if c := img.RGBAAt(7, 10); c.R < 100 || c.R > 155 {
+		t.Errorf("expected grey under the mask, got %v", c)
+	}
+	if c := img.RGBAAt(12, 10); c.R != 255 {
+		t.Errorf("expected white outside the mask, got %v", c)
+	}
+}
+
+func TestRedactor(t *testing.T) {
+	preds := []Prediction{
+		{Rect: image.Rect(20, 20, 40, 60), ClassIndex: 0},
+		{Rect: image.Rect(60, 60, 80, 80), ClassIndex: 2},
+	}
+
+	img := checkerboard(100, 100)
+	r := &Redactor{Style: RedactFill, Classes: []uint{0}, Margin: 0.1, MarginPixels: 1, Color: color.RGBA{R: 9}}
+	if region := r.Region(preds[0], img.Rect); region != image.Rect(17, 15, 43, 65) {
+		t.Errorf("unexpected region %v", region)
+	}
+	if n := r.Redact(img, preds); n != 1 {
+		t.Fatalf("expected 1 redaction, got %d", n)
+	}
+	for _, p := range []image.Point{{17, 15}, {42, 64}, {30, 40}} {
+		if c := img.RGBAAt(p.X, p.Y); c != (color.RGBA{9, 0, 0, 255}) {
+			t.Errorf("expected fill at %v, got %v", p, c)
+		}
+	}
+	if c := img.RGBAAt(70, 70); c.R != 255 {
+		t.Errorf("expected the unselected class untouched, got %v", c)
+	}
+
+	// Boxes at the border are clipped to the image
+	edge := Prediction{Rect: image.Rect(-10, 90, 10, 110)}
+	if region := (&Redactor{}).Region(edge, img.Rect); region != image.Rect(0, 90, 10, 100) {
+		t.Errorf("unexpected clipped region %v", region)
+	}
+
+	for _, style := range []RedactStyle{RedactBlur, RedactPixelate} {
+		img := checkerboard(100, 100)
+		(&Redactor{Style: style}).Redact(img, preds)
+		for _, p := range preds {
+			var white int
+			for y := p.Rect.Min.Y; y < p.Rect.Max.Y; y++ {
+				for x := p.Rect.Min.X; x < p.Rect.Max.X; x++ {
+					if img.RGBAAt(x, y).R == 255 {
+						white++
+					}
+				}
+			}
+			if white > 0 {
+				t.Errorf("%v left %d original white pixels in %v", style, white, p.Rect)
+			}
+		}
+	}
+}
+
+func BenchmarkBlurRect(b *testing.B) {
+	img := NewTensor(image.Rect(0, 0, 1920, 1080))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i * 7)
+	}
+	// A person close to the camera, at the Redactor's default strength
+	box := image.Rect(600, 40, 1200, 1040)
+	sigma := (&Redactor{}).sigma(box)
+	for i := 0; i < b.N; i++ {
+		img.BlurRect(box, sigma)
+	}
+}
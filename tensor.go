@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"image"
 	"image/color"
-	"image/draw"
 	"log"
 	"unsafe"
 )
@@ -30,15 +29,26 @@ func NewTensor(r image.Rectangle) *Tensor {
 	return &Tensor{Pix: make([]uint8, 3*w*h), Stride: 3 * w, Rect: r}
 }
 
+// NewTensorFromImage copies an image into a new tensor with the same bounds.
+// *Tensor, *image.RGBA, *image.NRGBA, *image.YCbCr and *image.Gray images are
+// copied directly; others go through their colour model, pixel by pixel. Both
+// give the same result.
 func NewTensorFromImage(i image.Image) *Tensor {
-	r := i.Bounds()
-	w, h := r.Dx(), r.Dy()
-	newTensor := &Tensor{Pix: make([]uint8, 3*w*h), Stride: 3 * w, Rect: r}
-
-	for y := 0; y < h; y++ {
-		for x := 0; x < w; x++ {
-			newTensor.Set(x, y, i.At(x, y))
-		}
+	newTensor := NewTensor(i.Bounds())
+
+	switch src := i.(type) {
+	case *Tensor:
+		newTensor.copyTensor(src)
+	case *image.RGBA:
+		newTensor.copyRGBA(src)
+	case *image.NRGBA:
+		newTensor.copyNRGBA(src)
+	case *image.YCbCr:
+		newTensor.copyYCbCr(src)
+	case *image.Gray:
+		newTensor.copyGray(src)
+	default:
+		newTensor.copyImage(src)
 	}
 
 	return newTensor
@@ -96,47 +106,27 @@ func (c RGB) RGBA() (r, g, b, a uint32) {
 	return
 }
 
+// ToSquareShape copies the image into the top left of a zero-padded square
+// whose origin is (0, 0), returning the padding added to the right and bottom.
 func (p *Tensor) ToSquareShape() (*Tensor, int, int, error) {
-	sb := p.Bounds()
-
-	w := float64(sb.Max.X)
-	h := float64(sb.Max.Y)
-
-	fast := false
-
-	if w > h {
-		h = w
-		fast = true
-	} else {
-		w = h
+	sw, sh := p.Rect.Dx(), p.Rect.Dy()
+	side := sw
+	if sh > side {
+		side = sh
 	}
 
-	db := image.Rect(0, 0, int(w), int(h))
-
-	// Now Center
-	sw := sb.Max.X
-	sh := sb.Max.Y
-	dw := db.Max.X
-	dh := db.Max.Y
-	dr := image.Rect(
-		0,
-		0,
-		sw,
-		sh)
+	dst := NewTensor(image.Rect(0, 0, side, side))
 
-	extraX := dw - sw
-	extraY := dh - sh
-
-	dst := NewTensor(db)
-
-	// draw the src image onto dst
-	if fast {
-		// dst.Pix[0:len(p.Pix)] = p.Pix
-		copy(dst.Pix, p.Pix)
+	// Contiguous images wider than tall copy in one go
+	if p.Stride == 3*sw && sw == side {
+		copy(dst.Pix, p.Pix[:3*sw*sh])
 	} else {
-		draw.Draw(dst, dr, p, p.Bounds().Min, draw.Src)
+		for y := 0; y < sh; y++ {
+			i := p.PixOffset(p.Rect.Min.X, p.Rect.Min.Y+y)
+			copy(dst.Pix[y*dst.Stride:], p.Pix[i:i+3*sw])
+		}
 	}
-	return dst, extraX, extraY, nil
+	return dst, side - sw, side - sh, nil
 }
 
 func (p *Tensor) Set(x, y int, c color.Color) {
@@ -177,14 +167,18 @@ func (img *Tensor) Resize(targetSize int) (*Tensor, float64, error) {
 		return nil, 0.0, fmt.Errorf("cannot upscale a tensor")
 	}
 
-	newTensor := NewTensor(image.Rect(0, 0, targetSize, targetSize))
+	return img.resize(targetSize, targetSize), float64(img.Bounds().Dx()) / float64(targetSize), nil
+}
+
+// resize scales the image to w x h with nearest neighbour sampling, up or
+// down, into a new tensor whose origin is (0, 0).
+func (img *Tensor) resize(w, h int) *Tensor {
+	newTensor := NewTensor(image.Rect(0, 0, w, h))
 
 	dr := newTensor.Bounds()
 	adr := newTensor.Bounds()
 	sr := img.Bounds()
 
-	ratio := float64(sr.Dx()) / float64(dr.Dx())
-
 	dw2 := uint64(dr.Dx()) * 2
 	dh2 := uint64(dr.Dy()) * 2
 	sw := uint64(sr.Dx())
@@ -203,7 +197,7 @@ func (img *Tensor) Resize(targetSize int) (*Tensor, float64, error) {
 			newTensor.Pix[d+2] = uint8(pb >> 8)
 		}
 	}
-	return newTensor, ratio, nil
+	return newTensor
 }
 
 // HLine draws a horizontal line
@@ -228,6 +222,58 @@ func (img *Tensor) DrawRect(rect image.Rectangle, col color.Color) {
 	img.VLine(rect.Max.X, rect.Min.Y, rect.Max.Y, col)
 }
 
+// FillRect fills the part of rect inside the image with col.
+func (img *Tensor) FillRect(rect image.Rectangle, col color.Color) {
+	rect = rect.Intersect(img.Rect)
+	if rect.Empty() {
+		return
+	}
+	c := color.RGBAModel.Convert(col).(color.RGBA)
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		row := img.Pix[img.PixOffset(rect.Min.X, y):img.PixOffset(rect.Max.X, y)]
+		for i := 0; i < len(row); i += 3 {
+			row[i], row[i+1], row[i+2] = c.R, c.G, c.B
+		}
+	}
+}
+
+// DrawLine draws a straight line between p0 and p1 using Bresenham's algorithm
+func (img *Tensor) DrawLine(p0, p1 image.Point, col color.Color) {
+	dx := abs(p1.X - p0.X)
+	dy := -abs(p1.Y - p0.Y)
+	sx, sy := 1, 1
+	if p0.X > p1.X {
+		sx = -1
+	}
+	if p0.Y > p1.Y {
+		sy = -1
+	}
+	e := dx + dy
+	x, y := p0.X, p0.Y
+	for {
+		img.Set(x, y, col)
+		if x == p1.X && y == p1.Y {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y += sy
+		}
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
 // Drop drops (frees) the tensor
 func (ts *Tensor) Drop() error {
 	atFree(ts.ctensor)
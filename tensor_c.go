@@ -1,3 +1,5 @@
+//go:build cgo && !nolibtorch
+
 package goyolov5
 
 //#include "stdlib.h"
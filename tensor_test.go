@@ -0,0 +1,86 @@
+package goyolov5
+
+import (
+	"image"
+	"image/png"
+	"os"
+	"testing"
+)
+
+func TestLetterboxingResize(t *testing.T) {
+	f, err := os.Open("tests/inputs/640x480.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	input, _, err := image.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tensor := NewTensorFromImage(input)
+
+	f2, err := os.Create("tests/outputs/640x480_load.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f2.Close()
+	if err = png.Encode(f2, tensor); err != nil {
+		t.Fatal(err)
+	}
+
+	lb, extraX, extraY, err := tensor.ToSquareShape()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if extraX != 0 {
+		t.Fatalf("expected 0, got %d", extraX)
+	}
+	if extraY != 160 {
+		t.Fatalf("expected 160, got %d", extraX)
+	}
+
+	f3, err := os.Create("tests/outputs/640x480_lb.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f3.Close()
+	if err = png.Encode(f3, lb); err != nil {
+		t.Fatal(err)
+	}
+
+	rs, _, err := lb.Resize(400)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f4, err := os.Create("tests/outputs/640x480_rs.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f4.Close()
+	if err = png.Encode(f4, rs); err != nil {
+		t.Fatal(err)
+	}
+
+}
+
+func BenchmarkToSquare(b *testing.B) {
+
+	f, err := os.Open("tests/inputs/640x480.png")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer f.Close()
+	input, _, err := image.Decode(f)
+	if err != nil {
+		b.Fatal(err)
+	}
+	tensor := NewTensorFromImage(input)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		tensor.ToSquareShape()
+	}
+}
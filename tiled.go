@@ -0,0 +1,224 @@
+package goyolov5
+
+import (
+	"fmt"
+	"image"
+	"sort"
+)
+
+// MergeMode selects how InferTiled combines boxes of the same class found in
+// overlapping tiles.
+type MergeMode int
+
+const (
+	// MergeNMS keeps the most confident box of each overlapping group.
+	MergeNMS MergeMode = iota
+	// MergeWBF fuses each overlapping group into one box, averaging the
+	// coordinates weighted by confidence.
+	MergeWBF
+)
+
+// TileOptions configures InferTiled.
+type TileOptions struct {
+	// Overlap is the fraction of the model size adjacent tiles share, in [0, 1).
+	Overlap float64
+	// BatchSize is the maximum number of tiles per forward pass. Values above 1
+	// need a backend and model that accept batched input.
+	BatchSize int
+	// FullImage adds a pass over the whole, letterboxed image so objects larger
+	// than a tile are still found.
+	FullImage bool
+	// Merge selects how duplicates across tile seams are combined.
+	Merge MergeMode
+}
+
+// InferTiled runs the model over overlapping, model-sized tiles of the input
+// rather than shrinking the whole image, so small objects in large images
+// survive. Boxes are shifted back into input coordinates and duplicates across
+// tile seams merged using nmsThreshold.
+func (yolov5 *YoloV5) InferTiled(inputTensorRaw *Tensor, confidenceThreshold float32, nmsThreshold float64, opts TileOptions) ([]Prediction, error) {
+	if opts.Overlap < 0 || opts.Overlap >= 1 {
+		return nil, fmt.Errorf("tile overlap must be in [0, 1), got %f", opts.Overlap)
+	}
+
+	batchSize := opts.BatchSize
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	bboxes := make([][]Bbox, yolov5.nClasses)
+
+	tiles := tileRects(inputTensorRaw.Rect, yolov5.size, opts.Overlap)
+	for start := 0; start < len(tiles); start += batchSize {
+		end := start + batchSize
+		if end > len(tiles) {
+			end = len(tiles)
+		}
+
+		inputs := make([]*Tensor, end-start)
+		for i, r := range tiles[start:end] {
+			inputs[i] = inputTensorRaw.tile(r, yolov5.size)
+		}
+
+		rawOutput, shape, err := forwardBatch(yolov5.backend, inputs)
+		if err != nil {
+			return nil, err
+		}
+
+		batchBboxes, err := yolov5.postProcess(rawOutput, shape, confidenceThreshold)
+		if err != nil {
+			return nil, err
+		}
+		if len(batchBboxes) != len(inputs) {
+			return nil, fmt.Errorf("expected %d outputs, got %d", len(inputs), len(batchBboxes))
+		}
+
+		for i, tileBboxes := range batchBboxes {
+			tileBboxes, err := yolov5.postNMS(tileBboxes, nmsThreshold)
+			if err != nil {
+				return nil, err
+			}
+			r := tiles[start+i]
+			for c, bboxesForClass := range tileBboxes {
+				for _, b := range bboxesForClass {
+					bboxes[c] = append(bboxes[c], b.transform(1.0, float64(r.Min.X), float64(r.Min.Y)))
+				}
+			}
+		}
+	}
+
+	if opts.FullImage {
+		inputTensor, _, _, scaleRatio, err := yolov5.preProcess(inputTensorRaw)
+		if err != nil {
+			return nil, err
+		}
+
+		rawOutput, shape, err := yolov5.backend.Forward(inputTensor)
+		if err != nil {
+			return nil, err
+		}
+
+		batchBboxes, err := yolov5.postProcess(rawOutput, shape, confidenceThreshold)
+		if err != nil {
+			return nil, err
+		}
+
+		for c, bboxesForClass := range batchBboxes[0] {
+			for _, b := range bboxesForClass {
+				bboxes[c] = append(bboxes[c], b.transform(scaleRatio, float64(inputTensorRaw.Rect.Min.X), float64(inputTensorRaw.Rect.Min.Y)))
+			}
+		}
+	}
+
+	var merged [][]Bbox
+	var err error
+	switch opts.Merge {
+	case MergeNMS:
+		merged, err = yolov5.postNMS(bboxes, nmsThreshold)
+	case MergeWBF:
+		merged = fuseBoxes(bboxes, nmsThreshold)
+	default:
+		err = fmt.Errorf("unknown merge mode %d", opts.Merge)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var predictions []Prediction
+	for _, c := range merged {
+		for _, b := range c {
+			predictions = append(predictions, b.toPrediction(1.0, image.Point{}))
+		}
+	}
+
+	return predictions, nil
+}
+
+// tileRects covers bounds with size x size tiles overlapping by the given
+// fraction. The last row and column are aligned to the far edge.
+func tileRects(bounds image.Rectangle, size int, overlap float64) []image.Rectangle {
+	step := int(float64(size) * (1 - overlap))
+	if step < 1 {
+		step = 1
+	}
+
+	var rects []image.Rectangle
+	for _, y := range tileStarts(bounds.Min.Y, bounds.Max.Y, size, step) {
+		for _, x := range tileStarts(bounds.Min.X, bounds.Max.X, size, step) {
+			rects = append(rects, image.Rect(x, y, x+size, y+size).Intersect(bounds))
+		}
+	}
+	return rects
+}
+
+func tileStarts(min, max, size, step int) []int {
+	if max-min <= size {
+		return []int{min}
+	}
+
+	var starts []int
+	for s := min; ; s += step {
+		if s+size >= max {
+			return append(starts, max-size)
+		}
+		starts = append(starts, s)
+	}
+}
+
+// tile copies r into a new size x size tensor, zero padding the remainder.
+func (p *Tensor) tile(r image.Rectangle, size int) *Tensor {
+	dst := NewTensor(image.Rect(0, 0, size, size))
+	r = r.Intersect(p.Rect)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		i := p.PixOffset(r.Min.X, y)
+		copy(dst.Pix[(y-r.Min.Y)*dst.Stride:], p.Pix[i:i+3*r.Dx()])
+	}
+	return dst
+}
+
+// fuseBoxes clusters boxes of each class whose IoU with a cluster's fused box
+// exceeds iouThreshold, and replaces every cluster with a single box whose
+// coordinates are the confidence-weighted mean of its members. The fused box
+// keeps the highest confidence of the cluster.
+func fuseBoxes(bboxes [][]Bbox, iouThreshold float64) [][]Bbox {
+	fused := make([][]Bbox, len(bboxes))
+	for c, bboxesForClass := range bboxes {
+		sort.Sort(sort.Reverse(ByConfBbox(bboxesForClass)))
+
+		var clusters [][]Bbox
+		for _, b := range bboxesForClass {
+			matched := false
+			for i := range clusters {
+				if Iou(fused[c][i], b) > iouThreshold {
+					clusters[i] = append(clusters[i], b)
+					fused[c][i] = weightedBox(clusters[i])
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				clusters = append(clusters, []Bbox{b})
+				fused[c] = append(fused[c], b)
+			}
+		}
+	}
+	return fused
+}
+
+// weightedBox averages the coordinates of a cluster sorted by descending
+// confidence, keeping everything else from its most confident member.
+func weightedBox(cluster []Bbox) Bbox {
+	res := cluster[0]
+	var xmin, ymin, xmax, ymax, total float64
+	for _, b := range cluster {
+		xmin += b.xmin * b.confidence
+		ymin += b.ymin * b.confidence
+		xmax += b.xmax * b.confidence
+		ymax += b.ymax * b.confidence
+		total += b.confidence
+	}
+	if total > 0 {
+		res.xmin, res.ymin, res.xmax, res.ymax = xmin/total, ymin/total, xmax/total, ymax/total
+	}
+	return res
+}
@@ -0,0 +1,119 @@
+package goyolov5
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"sync"
+	"testing"
+)
+
+// blobBackend reports the bounding box of all non-black pixels in its input as
+// a single class 0 detection.
+type blobBackend struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (b *blobBackend) Forward(input *Tensor) ([]float32, []int, error) {
+	b.mu.Lock()
+	b.calls++
+	b.mu.Unlock()
+
+	predSize := ClassesOffset + 1
+	out := make([]float32, predSize)
+	r := image.Rectangle{}
+	for y := input.Rect.Min.Y; y < input.Rect.Max.Y; y++ {
+		for x := input.Rect.Min.X; x < input.Rect.Max.X; x++ {
+			if input.RGBAAt(x, y) != (color.RGBA{0, 0, 0, 255}) {
+				r = r.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	if !r.Empty() {
+		out[0] = float32(r.Min.X+r.Max.X) / 2.0
+		out[1] = float32(r.Min.Y+r.Max.Y) / 2.0
+		out[2] = float32(r.Dx())
+		out[3] = float32(r.Dy())
+		out[4] = 0.9
+		out[5] = 0.9
+	}
+	return out, []int{1, 1, predSize}, nil
+}
+
+func TestTileRects(t *testing.T) {
+	rects := tileRects(image.Rect(0, 0, 1000, 600), 320, 0.25)
+	if len(rects) != 12 {
+		t.Fatalf("expected 12 tiles, got %d", len(rects))
+	}
+	if rects[3] != image.Rect(680, 0, 1000, 320) {
+		t.Fatalf("expected the last column aligned to the edge, got %v", rects[3])
+	}
+	if rects[11] != image.Rect(680, 280, 1000, 600) {
+		t.Fatalf("expected the last tile in the corner, got %v", rects[11])
+	}
+
+	rects = tileRects(image.Rect(0, 0, 200, 100), 320, 0.25)
+	if len(rects) != 1 || rects[0] != image.Rect(0, 0, 200, 100) {
+		t.Fatalf("expected a single tile for a small image, got %v", rects)
+	}
+}
+
+func TestInferTiled(t *testing.T) {
+	backend := &blobBackend{}
+	yolov5 := NewYoloV5WithBackend(backend, 320, 1, 0)
+
+	tensor := NewTensor(image.Rect(0, 0, 1000, 600))
+	draw.Draw(tensor, tensor.Rect, image.NewUniform(color.Black), image.Point{}, draw.Src)
+	draw.Draw(tensor, image.Rect(600, 100, 640, 140), image.NewUniform(color.White), image.Point{}, draw.Src)
+
+	predictions, err := yolov5.InferTiled(tensor, 0.5, 0.4, TileOptions{Overlap: 0.25})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if backend.calls != 12 {
+		t.Fatalf("expected 12 forward calls, got %d", backend.calls)
+	}
+	if len(predictions) != 1 {
+		t.Fatalf("expected 1 prediction, got %d", len(predictions))
+	}
+	if predictions[0].Rect != image.Rect(600, 100, 640, 140) {
+		t.Fatalf("expected the box in image coordinates, got %v", predictions[0].Rect)
+	}
+}
+
+func TestInferTiledBatched(t *testing.T) {
+	backend := NewFakeBackend(NClasses, 0, 10,
+		FakeDetection{Rect: image.Rect(10, 10, 50, 50), Confidence: 0.9, ClassIndex: COCO_PERSON, ClassConfidence: 0.9},
+	)
+	yolov5 := NewYoloV5WithBackend(backend, 320, NClasses, 0)
+
+	predictions, err := yolov5.InferTiled(NewTensor(image.Rect(0, 0, 1000, 600)), 0.5, 0.4, TileOptions{Overlap: 0.25, BatchSize: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backend.Inputs()) != 12 {
+		t.Fatalf("expected 12 tiles, got %d", len(backend.Inputs()))
+	}
+	// One box per tile; none of them overlap after shifting back.
+	if len(predictions) != 12 {
+		t.Fatalf("expected 12 predictions, got %d", len(predictions))
+	}
+}
+
+func TestFuseBoxes(t *testing.T) {
+	bboxes := [][]Bbox{{
+		{xmin: 0, ymin: 0, xmax: 10, ymax: 10, confidence: 0.75},
+		{xmin: 2, ymin: 2, xmax: 12, ymax: 12, confidence: 0.25},
+		{xmin: 50, ymin: 50, xmax: 60, ymax: 60, confidence: 0.5},
+	}}
+
+	fused := fuseBoxes(bboxes, 0.4)
+	if len(fused[0]) != 2 {
+		t.Fatalf("expected 2 boxes, got %d", len(fused[0]))
+	}
+	b := fused[0][0]
+	if b.xmin != 0.5 || b.ymax != 10.5 || b.confidence != 0.75 {
+		t.Fatalf("unexpected fused box %+v", b)
+	}
+}
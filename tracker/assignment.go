@@ -0,0 +1,117 @@
+package tracker
+
+import "math"
+
+// assign solves the linear assignment problem for cost, a rows x cols matrix,
+// leaving a row or column unmatched whenever that is cheaper than any match
+// costing more than costLimit. It returns the matched (row, col) pairs.
+func assign(cost [][]float64, costLimit float64) [][2]int {
+	rows := len(cost)
+	if rows == 0 {
+		return nil
+	}
+	cols := len(cost[0])
+	if cols == 0 {
+		return nil
+	}
+
+	// Extend to a square matrix in which every row and column may instead be
+	// paired with a dummy at half the cost limit, as lapjv's cost_limit does.
+	const infeasible = 1e9
+	n := rows + cols
+	extended := make([][]float64, n)
+	for i := range extended {
+		extended[i] = make([]float64, n)
+		for j := range extended[i] {
+			switch {
+			case i < rows && j < cols:
+				if cost[i][j] > costLimit {
+					extended[i][j] = infeasible
+				} else {
+					extended[i][j] = cost[i][j]
+				}
+			case i < rows:
+				extended[i][j] = infeasible
+				if j-cols == i {
+					extended[i][j] = costLimit / 2
+				}
+			case j < cols:
+				extended[i][j] = infeasible
+				if i-rows == j {
+					extended[i][j] = costLimit / 2
+				}
+			}
+		}
+	}
+
+	var matches [][2]int
+	for i, j := range hungarian(extended) {
+		if i < rows && j < cols && cost[i][j] <= costLimit {
+			matches = append(matches, [2]int{i, j})
+		}
+	}
+	return matches
+}
+
+// hungarian returns, for each row of the square cost matrix, the column
+// assigned to it in a minimum cost perfect matching.
+func hungarian(cost [][]float64) []int {
+	n := len(cost)
+	u := make([]float64, n+1)
+	v := make([]float64, n+1)
+	p := make([]int, n+1) // p[j] is the row matched to column j, 1-based
+	way := make([]int, n+1)
+
+	for i := 1; i <= n; i++ {
+		p[0] = i
+		j0 := 0
+		minv := make([]float64, n+1)
+		used := make([]bool, n+1)
+		for j := range minv {
+			minv[j] = math.Inf(1)
+		}
+		for {
+			used[j0] = true
+			i0 := p[j0]
+			delta := math.Inf(1)
+			j1 := 0
+			for j := 1; j <= n; j++ {
+				if used[j] {
+					continue
+				}
+				cur := cost[i0-1][j-1] - u[i0] - v[j]
+				if cur < minv[j] {
+					minv[j] = cur
+					way[j] = j0
+				}
+				if minv[j] < delta {
+					delta = minv[j]
+					j1 = j
+				}
+			}
+			for j := 0; j <= n; j++ {
+				if used[j] {
+					u[p[j]] += delta
+					v[j] -= delta
+				} else {
+					minv[j] -= delta
+				}
+			}
+			j0 = j1
+			if p[j0] == 0 {
+				break
+			}
+		}
+		for j0 != 0 {
+			j1 := way[j0]
+			p[j0] = p[j1]
+			j0 = j1
+		}
+	}
+
+	res := make([]int, n)
+	for j := 1; j <= n; j++ {
+		res[p[j]-1] = j - 1
+	}
+	return res
+}
@@ -0,0 +1,180 @@
+package tracker
+
+// kalmanFilter is a constant-velocity Kalman filter over the box state
+// (cx, cy, aspect ratio, height) and its velocities, as used by SORT and
+// ByteTrack.
+type kalmanFilter struct {
+	mean [8]float64
+	cov  [8][8]float64
+}
+
+const (
+	stdWeightPosition = 1.0 / 20
+	stdWeightVelocity = 1.0 / 160
+)
+
+func newKalmanFilter(measurement [4]float64) *kalmanFilter {
+	kf := &kalmanFilter{}
+	copy(kf.mean[:4], measurement[:])
+
+	h := measurement[3]
+	std := [8]float64{
+		2 * stdWeightPosition * h,
+		2 * stdWeightPosition * h,
+		1e-2,
+		2 * stdWeightPosition * h,
+		10 * stdWeightVelocity * h,
+		10 * stdWeightVelocity * h,
+		1e-5,
+		10 * stdWeightVelocity * h,
+	}
+	for i, s := range std {
+		kf.cov[i][i] = s * s
+	}
+	return kf
+}
+
+// predict advances the state by one frame.
+func (kf *kalmanFilter) predict() {
+	h := kf.mean[3]
+	std := [8]float64{
+		stdWeightPosition * h,
+		stdWeightPosition * h,
+		1e-2,
+		stdWeightPosition * h,
+		stdWeightVelocity * h,
+		stdWeightVelocity * h,
+		1e-5,
+		stdWeightVelocity * h,
+	}
+
+	// x = Fx, where F adds each velocity to its position
+	for i := 0; i < 4; i++ {
+		kf.mean[i] += kf.mean[i+4]
+	}
+
+	// P = FPF' + Q
+	var fp [8][8]float64
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
+			fp[i][j] = kf.cov[i][j]
+			if i < 4 {
+				fp[i][j] += kf.cov[i+4][j]
+			}
+		}
+	}
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
+			kf.cov[i][j] = fp[i][j]
+			if j < 4 {
+				kf.cov[i][j] += fp[i][j+4]
+			}
+		}
+		kf.cov[i][i] += std[i] * std[i]
+	}
+}
+
+// update corrects the state with a measured box.
+func (kf *kalmanFilter) update(measurement [4]float64) {
+	h := kf.mean[3]
+	std := [4]float64{
+		stdWeightPosition * h,
+		stdWeightPosition * h,
+		1e-1,
+		stdWeightPosition * h,
+	}
+
+	// S = HPH' + R, where H selects the positions
+	var s [4][4]float64
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			s[i][j] = kf.cov[i][j]
+		}
+		s[i][i] += std[i] * std[i]
+	}
+	sInv, ok := invert4(s)
+	if !ok {
+		return
+	}
+
+	// K = PH'S^-1
+	var k [8][4]float64
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 4; j++ {
+			for l := 0; l < 4; l++ {
+				k[i][j] += kf.cov[i][l] * sInv[l][j]
+			}
+		}
+	}
+
+	var innovation [4]float64
+	for i := 0; i < 4; i++ {
+		innovation[i] = measurement[i] - kf.mean[i]
+	}
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 4; j++ {
+			kf.mean[i] += k[i][j] * innovation[j]
+		}
+	}
+
+	// P = P - KHP
+	var khp [8][8]float64
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
+			for l := 0; l < 4; l++ {
+				khp[i][j] += k[i][l] * kf.cov[l][j]
+			}
+		}
+	}
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
+			kf.cov[i][j] -= khp[i][j]
+		}
+	}
+}
+
+// invert4 inverts a 4x4 matrix with Gauss-Jordan elimination.
+func invert4(m [4][4]float64) ([4][4]float64, bool) {
+	var inv [4][4]float64
+	for i := range inv {
+		inv[i][i] = 1
+	}
+
+	for col := 0; col < 4; col++ {
+		pivot := col
+		for row := col + 1; row < 4; row++ {
+			if abs(m[row][col]) > abs(m[pivot][col]) {
+				pivot = row
+			}
+		}
+		if m[pivot][col] == 0 {
+			return inv, false
+		}
+		m[col], m[pivot] = m[pivot], m[col]
+		inv[col], inv[pivot] = inv[pivot], inv[col]
+
+		p := m[col][col]
+		for j := 0; j < 4; j++ {
+			m[col][j] /= p
+			inv[col][j] /= p
+		}
+		for row := 0; row < 4; row++ {
+			if row == col {
+				continue
+			}
+			f := m[row][col]
+			for j := 0; j < 4; j++ {
+				m[row][j] -= f * m[col][j]
+				inv[row][j] -= f * inv[col][j]
+			}
+		}
+	}
+	return inv, true
+}
+
+func abs(x float64) float64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,338 @@
+// Package tracker assigns stable identities to goyolov5 detections across
+// frames. It follows ByteTrack: Kalman-filtered tracks are matched to high
+// confidence detections first and then to low confidence ones, so briefly
+// occluded or blurred objects keep their IDs.
+package tracker
+
+import (
+	"image"
+	"math"
+	"sort"
+
+	"github.com/danhilltech/goyolov5"
+)
+
+// TrackState is the lifecycle state of a Track.
+type TrackState int
+
+const (
+	// New tracks have been seen in a single frame and are not yet confirmed.
+	New TrackState = iota
+	// Tracked tracks were matched in the latest frame.
+	Tracked
+	// Lost tracks were not matched in the latest frame but may still recover.
+	Lost
+	// Removed tracks are gone for good and are reported only once.
+	Removed
+)
+
+func (s TrackState) String() string {
+	switch s {
+	case New:
+		return "new"
+	case Tracked:
+		return "tracked"
+	case Lost:
+		return "lost"
+	case Removed:
+		return "removed"
+	}
+	return "unknown"
+}
+
+// Track is a snapshot of one tracked object.
+type Track struct {
+	ID         int
+	State      TrackState
+	ClassIndex uint
+	// Rect is the Kalman-filtered box in image coordinates.
+	Rect image.Rectangle
+	// Prediction is the detection the track was last matched to.
+	Prediction goyolov5.Prediction
+	// Score is the detection score, confidence times class confidence, of
+	// Prediction.
+	Score float64
+	// VX and VY are the velocity of the box centre in pixels per frame.
+	VX, VY float64
+	// StartFrame and Frame are the frames the track was first and last matched
+	// in, counting from 1.
+	StartFrame int
+	Frame      int
+	// Age is the number of frames since the track started.
+	Age int
+	// TimeSinceUpdate is the number of frames since the track was last matched.
+	TimeSinceUpdate int
+}
+
+// Config holds the tracker thresholds. Scores are detection confidence times
+// class confidence.
+type Config struct {
+	// HighThreshold splits detections into the first, high confidence and the
+	// second, low confidence association stage.
+	HighThreshold float64
+	// LowThreshold drops detections below it entirely.
+	LowThreshold float64
+	// NewTrackThreshold is the minimum score of an unmatched detection to start
+	// a new track.
+	NewTrackThreshold float64
+	// MatchThreshold is the maximum IoU distance, 1 - IoU, of a first stage
+	// match.
+	MatchThreshold float64
+	// MaxLost is the number of frames a lost track is kept before it is removed.
+	MaxLost int
+}
+
+// DefaultConfig matches ByteTrack's defaults at 30 frames per second.
+var DefaultConfig = Config{
+	HighThreshold:     0.5,
+	LowThreshold:      0.1,
+	NewTrackThreshold: 0.6,
+	MatchThreshold:    0.8,
+	MaxLost:           30,
+}
+
+const (
+	secondMatchThreshold      = 0.5
+	unconfirmedMatchThreshold = 0.7
+)
+
+// Tracker maintains tracks across calls to Update. It is not safe for
+// concurrent use.
+type Tracker struct {
+	config Config
+	frame  int
+	nextID int
+	tracks []*track
+}
+
+type track struct {
+	Track
+	kf *kalmanFilter
+}
+
+type detection struct {
+	prediction goyolov5.Prediction
+	score      float64
+}
+
+func NewTracker(config Config) *Tracker {
+	return &Tracker{config: config, nextID: 1}
+}
+
+// Update consumes the detections of the next frame and returns every track
+// that is new, tracked or lost, plus those removed in this frame, ordered by
+// ID.
+func (t *Tracker) Update(predictions []goyolov5.Prediction) []Track {
+	t.frame++
+
+	var high, low []detection
+	for _, p := range predictions {
+		d := detection{prediction: p, score: p.Confidence * p.ClassConfidence}
+		if d.score >= t.config.HighThreshold {
+			high = append(high, d)
+		} else if d.score >= t.config.LowThreshold {
+			low = append(low, d)
+		}
+	}
+
+	var unconfirmed, pool []*track
+	for _, tr := range t.tracks {
+		if tr.State == New {
+			unconfirmed = append(unconfirmed, tr)
+		} else {
+			pool = append(pool, tr)
+		}
+	}
+	for _, tr := range pool {
+		if tr.State != Tracked {
+			tr.kf.mean[7] = 0
+		}
+		tr.kf.predict()
+	}
+
+	// 1. Tracked and lost tracks against high confidence detections
+	matches, unmatchedTracks, unmatchedHigh := associate(pool, high, t.config.MatchThreshold)
+	for _, m := range matches {
+		pool[m[0]].update(high[m[1]], t.frame)
+	}
+
+	// 2. Remaining tracked tracks against low confidence detections
+	var remaining []*track
+	for _, i := range unmatchedTracks {
+		if pool[i].State == Tracked {
+			remaining = append(remaining, pool[i])
+		}
+	}
+	matches, unmatchedTracks, _ = associate(remaining, low, secondMatchThreshold)
+	for _, m := range matches {
+		remaining[m[0]].update(low[m[1]], t.frame)
+	}
+	for _, i := range unmatchedTracks {
+		remaining[i].State = Lost
+	}
+
+	// 3. Unconfirmed tracks against the high confidence detections left over
+	var leftover []detection
+	for _, j := range unmatchedHigh {
+		leftover = append(leftover, high[j])
+	}
+	matches, unmatchedTracks, unmatchedLeftover := associate(unconfirmed, leftover, unconfirmedMatchThreshold)
+	for _, m := range matches {
+		unconfirmed[m[0]].update(leftover[m[1]], t.frame)
+	}
+	for _, i := range unmatchedTracks {
+		unconfirmed[i].State = Removed
+	}
+
+	// 4. Start new tracks
+	for _, j := range unmatchedLeftover {
+		d := leftover[j]
+		if d.score < t.config.NewTrackThreshold {
+			continue
+		}
+		t.tracks = append(t.tracks, t.newTrack(d))
+	}
+
+	// 5. Expire lost tracks
+	for _, tr := range t.tracks {
+		if tr.State == Lost && t.frame-tr.Frame > t.config.MaxLost {
+			tr.State = Removed
+		}
+	}
+
+	var out []Track
+	alive := t.tracks[:0]
+	for _, tr := range t.tracks {
+		out = append(out, tr.snapshot(t.frame))
+		if tr.State != Removed {
+			alive = append(alive, tr)
+		}
+	}
+	for i := len(alive); i < len(t.tracks); i++ {
+		t.tracks[i] = nil
+	}
+	t.tracks = alive
+
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+func (t *Tracker) newTrack(d detection) *track {
+	tr := &track{
+		Track: Track{
+			ID:         t.nextID,
+			State:      New,
+			ClassIndex: d.prediction.ClassIndex,
+			Prediction: d.prediction,
+			Score:      d.score,
+			StartFrame: t.frame,
+			Frame:      t.frame,
+		},
+		kf: newKalmanFilter(toMeasurement(d.prediction.Rect)),
+	}
+	// Nothing can confirm tracks in the first frame, so trust it outright.
+	if t.frame == 1 {
+		tr.State = Tracked
+	}
+	t.nextID++
+	return tr
+}
+
+func (tr *track) update(d detection, frame int) {
+	tr.kf.update(toMeasurement(d.prediction.Rect))
+	tr.Prediction = d.prediction
+	tr.Score = d.score
+	tr.Frame = frame
+	tr.State = Tracked
+}
+
+func (tr *track) snapshot(frame int) Track {
+	s := tr.Track
+	s.Rect = tr.rect()
+	s.VX = tr.kf.mean[4]
+	s.VY = tr.kf.mean[5]
+	s.Age = frame - tr.StartFrame
+	s.TimeSinceUpdate = frame - tr.Frame
+	return s
+}
+
+// rect converts the Kalman state back into a box.
+func (tr *track) rect() image.Rectangle {
+	cx, cy, a, h := tr.kf.mean[0], tr.kf.mean[1], tr.kf.mean[2], tr.kf.mean[3]
+	w := a * h
+	return image.Rect(
+		int(math.Round(cx-w/2)),
+		int(math.Round(cy-h/2)),
+		int(math.Round(cx+w/2)),
+		int(math.Round(cy+h/2)),
+	)
+}
+
+// toMeasurement converts a box into the filter's (cx, cy, aspect ratio,
+// height) measurement.
+func toMeasurement(r image.Rectangle) [4]float64 {
+	w, h := float64(r.Dx()), float64(r.Dy())
+	if h <= 0 {
+		h = 1
+	}
+	return [4]float64{
+		float64(r.Min.X) + w/2,
+		float64(r.Min.Y) + h/2,
+		w / h,
+		h,
+	}
+}
+
+// associate matches tracks to detections of the same class by IoU distance. It
+// returns the matched (track, detection) index pairs and the unmatched track
+// and detection indices.
+func associate(tracks []*track, detections []detection, threshold float64) ([][2]int, []int, []int) {
+	cost := make([][]float64, len(tracks))
+	for i, tr := range tracks {
+		cost[i] = make([]float64, len(detections))
+		r := tr.rect()
+		for j, d := range detections {
+			if d.prediction.ClassIndex != tr.ClassIndex {
+				cost[i][j] = math.Inf(1)
+				continue
+			}
+			cost[i][j] = 1 - IoU(r, d.prediction.Rect)
+		}
+	}
+
+	matches := assign(cost, threshold)
+
+	matchedTracks := make([]bool, len(tracks))
+	matchedDetections := make([]bool, len(detections))
+	for _, m := range matches {
+		matchedTracks[m[0]] = true
+		matchedDetections[m[1]] = true
+	}
+
+	var unmatchedTracks, unmatchedDetections []int
+	for i, matched := range matchedTracks {
+		if !matched {
+			unmatchedTracks = append(unmatchedTracks, i)
+		}
+	}
+	for j, matched := range matchedDetections {
+		if !matched {
+			unmatchedDetections = append(unmatchedDetections, j)
+		}
+	}
+	return matches, unmatchedTracks, unmatchedDetections
+}
+
+// IoU is the intersection over union of two rectangles.
+func IoU(a, b image.Rectangle) float64 {
+	inter := a.Intersect(b)
+	if inter.Empty() {
+		return 0
+	}
+	i := float64(inter.Dx() * inter.Dy())
+	u := float64(a.Dx()*a.Dy()+b.Dx()*b.Dy()) - i
+	if u <= 0 {
+		return 0
+	}
+	return i / u
+}
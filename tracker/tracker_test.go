@@ -0,0 +1,129 @@
+package tracker
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"github.com/danhilltech/goyolov5"
+)
+
+func person(x, y int, confidence float64) goyolov5.Prediction {
+	return goyolov5.Prediction{
+		Rect:            image.Rect(x, y, x+40, y+100),
+		Confidence:      confidence,
+		ClassIndex:      goyolov5.COCO_PERSON,
+		ClassConfidence: 1.0,
+	}
+}
+
+func TestAssign(t *testing.T) {
+	cost := [][]float64{
+		{0.9, 0.1, 0.5},
+		{0.2, 0.3, 0.9},
+	}
+	matches := assign(cost, 0.8)
+	if len(matches) != 2 || matches[0] != [2]int{0, 1} || matches[1] != [2]int{1, 0} {
+		t.Fatalf("unexpected matches %v", matches)
+	}
+
+	// Every pairing is above the limit
+	matches = assign([][]float64{{0.95}}, 0.8)
+	if len(matches) != 0 {
+		t.Fatalf("expected no matches, got %v", matches)
+	}
+}
+
+func TestTrackerStableID(t *testing.T) {
+	tr := NewTracker(DefaultConfig)
+
+	var tracks []Track
+	for frame := 0; frame < 20; frame++ {
+		tracks = tr.Update([]goyolov5.Prediction{
+			person(100+5*frame, 50, 0.9),
+			person(400, 300-3*frame, 0.9),
+		})
+	}
+
+	if len(tracks) != 2 {
+		t.Fatalf("expected 2 tracks, got %d", len(tracks))
+	}
+	if tracks[0].ID != 1 || tracks[1].ID != 2 {
+		t.Fatalf("expected IDs 1 and 2, got %d and %d", tracks[0].ID, tracks[1].ID)
+	}
+	if tracks[0].State != Tracked || tracks[0].Age != 19 {
+		t.Fatalf("unexpected track %+v", tracks[0])
+	}
+	if math.Abs(tracks[0].VX-5) > 0.5 || math.Abs(tracks[0].VY) > 0.5 {
+		t.Fatalf("expected velocity (5, 0), got (%f, %f)", tracks[0].VX, tracks[0].VY)
+	}
+	if math.Abs(tracks[1].VY+3) > 0.5 {
+		t.Fatalf("expected vertical velocity -3, got %f", tracks[1].VY)
+	}
+}
+
+func TestTrackerLowConfidenceRecovery(t *testing.T) {
+	tr := NewTracker(DefaultConfig)
+
+	for frame := 0; frame < 5; frame++ {
+		tr.Update([]goyolov5.Prediction{person(100+5*frame, 50, 0.9)})
+	}
+	// Motion blur drops the score into the second association stage
+	tracks := tr.Update([]goyolov5.Prediction{person(125, 50, 0.3)})
+	if len(tracks) != 1 || tracks[0].ID != 1 || tracks[0].State != Tracked {
+		t.Fatalf("expected track 1 kept by the low confidence detection, got %+v", tracks)
+	}
+}
+
+func TestTrackerLostAndRemoved(t *testing.T) {
+	config := DefaultConfig
+	config.MaxLost = 3
+	tr := NewTracker(config)
+
+	for frame := 0; frame < 5; frame++ {
+		tr.Update([]goyolov5.Prediction{person(100, 50, 0.9)})
+	}
+
+	tracks := tr.Update(nil)
+	if len(tracks) != 1 || tracks[0].State != Lost {
+		t.Fatalf("expected a lost track, got %+v", tracks)
+	}
+
+	// Reappears within MaxLost and keeps its ID
+	tracks = tr.Update([]goyolov5.Prediction{person(100, 50, 0.9)})
+	if len(tracks) != 1 || tracks[0].ID != 1 || tracks[0].State != Tracked {
+		t.Fatalf("expected track 1 to recover, got %+v", tracks)
+	}
+
+	for i := 0; i < 4; i++ {
+		tracks = tr.Update(nil)
+	}
+	if len(tracks) != 1 || tracks[0].State != Removed {
+		t.Fatalf("expected the track to be removed, got %+v", tracks)
+	}
+	if tracks = tr.Update(nil); len(tracks) != 0 {
+		t.Fatalf("expected removed tracks to be reported once, got %+v", tracks)
+	}
+}
+
+func TestTrackerUnconfirmed(t *testing.T) {
+	tr := NewTracker(DefaultConfig)
+	tr.Update(nil)
+
+	tracks := tr.Update([]goyolov5.Prediction{person(100, 50, 0.9)})
+	if len(tracks) != 1 || tracks[0].State != New {
+		t.Fatalf("expected a new track, got %+v", tracks)
+	}
+
+	tracks = tr.Update([]goyolov5.Prediction{person(102, 50, 0.9)})
+	if len(tracks) != 1 || tracks[0].State != Tracked {
+		t.Fatalf("expected the track to be confirmed, got %+v", tracks)
+	}
+
+	// A one-frame false positive is dropped straight away
+	tr.Update([]goyolov5.Prediction{person(104, 50, 0.9), person(500, 500, 0.9)})
+	tracks = tr.Update([]goyolov5.Prediction{person(106, 50, 0.9)})
+	if len(tracks) != 2 || tracks[1].State != Removed {
+		t.Fatalf("expected the unconfirmed track to be removed, got %+v", tracks)
+	}
+}
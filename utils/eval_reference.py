@@ -0,0 +1,33 @@
+#!/usr/bin/env python3
+"""Print pycocotools' numbers for eval/testdata, which eval_test.go expects.
+
+    pip install pycocotools
+    python3 utils/eval_reference.py
+"""
+import os
+
+import numpy as np
+from pycocotools.coco import COCO
+from pycocotools.cocoeval import COCOeval
+
+testdata = os.path.join(os.path.dirname(__file__), "..", "eval", "testdata")
+
+gt = COCO(os.path.join(testdata, "instances.json"))
+dt = gt.loadRes(os.path.join(testdata, "detections.json"))
+
+E = COCOeval(gt, dt, "bbox")
+E.evaluate()
+E.accumulate()
+E.summarize()
+
+print("mAP50-95 %.17g" % E.stats[0])
+print("mAP50    %.17g" % E.stats[1])
+
+# precision is [iou, recall, category, area, max detections]
+precision = E.eval["precision"][:, :, :, 0, -1]
+for k, cat_id in enumerate(E.params.catIds):
+    name = gt.loadCats(cat_id)[0]["name"]
+    p = precision[:, :, k]
+    ap50 = np.mean(p[0][p[0] > -1]) if (p[0] > -1).any() else -1
+    ap = np.mean(p[p > -1]) if (p > -1).any() else -1
+    print("%-10s %.17g %.17g" % (name, ap50, ap))
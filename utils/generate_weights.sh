@@ -22,6 +22,8 @@ python3 export.py --weights yolov5n.pt --include torchscript --device 0 &&
         mv /opt/yolov5/yolov5n.torchscript.pt /var/app/weights/yolov5n/yolov5n.torchscript.gpu.640.pt
 python3 export.py --weights yolov5n.pt --include torchscript --device 0 --half &&
         mv /opt/yolov5/yolov5n.torchscript.pt /var/app/weights/yolov5n/yolov5n.torchscript.gpu.half.640.pt
+python3 export.py --weights yolov5n.pt --include onnx &&
+        mv /opt/yolov5/yolov5n.onnx /var/app/weights/yolov5n/yolov5n.cpu.640.onnx
 
 # yolov5s
 python3 export.py --weights yolov5s.pt --include torchscript &&
@@ -30,6 +32,8 @@ python3 export.py --weights yolov5s.pt --include torchscript --device 0 &&
         mv /opt/yolov5/yolov5s.torchscript.pt /var/app/weights/yolov5s/yolov5s.torchscript.gpu.640.pt
 python3 export.py --weights yolov5s.pt --include torchscript --device 0 --half &&
         mv /opt/yolov5/yolov5s.torchscript.pt /var/app/weights/yolov5s/yolov5s.torchscript.gpu.half.640.pt
+python3 export.py --weights yolov5s.pt --include onnx &&
+        mv /opt/yolov5/yolov5s.onnx /var/app/weights/yolov5s/yolov5s.cpu.640.onnx
 
 # yolov5n6
 python3 export.py --weights yolov5n6.pt --include torchscript --imgsz 1280 &&